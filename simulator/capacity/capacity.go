@@ -0,0 +1,121 @@
+package capacity
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+)
+
+// DefaultMaxReplicas is the upper limit of replicas simulated in one estimation when it isn't specified.
+const DefaultMaxReplicas = 1000
+
+// DryRunService represents a service to simulate the placement of Pods without creating them.
+type DryRunService interface {
+	ScheduleReplicas(ctx context.Context, template *v1.Pod, maxReplicas int) ([]dryrun.Result, error)
+}
+
+// Service estimates how many replicas of a Pod can be placed on the cluster.
+type Service struct {
+	dryRunService DryRunService
+}
+
+// Estimation is the result of a capacity estimation.
+type Estimation struct {
+	// Replicas is the number of replicas which can be placed.
+	Replicas int `json:"replicas"`
+	// Nodes has the number of replicas placed on each node.
+	Nodes map[string]int `json:"nodes"`
+	// LimitReached is true when the estimation stopped at the max replicas, that is, more replicas may fit.
+	LimitReached bool `json:"limitReached"`
+	// Message describes why the next replica doesn't fit.
+	Message string `json:"message,omitempty"`
+	// LimitingFactors are the plugins and reasons which reject the next replica, sorted by the number of nodes in descending order.
+	LimitingFactors []LimitingFactor `json:"limitingFactors,omitempty"`
+}
+
+// LimitingFactor is a pair of a plugin and a reason which rejects the next replica.
+type LimitingFactor struct {
+	Plugin string `json:"plugin"`
+	Reason string `json:"reason"`
+	// Resource is the resource name when the reason is insufficient resources (e.g., "cpu").
+	Resource string `json:"resource,omitempty"`
+	// Nodes is the number of nodes rejected by the plugin with the reason.
+	// It's 0 when the replica is rejected in PreFilter, which doesn't evaluate each node.
+	Nodes int `json:"nodes"`
+}
+
+// NewService initializes Service.
+func NewService(dryRunService DryRunService) *Service {
+	return &Service{dryRunService: dryRunService}
+}
+
+// Estimate places replicas of template one by one with the current scheduler configuration until nothing fits.
+// maxReplicas limits the number of simulated replicas, and DefaultMaxReplicas is used when it's not positive.
+func (s *Service) Estimate(ctx context.Context, template *v1.Pod, maxReplicas int) (*Estimation, error) {
+	if maxReplicas <= 0 {
+		maxReplicas = DefaultMaxReplicas
+	}
+
+	results, err := s.dryRunService.ScheduleReplicas(ctx, template, maxReplicas)
+	if err != nil {
+		return nil, xerrors.Errorf("simulate placement of replicas: %w", err)
+	}
+
+	est := &Estimation{Nodes: map[string]int{}}
+	for _, r := range results {
+		if r.NodeName == "" {
+			est.Message = r.Message
+			est.LimitingFactors = limitingFactors(r.Rejections)
+			break
+		}
+		est.Replicas++
+		est.Nodes[r.NodeName]++
+	}
+	est.LimitReached = est.Replicas == maxReplicas
+
+	return est, nil
+}
+
+// insufficientResourcePrefix is the prefix of the reason returned from NodeResourcesFit when a resource is insufficient.
+const insufficientResourcePrefix = "Insufficient "
+
+func limitingFactors(rejections []dryrun.Rejection) []LimitingFactor {
+	type factorKey struct{ plugin, reason string }
+	counts := map[factorKey]int{}
+	for _, r := range rejections {
+		for _, reason := range r.Reasons {
+			k := factorKey{plugin: r.Plugin, reason: reason}
+			if _, ok := counts[k]; !ok {
+				counts[k] = 0
+			}
+			// The rejection in PreFilter isn't counted as it's not for a specific node.
+			if r.NodeName != "" {
+				counts[k]++
+			}
+		}
+	}
+
+	ret := make([]LimitingFactor, 0, len(counts))
+	for k, n := range counts {
+		f := LimitingFactor{Plugin: k.plugin, Reason: k.reason, Nodes: n}
+		if strings.HasPrefix(k.reason, insufficientResourcePrefix) {
+			f.Resource = strings.TrimPrefix(k.reason, insufficientResourcePrefix)
+		}
+		ret = append(ret, f)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Nodes != ret[j].Nodes {
+			return ret[i].Nodes > ret[j].Nodes
+		}
+		if ret[i].Plugin != ret[j].Plugin {
+			return ret[i].Plugin < ret[j].Plugin
+		}
+		return ret[i].Reason < ret[j].Reason
+	})
+	return ret
+}
@@ -0,0 +1,91 @@
+package capacity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+)
+
+type fakeDryRunService struct {
+	results []dryrun.Result
+}
+
+func (f *fakeDryRunService) ScheduleReplicas(_ context.Context, _ *v1.Pod, maxReplicas int) ([]dryrun.Result, error) {
+	if len(f.results) > maxReplicas {
+		return f.results[:maxReplicas], nil
+	}
+	return f.results, nil
+}
+
+func TestService_Estimate(t *testing.T) {
+	t.Parallel()
+
+	unschedulable := dryrun.Result{
+		Message: "0/3 nodes are available: 1 node(s) had untolerated taint, 2 Insufficient cpu.",
+		Rejections: []dryrun.Rejection{
+			{NodeName: "node-1", Plugin: "NodeResourcesFit", Reasons: []string{"Insufficient cpu"}},
+			{NodeName: "node-2", Plugin: "NodeResourcesFit", Reasons: []string{"Insufficient cpu"}},
+			{NodeName: "node-3", Plugin: "TaintToleration", Reasons: []string{"node(s) had untolerated taint"}},
+		},
+	}
+	placed := []dryrun.Result{{NodeName: "node-1"}, {NodeName: "node-2"}, {NodeName: "node-1"}}
+
+	tests := []struct {
+		name        string
+		results     []dryrun.Result
+		maxReplicas int
+		want        *Estimation
+	}{
+		{
+			name:    "replicas are placed until nothing fits",
+			results: append(append([]dryrun.Result{}, placed...), unschedulable),
+			want: &Estimation{
+				Replicas: 3,
+				Nodes:    map[string]int{"node-1": 2, "node-2": 1},
+				Message:  unschedulable.Message,
+				LimitingFactors: []LimitingFactor{
+					{Plugin: "NodeResourcesFit", Reason: "Insufficient cpu", Resource: "cpu", Nodes: 2},
+					{Plugin: "TaintToleration", Reason: "node(s) had untolerated taint", Nodes: 1},
+				},
+			},
+		},
+		{
+			name:        "estimation stops at maxReplicas",
+			results:     append(append([]dryrun.Result{}, placed...), unschedulable),
+			maxReplicas: 2,
+			want: &Estimation{
+				Replicas:     2,
+				Nodes:        map[string]int{"node-1": 1, "node-2": 1},
+				LimitReached: true,
+			},
+		},
+		{
+			name: "no replica fits because of PreFilter",
+			results: []dryrun.Result{{
+				Message:    "node(s) didn't match pod topology spread constraints (missing required label)",
+				Rejections: []dryrun.Rejection{{Plugin: "PodTopologySpread", Reasons: []string{"node(s) didn't match pod topology spread constraints (missing required label)"}}},
+			}},
+			want: &Estimation{
+				Nodes:   map[string]int{},
+				Message: "node(s) didn't match pod topology spread constraints (missing required label)",
+				LimitingFactors: []LimitingFactor{
+					{Plugin: "PodTopologySpread", Reason: "node(s) didn't match pod topology spread constraints (missing required label)"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewService(&fakeDryRunService{results: tt.results})
+			got, err := s.Estimate(context.Background(), &v1.Pod{}, tt.maxReplicas)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
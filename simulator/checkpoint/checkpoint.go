@@ -0,0 +1,191 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"golang.org/x/xerrors"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/reset"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/util"
+)
+
+var (
+	// ErrCheckpointNotFound is returned when the requested checkpoint doesn't exist.
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+	// ErrCheckpointAlreadyExists is returned when a checkpoint with the same name is already saved.
+	ErrCheckpointAlreadyExists = errors.New("checkpoint already exists")
+	// ErrEmptyCheckpointName is returned when the checkpoint name is empty.
+	ErrEmptyCheckpointName = errors.New("checkpoint name is empty")
+)
+
+// SchedulerService is the part of scheduler.Service used to save and restore the scheduler configurations.
+type SchedulerService interface {
+	ListSchedulers() []scheduler.Scheduler
+	GetNamedSchedulerConfig(name string) (*configv1.KubeSchedulerConfiguration, error)
+	RestartNamedScheduler(name string, cfg *configv1.KubeSchedulerConfiguration) error
+}
+
+// Service saves the simulator state as named checkpoints and restores it from them.
+// Checkpoints are kept in memory, and they are lost when the simulator is restarted.
+type Service struct {
+	mu          sync.RWMutex
+	checkpoints map[string]*checkpoint
+	// seq is incremented every time a checkpoint is saved, and it's used to keep the order of checkpoints.
+	seq int
+
+	etcdClient   clientv3.KV
+	schedService SchedulerService
+}
+
+// Checkpoint describes a saved checkpoint.
+type Checkpoint struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	// KeyCount is the number of etcd keys saved in the checkpoint.
+	KeyCount int `json:"keyCount"`
+	// HasSchedulerConfig indicates whether the checkpoint has the scheduler configuration.
+	// It's false when the simulator doesn't manage the scheduler (e.g., an external scheduler is used).
+	HasSchedulerConfig bool `json:"hasSchedulerConfig"`
+	// Schedulers are the names of the schedulers whose configurations are saved in the checkpoint.
+	Schedulers []string `json:"schedulers,omitempty"`
+}
+
+// checkpoint has the whole data of a saved checkpoint.
+type checkpoint struct {
+	Checkpoint
+	seq int
+	// data has all etcd key/values under reset.EtcdPrefix.
+	data map[string]string
+	// schedulerCfgs are keyed by the names of the schedulers. Their order is kept in Checkpoint.Schedulers.
+	schedulerCfgs map[string]*configv1.KubeSchedulerConfiguration
+}
+
+// NewCheckpointService initializes Service.
+func NewCheckpointService(etcdClient clientv3.KV, schedService SchedulerService) *Service {
+	return &Service{
+		checkpoints:  map[string]*checkpoint{},
+		etcdClient:   etcdClient,
+		schedService: schedService,
+	}
+}
+
+// Save saves the current etcd key space and the configurations of all schedulers as the checkpoint named name.
+func (s *Service) Save(ctx context.Context, name string) (*Checkpoint, error) {
+	if name == "" {
+		return nil, ErrEmptyCheckpointName
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.checkpoints[name]; ok {
+		return nil, xerrors.Errorf("save checkpoint %s: %w", name, ErrCheckpointAlreadyExists)
+	}
+
+	result, err := s.etcdClient.Get(ctx, reset.EtcdPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, xerrors.Errorf("get all data in etcd: %w", err)
+	}
+	data := make(map[string]string, len(result.Kvs))
+	for _, v := range result.Kvs {
+		data[string(v.Key)] = string(v.Value)
+	}
+
+	var schedulers []string
+	cfgs := map[string]*configv1.KubeSchedulerConfiguration{}
+	for _, sched := range s.schedService.ListSchedulers() {
+		cfg, err := s.schedService.GetNamedSchedulerConfig(sched.Name)
+		if err != nil && !errors.Is(err, scheduler.ErrServiceDisabled) {
+			return nil, xerrors.Errorf("get scheduler config of %s: %w", sched.Name, err)
+		}
+		if cfg == nil {
+			continue
+		}
+		schedulers = append(schedulers, sched.Name)
+		cfgs[sched.Name] = cfg.DeepCopy()
+	}
+
+	s.seq++
+	c := &checkpoint{
+		seq: s.seq,
+		Checkpoint: Checkpoint{
+			Name:               name,
+			CreatedAt:          time.Now(),
+			KeyCount:           len(data),
+			HasSchedulerConfig: len(schedulers) != 0,
+			Schedulers:         schedulers,
+		},
+		data:          data,
+		schedulerCfgs: cfgs,
+	}
+	s.checkpoints[name] = c
+
+	ret := c.Checkpoint
+	return &ret, nil
+}
+
+// List returns all saved checkpoints in the order of their creation.
+func (s *Service) List() []Checkpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cs := make([]*checkpoint, 0, len(s.checkpoints))
+	for _, c := range s.checkpoints {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].seq < cs[j].seq
+	})
+
+	ret := make([]Checkpoint, 0, len(cs))
+	for _, c := range cs {
+		ret = append(ret, c.Checkpoint)
+	}
+	return ret
+}
+
+// Restore rolls back all resources and the configurations of the schedulers to the checkpoint named name.
+func (s *Service) Restore(ctx context.Context, name string) error {
+	s.mu.RLock()
+	c, ok := s.checkpoints[name]
+	s.mu.RUnlock()
+	if !ok {
+		return xerrors.Errorf("restore checkpoint %s: %w", name, ErrCheckpointNotFound)
+	}
+
+	if _, err := s.etcdClient.Delete(ctx, reset.EtcdPrefix, clientv3.WithPrefix()); err != nil {
+		return xerrors.Errorf("delete all data in etcd: %w", err)
+	}
+
+	eg := util.NewErrGroupWithSemaphore(ctx)
+	for k, v := range c.data {
+		k := k
+		v := v
+		err := eg.Go(func() error {
+			if _, err := s.etcdClient.Put(ctx, k, v); err != nil {
+				return xerrors.Errorf("put checkpoint data in etcd: key: %s, error: %w", k, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	for _, name := range c.Schedulers {
+		if err := s.schedService.RestartNamedScheduler(name, c.schedulerCfgs[name].DeepCopy()); err != nil && !errors.Is(err, scheduler.ErrServiceDisabled) {
+			return xerrors.Errorf("restart scheduler %s with the checkpoint's config: %w", name, err)
+		}
+	}
+	return nil
+}
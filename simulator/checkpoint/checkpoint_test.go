@@ -0,0 +1,153 @@
+package checkpoint
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+)
+
+// fakeKV is an in-memory clientv3.KV which only supports prefix Get/Delete and Put.
+type fakeKV struct {
+	clientv3.KV
+
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (f *fakeKV) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &clientv3.GetResponse{}
+	for k, v := range f.data {
+		if strings.HasPrefix(k, key) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeKV) Put(_ context.Context, key, val string, _ ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key] = val
+	return &clientv3.PutResponse{}, nil
+}
+
+func (f *fakeKV) Delete(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k := range f.data {
+		if strings.HasPrefix(k, key) {
+			delete(f.data, k)
+		}
+	}
+	return &clientv3.DeleteResponse{}, nil
+}
+
+type fakeSchedulerService struct {
+	names []string
+	// cfgs are keyed by the scheduler names.
+	cfgs        map[string]*configv1.KubeSchedulerConfiguration
+	err         error
+	restartedBy map[string]*configv1.KubeSchedulerConfiguration
+}
+
+func (f *fakeSchedulerService) ListSchedulers() []scheduler.Scheduler {
+	ret := make([]scheduler.Scheduler, 0, len(f.names))
+	for _, n := range f.names {
+		ret = append(ret, scheduler.Scheduler{Name: n})
+	}
+	return ret
+}
+
+func (f *fakeSchedulerService) GetNamedSchedulerConfig(name string) (*configv1.KubeSchedulerConfiguration, error) {
+	return f.cfgs[name], f.err
+}
+
+func (f *fakeSchedulerService) RestartNamedScheduler(name string, cfg *configv1.KubeSchedulerConfiguration) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.restartedBy == nil {
+		f.restartedBy = map[string]*configv1.KubeSchedulerConfiguration{}
+	}
+	f.restartedBy[name] = cfg
+	f.cfgs[name] = cfg
+	return nil
+}
+
+func TestService_SaveAndRestore(t *testing.T) {
+	t.Parallel()
+	kv := &fakeKV{data: map[string]string{
+		"/kube-scheduler-simulator/pods/default/pod1": "pod1",
+		"/kube-scheduler-simulator/nodes/node1":       "node1",
+		"/other/key":                                  "other",
+	}}
+	cfg := &configv1.KubeSchedulerConfiguration{Parallelism: ptr.To[int32](3)}
+	batchCfg := &configv1.KubeSchedulerConfiguration{Parallelism: ptr.To[int32](4)}
+	sched := &fakeSchedulerService{
+		names: []string{"default", "batch"},
+		cfgs:  map[string]*configv1.KubeSchedulerConfiguration{"default": cfg, "batch": batchCfg},
+	}
+	s := NewCheckpointService(kv, sched)
+
+	c, err := s.Save(context.Background(), "before")
+	assert.NoError(t, err)
+	assert.Equal(t, "before", c.Name)
+	assert.Equal(t, 2, c.KeyCount)
+	assert.True(t, c.HasSchedulerConfig)
+	assert.Equal(t, []string{"default", "batch"}, c.Schedulers)
+
+	_, err = s.Save(context.Background(), "before")
+	assert.ErrorIs(t, err, ErrCheckpointAlreadyExists)
+	_, err = s.Save(context.Background(), "")
+	assert.ErrorIs(t, err, ErrEmptyCheckpointName)
+
+	// mutate the state after saving the checkpoint.
+	kv.data["/kube-scheduler-simulator/pods/default/pod2"] = "pod2"
+	delete(kv.data, "/kube-scheduler-simulator/nodes/node1")
+	sched.cfgs["default"] = &configv1.KubeSchedulerConfiguration{Parallelism: ptr.To[int32](10)}
+	sched.cfgs["batch"] = &configv1.KubeSchedulerConfiguration{Parallelism: ptr.To[int32](20)}
+
+	_, err = s.Save(context.Background(), "after")
+	assert.NoError(t, err)
+	got := s.List()
+	assert.Len(t, got, 2)
+	assert.Equal(t, "before", got[0].Name)
+	assert.Equal(t, "after", got[1].Name)
+
+	assert.NoError(t, s.Restore(context.Background(), "before"))
+	assert.Equal(t, map[string]string{
+		"/kube-scheduler-simulator/pods/default/pod1": "pod1",
+		"/kube-scheduler-simulator/nodes/node1":       "node1",
+		"/other/key":                                  "other",
+	}, kv.data)
+	// All schedulers are restarted with the configurations in the checkpoint.
+	assert.Equal(t, map[string]*configv1.KubeSchedulerConfiguration{"default": cfg, "batch": batchCfg}, sched.restartedBy)
+
+	assert.ErrorIs(t, s.Restore(context.Background(), "unknown"), ErrCheckpointNotFound)
+}
+
+func TestService_SaveWithoutSchedulerService(t *testing.T) {
+	t.Parallel()
+	kv := &fakeKV{data: map[string]string{"/kube-scheduler-simulator/nodes/node1": "node1"}}
+	sched := &fakeSchedulerService{names: []string{"default"}, err: scheduler.ErrServiceDisabled}
+	s := NewCheckpointService(kv, sched)
+
+	c, err := s.Save(context.Background(), "cp")
+	assert.NoError(t, err)
+	assert.False(t, c.HasSchedulerConfig)
+	assert.Empty(t, c.Schedulers)
+
+	assert.NoError(t, s.Restore(context.Background(), "cp"))
+	assert.Nil(t, sched.restartedBy)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/capacity"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/handler"
+)
+
+var (
+	serverURL   string
+	podFile     string
+	maxReplicas int
+	output      string
+)
+
+func main() {
+	if err := estimate(); err != nil {
+		klog.Fatalf("failed to estimate capacity: %+v", err)
+	}
+}
+
+func estimate() error {
+	if err := parseOptions(); err != nil {
+		return err
+	}
+
+	pod, err := loadPod(podFile)
+	if err != nil {
+		return xerrors.Errorf("load pod template: %w", err)
+	}
+
+	body, err := json.Marshal(handler.CapacityRequest{Pod: pod, MaxReplicas: maxReplicas})
+	if err != nil {
+		return xerrors.Errorf("encode request: %w", err)
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Post(strings.TrimSuffix(serverURL, "/")+"/api/v1/capacity", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("call capacity API: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("capacity API returned %s: %s", resp.Status, string(respBody))
+	}
+
+	if output == "json" {
+		_, err := os.Stdout.Write(append(respBody, '\n'))
+		return err
+	}
+
+	est := &capacity.Estimation{}
+	if err := json.Unmarshal(respBody, est); err != nil {
+		return xerrors.Errorf("decode response: %w", err)
+	}
+	return printEstimation(os.Stdout, est)
+}
+
+func loadPod(path string) (*v1.Pod, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	pod := &v1.Pod{}
+	if err := utilyaml.NewYAMLOrJSONDecoder(f, 4096).Decode(pod); err != nil {
+		return nil, xerrors.Errorf("decode %s: %w", path, err)
+	}
+	return pod, nil
+}
+
+func printEstimation(out io.Writer, est *capacity.Estimation) error {
+	replicas := fmt.Sprint(est.Replicas)
+	if est.LimitReached {
+		replicas += " (reached the max replicas, more replicas may fit)"
+	}
+	fmt.Fprintf(out, "Replicas: %s\n\n", replicas)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tREPLICAS")
+	nodes := make([]string, 0, len(est.Nodes))
+	for n := range est.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	for _, n := range nodes {
+		fmt.Fprintf(w, "%s\t%d\n", n, est.Nodes[n])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if est.Message == "" {
+		return nil
+	}
+	fmt.Fprintf(out, "\nThe next replica doesn't fit: %s\n\n", est.Message)
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PLUGIN\tREASON\tNODES")
+	for _, f := range est.LimitingFactors {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", f.Plugin, f.Reason, f.Nodes)
+	}
+	return w.Flush()
+}
+
+func parseOptions() error {
+	flag.StringVar(&serverURL, "server", "http://localhost:1212", "URL of the simulator server")
+	flag.StringVar(&podFile, "pod", "", "path to the YAML or JSON file of the pod template")
+	flag.IntVar(&maxReplicas, "max-replicas", capacity.DefaultMaxReplicas, "upper limit of replicas to simulate")
+	flag.StringVar(&output, "o", "table", "output format (table or json)")
+	flag.Parse()
+
+	if podFile == "" {
+		return xerrors.New("pod flag is required")
+	}
+	if output != "table" && output != "json" {
+		return xerrors.New("o flag must be table or json")
+	}
+
+	return nil
+}
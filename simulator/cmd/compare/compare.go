@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/xerrors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/comparison"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/recorder"
+)
+
+var (
+	serverURL     string
+	configAFile   string
+	configBFile   string
+	snapshotFile  string
+	recordingFile string
+	output        string
+)
+
+func main() {
+	if err := compare(); err != nil {
+		klog.Fatalf("failed to compare scheduler configurations: %+v", err)
+	}
+}
+
+func compare() error {
+	if err := parseOptions(); err != nil {
+		return err
+	}
+
+	in, err := loadInput()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return xerrors.Errorf("encode request: %w", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Minute}
+	resp, err := client.Post(strings.TrimSuffix(serverURL, "/")+"/api/v1/comparison", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("call comparison API: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("comparison API returned %s: %s", resp.Status, string(respBody))
+	}
+
+	if output == "json" {
+		_, err := os.Stdout.Write(append(respBody, '\n'))
+		return err
+	}
+
+	report := &comparison.Report{}
+	if err := json.Unmarshal(respBody, report); err != nil {
+		return xerrors.Errorf("decode response: %w", err)
+	}
+	return printReport(os.Stdout, report)
+}
+
+func loadInput() (*comparison.Input, error) {
+	in := &comparison.Input{}
+	var err error
+	if configAFile != "" {
+		in.ConfigA = &configv1.KubeSchedulerConfiguration{}
+		if err := decodeFile(configAFile, in.ConfigA); err != nil {
+			return nil, xerrors.Errorf("load config A: %w", err)
+		}
+	}
+	if configBFile != "" {
+		in.ConfigB = &configv1.KubeSchedulerConfiguration{}
+		if err := decodeFile(configBFile, in.ConfigB); err != nil {
+			return nil, xerrors.Errorf("load config B: %w", err)
+		}
+	}
+	if snapshotFile != "" {
+		in.Snapshot = &comparison.Snapshot{}
+		if err := decodeFile(snapshotFile, in.Snapshot); err != nil {
+			return nil, xerrors.Errorf("load snapshot: %w", err)
+		}
+	}
+	if recordingFile != "" {
+		in.Recording, err = loadRecording(recordingFile)
+		if err != nil {
+			return nil, xerrors.Errorf("load recording: %w", err)
+		}
+	}
+	return in, nil
+}
+
+// decodeFile decodes the YAML or JSON file into obj.
+func decodeFile(path string, obj interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return xerrors.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := utilyaml.NewYAMLOrJSONDecoder(f, 4096).Decode(obj); err != nil {
+		return xerrors.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadRecording reads the file written by the recorder, which has one record per line.
+func loadRecording(path string) ([]recorder.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	records := []recorder.Record{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		r := recorder.Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, xerrors.Errorf("unmarshal record: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+func printReport(out io.Writer, r *comparison.Report) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tA\tB")
+	fmt.Fprintf(w, "Scheduled\t%d\t%d\n", r.A.Scheduled, r.B.Scheduled)
+	fmt.Fprintf(w, "Unschedulable\t%d\t%d\n", r.A.Unschedulable, r.B.Unschedulable)
+	fmt.Fprintf(w, "Would preempt\t%d\t%d\n", r.A.WouldPreempt, r.B.WouldPreempt)
+	fmt.Fprintf(w, "CPU utilization (mean/stddev)\t%.3f/%.3f\t%.3f/%.3f\n",
+		r.A.CPUUtilization.Mean, r.A.CPUUtilization.StdDev, r.B.CPUUtilization.Mean, r.B.CPUUtilization.StdDev)
+	fmt.Fprintf(w, "Memory utilization (mean/stddev)\t%.3f/%.3f\t%.3f/%.3f\n",
+		r.A.MemoryUtilization.Mean, r.A.MemoryUtilization.StdDev, r.B.MemoryUtilization.Mean, r.B.MemoryUtilization.StdDev)
+	fmt.Fprintf(w, "CPU fragmentation\t%.3f\t%.3f\n", r.A.CPUFragmentation, r.B.CPUFragmentation)
+	fmt.Fprintf(w, "Memory fragmentation\t%.3f\t%.3f\n", r.A.MemoryFragmentation, r.B.MemoryFragmentation)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nPlacements: %d same, %d different\n", r.SamePlacements, len(r.PlacementDifferences))
+	if len(r.PlacementDifferences) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tNODE (A)\tNODE (B)")
+	for _, d := range r.PlacementDifferences {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\n", d.Namespace, d.Name, nodeOrUnschedulable(d.NodeA), nodeOrUnschedulable(d.NodeB))
+	}
+	return w.Flush()
+}
+
+func nodeOrUnschedulable(node string) string {
+	if node == "" {
+		return "<unschedulable>"
+	}
+	return node
+}
+
+func parseOptions() error {
+	flag.StringVar(&serverURL, "server", "http://localhost:1212", "URL of the simulator server")
+	flag.StringVar(&configAFile, "config-a", "", "path to the scheduler configuration A (the current one is used if omitted)")
+	flag.StringVar(&configBFile, "config-b", "", "path to the scheduler configuration B (the current one is used if omitted)")
+	flag.StringVar(&snapshotFile, "snapshot", "", "path to the exported resources to use as the workload")
+	flag.StringVar(&recordingFile, "recording", "", "path to the file recorded by sched-recorder to use as the workload")
+	flag.StringVar(&output, "o", "table", "output format (table or json)")
+	flag.Parse()
+
+	if snapshotFile != "" && recordingFile != "" {
+		return xerrors.New("only one of snapshot and recording flags can be specified")
+	}
+	if output != "table" && output != "json" {
+		return xerrors.New("o flag must be table or json")
+	}
+
+	return nil
+}
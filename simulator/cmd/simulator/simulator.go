@@ -22,6 +22,9 @@ import (
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/config"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/replayer"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/resourceapplier"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/debugextender"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
 )
@@ -91,8 +94,28 @@ func startSimulator() error {
 
 	replayerOptions := replayer.Options{RecordFile: cfg.RecordFilePath}
 	resourceApplierOptions := resourceapplier.Options{}
+	if err := extender.ValidateMockExtenders(cfg.MockExtenders); err != nil {
+		return xerrors.Errorf("validate mock extenders: %w", err)
+	}
+	if err := extender.ValidateExtenderFaults(cfg.ExtenderFaults); err != nil {
+		return xerrors.Errorf("validate extender faults: %w", err)
+	}
+	extenderOptions := []extender.Option{extender.WithMockExtenders(cfg.MockExtenders), extender.WithExtenderFaults(cfg.ExtenderFaults)}
+	pluginExtenders, err := debugextender.New(cfg.PluginExtenders)
+	if err != nil {
+		return xerrors.Errorf("create plugin extenders: %w", err)
+	}
 
-	dic, err := di.NewDIContainer(client, dynamicClient, restMapper, etcdclient, restCfg, cfg.InitialSchedulerCfg, cfg.ExternalImportEnabled, cfg.ResourceSyncEnabled, cfg.ReplayerEnabled, importClusterDynamicClient, cfg.Port, resourceApplierOptions, replayerOptions)
+	schedulerOptions := scheduler.Options{
+		AdditionalSchedulers: cfg.AdditionalSchedulers,
+		SchedulerRuntime:     cfg.SchedulerRuntime,
+		RecordCycleState:     cfg.RecordCycleState,
+		PluginExtenders:      pluginExtenders,
+		ExtenderOpts:         extenderOptions,
+		SimulatorPort:        cfg.Port,
+	}
+
+	dic, err := di.NewDIContainer(client, dynamicClient, restMapper, etcdclient, restCfg, cfg.InitialSchedulerCfg, cfg.ExternalImportEnabled, cfg.ResourceSyncEnabled, cfg.ReplayerEnabled, importClusterDynamicClient, schedulerOptions, resourceApplierOptions, replayerOptions)
 	if err != nil {
 		return xerrors.Errorf("create di container: %w", err)
 	}
@@ -117,6 +140,12 @@ func startSimulator() error {
 
 	dic.SchedulerService().SetSchedulerConfig(cfg.InitialSchedulerCfg)
 
+	// The schedulers running in containers are started outside the simulator, and this only starts in-process ones.
+	if err := dic.SchedulerService().StartScheduler(); err != nil {
+		return xerrors.Errorf("start scheduler: %w", err)
+	}
+	defer dic.SchedulerService().ShutdownScheduler()
+
 	if cfg.ResourceSyncEnabled {
 		// Start the resource syncer to sync resources from the target cluster.
 		if err = dic.ResourceSyncer().Run(ctx); err != nil {
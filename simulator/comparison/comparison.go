@@ -0,0 +1,268 @@
+package comparison
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/recorder"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	schedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+)
+
+// ErrMultipleWorkloads is returned when both of a snapshot and a recording are passed.
+var ErrMultipleWorkloads = errors.New("only one of snapshot and recording can be specified")
+
+// DryRunService represents a service to run scheduling cycles separated from the cluster.
+type DryRunService interface {
+	NewSimulation(ctx context.Context, cfg *configv1.KubeSchedulerConfiguration, nodes []*v1.Node, pods []*v1.Pod) (*dryrun.Simulation, error)
+	ClusterState(ctx context.Context) ([]*v1.Node, []*v1.Pod, error)
+}
+
+// SchedulerService is the part of scheduler.Service used to get the current scheduler configuration.
+type SchedulerService interface {
+	GetSchedulerConfig() (*configv1.KubeSchedulerConfiguration, error)
+}
+
+// Service runs the same workload with two scheduler configurations and compares the results.
+type Service struct {
+	dryRunService DryRunService
+	schedService  SchedulerService
+}
+
+// Input is the input of a comparison.
+type Input struct {
+	// ConfigA and ConfigB are the scheduler configurations to compare.
+	// The current scheduler configuration is used when it's nil.
+	ConfigA *configv1.KubeSchedulerConfiguration `json:"configA,omitempty"`
+	ConfigB *configv1.KubeSchedulerConfiguration `json:"configB,omitempty"`
+	// Snapshot is the workload given as nodes and Pods.
+	// All Pods are scheduled in the order of their creation.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	// Recording is the workload given as the records of the recorder.
+	// The events of nodes and Pods are applied in order, and each Pod is scheduled when it's created.
+	Recording []recorder.Record `json:"recording,omitempty"`
+}
+
+// Snapshot has the nodes and the Pods of a workload.
+// It's compatible with the response of the export API; the other resources in it are ignored.
+type Snapshot struct {
+	Pods  []v1.Pod  `json:"pods"`
+	Nodes []v1.Node `json:"nodes"`
+}
+
+// NewService initializes Service.
+func NewService(dryRunService DryRunService, schedService SchedulerService) *Service {
+	return &Service{dryRunService: dryRunService, schedService: schedService}
+}
+
+// Compare runs the workload in the input with the two scheduler configurations, and reports the differences.
+// When neither a snapshot nor a recording is given, the Pods in the current cluster are rescheduled on its nodes.
+// The cluster isn't changed in any case.
+func (s *Service) Compare(ctx context.Context, in *Input) (*Report, error) {
+	steps, err := s.workload(ctx, in)
+	if err != nil {
+		return nil, xerrors.Errorf("build workload: %w", err)
+	}
+
+	cfgA, err := s.configOrCurrent(in.ConfigA)
+	if err != nil {
+		return nil, err
+	}
+	cfgB, err := s.configOrCurrent(in.ConfigB)
+	if err != nil {
+		return nil, err
+	}
+
+	resultA, err := s.run(ctx, cfgA, steps)
+	if err != nil {
+		return nil, xerrors.Errorf("run workload with config A: %w", err)
+	}
+	resultB, err := s.run(ctx, cfgB, steps)
+	if err != nil {
+		return nil, xerrors.Errorf("run workload with config B: %w", err)
+	}
+
+	return newReport(resultA, resultB), nil
+}
+
+func (s *Service) configOrCurrent(cfg *configv1.KubeSchedulerConfiguration) (*configv1.KubeSchedulerConfiguration, error) {
+	if cfg != nil {
+		return cfg, nil
+	}
+	cfg, err := s.schedService.GetSchedulerConfig()
+	if err != nil && !errors.Is(err, scheduler.ErrServiceDisabled) {
+		return nil, xerrors.Errorf("get scheduler config: %w", err)
+	}
+	if cfg != nil {
+		return cfg, nil
+	}
+	cfg, err = schedconfig.DefaultSchedulerConfig()
+	if err != nil {
+		return nil, xerrors.Errorf("get default scheduler config: %w", err)
+	}
+	return cfg, nil
+}
+
+// step is a change in the workload. Only one of the fields is set.
+type step struct {
+	addNode    *v1.Node
+	deleteNode string
+	// schedulePod is the Pod to be scheduled.
+	schedulePod *v1.Pod
+	deletePod   *v1.Pod
+}
+
+func (s *Service) workload(ctx context.Context, in *Input) ([]step, error) {
+	switch {
+	case in.Snapshot != nil && in.Recording != nil:
+		return nil, ErrMultipleWorkloads
+	case in.Recording != nil:
+		return stepsFromRecording(in.Recording)
+	case in.Snapshot != nil:
+		nodes := make([]*v1.Node, 0, len(in.Snapshot.Nodes))
+		for i := range in.Snapshot.Nodes {
+			nodes = append(nodes, &in.Snapshot.Nodes[i])
+		}
+		pods := make([]*v1.Pod, 0, len(in.Snapshot.Pods))
+		for i := range in.Snapshot.Pods {
+			pods = append(pods, &in.Snapshot.Pods[i])
+		}
+		return stepsFromState(nodes, pods), nil
+	default:
+		nodes, pods, err := s.dryRunService.ClusterState(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("get cluster state: %w", err)
+		}
+		return stepsFromState(nodes, pods), nil
+	}
+}
+
+// stepsFromState adds all nodes, and then schedules the Pods in the order of their creation.
+// Pods which have already finished are ignored.
+func stepsFromState(nodes []*v1.Node, pods []*v1.Pod) []step {
+	steps := make([]step, 0, len(nodes)+len(pods))
+	for _, n := range nodes {
+		steps = append(steps, step{addNode: n})
+	}
+
+	sorted := make([]*v1.Pod, 0, len(pods))
+	for _, p := range pods {
+		if isFinished(p) {
+			continue
+		}
+		sorted = append(sorted, p)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Namespace+"/"+sorted[i].Name < sorted[j].Namespace+"/"+sorted[j].Name
+	})
+	for _, p := range sorted {
+		steps = append(steps, step{schedulePod: p})
+	}
+	return steps
+}
+
+// stepsFromRecording converts the events of nodes and Pods into steps.
+// A Pod is scheduled when it's seen first, and deleted when it's deleted or finished.
+//
+//nolint:cyclop
+func stepsFromRecording(records []recorder.Record) ([]step, error) {
+	steps := make([]step, 0, len(records))
+	seenPods := map[string]bool{}
+	for _, r := range records {
+		switch r.Resource.GetKind() {
+		case "Node":
+			node := &v1.Node{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Resource.Object, node); err != nil {
+				return nil, xerrors.Errorf("convert node %s: %w", r.Resource.GetName(), err)
+			}
+			if r.Event == recorder.Delete {
+				steps = append(steps, step{deleteNode: node.Name})
+				continue
+			}
+			steps = append(steps, step{addNode: node})
+		case "Pod":
+			pod := &v1.Pod{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Resource.Object, pod); err != nil {
+				return nil, xerrors.Errorf("convert pod %s: %w", r.Resource.GetName(), err)
+			}
+			k := pod.Namespace + "/" + pod.Name
+			if r.Event == recorder.Delete || isFinished(pod) {
+				if seenPods[k] {
+					steps = append(steps, step{deletePod: pod})
+					delete(seenPods, k)
+				}
+				continue
+			}
+			if seenPods[k] {
+				continue
+			}
+			seenPods[k] = true
+			steps = append(steps, step{schedulePod: pod})
+		}
+	}
+	return steps, nil
+}
+
+func isFinished(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// runResult is the result of running a workload with a scheduler configuration.
+type runResult struct {
+	// placements has the node selected for each Pod keyed by "namespace/name". It's empty for unschedulable Pods.
+	placements   map[string]string
+	wouldPreempt int
+	nodes        []*v1.Node
+	pods         []*v1.Pod
+}
+
+func (s *Service) run(ctx context.Context, cfg *configv1.KubeSchedulerConfiguration, steps []step) (*runResult, error) {
+	// The simulation starts from the empty state, and nodes and Pods are added by the steps.
+	sim, err := s.dryRunService.NewSimulation(ctx, cfg, []*v1.Node{}, []*v1.Pod{})
+	if err != nil {
+		return nil, xerrors.Errorf("create simulation: %w", err)
+	}
+	defer sim.Close()
+
+	ret := &runResult{placements: map[string]string{}}
+	for _, st := range steps {
+		switch {
+		case st.addNode != nil:
+			sim.AddNode(st.addNode)
+		case st.deleteNode != "":
+			sim.DeleteNode(st.deleteNode)
+		case st.deletePod != nil:
+			sim.DeletePod(st.deletePod.Namespace, st.deletePod.Name)
+		case st.schedulePod != nil:
+			r, err := sim.Schedule(ctx, st.schedulePod)
+			if err != nil {
+				return nil, xerrors.Errorf("schedule pod %s/%s: %w", st.schedulePod.Namespace, st.schedulePod.Name, err)
+			}
+			ret.placements[r.Namespace+"/"+r.Name] = r.NodeName
+			if r.NodeName != "" {
+				continue
+			}
+			preempt, err := sim.WouldPreempt(ctx, st.schedulePod)
+			if err != nil {
+				return nil, xerrors.Errorf("check preemption for pod %s/%s: %w", st.schedulePod.Namespace, st.schedulePod.Name, err)
+			}
+			if preempt {
+				ret.wouldPreempt++
+			}
+		}
+	}
+	ret.nodes = sim.Nodes()
+	ret.pods = sim.Pods()
+
+	return ret, nil
+}
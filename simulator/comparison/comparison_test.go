@@ -0,0 +1,159 @@
+package comparison
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/recorder"
+	schedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+)
+
+type fakeSchedulerService struct{}
+
+func (fakeSchedulerService) GetSchedulerConfig() (*configv1.KubeSchedulerConfiguration, error) {
+	return nil, nil
+}
+
+func node(name, cpu string) v1.Node {
+	rl := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+	return v1.Node{
+		TypeMeta:   metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Capacity: rl, Allocatable: rl},
+	}
+}
+
+func pod(name, cpu string, created int) v1.Pod {
+	return v1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Unix(int64(created), 0)),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:  "container",
+				Image: "image",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+	}
+}
+
+func record(t *testing.T, event recorder.Event, obj interface{}) recorder.Record {
+	t.Helper()
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	return recorder.Record{Event: event, Resource: unstructured.Unstructured{Object: u}}
+}
+
+func mostAllocatedConfig(t *testing.T) *configv1.KubeSchedulerConfiguration {
+	t.Helper()
+	cfg, err := schedconfig.DefaultSchedulerConfig()
+	require.NoError(t, err)
+	cfg.Profiles[0].PluginConfig = []configv1.PluginConfig{{
+		Name: "NodeResourcesFit",
+		Args: runtime.RawExtension{Object: &configv1.NodeResourcesFitArgs{
+			ScoringStrategy: &configv1.ScoringStrategy{
+				Type:      configv1.MostAllocated,
+				Resources: []configv1.ResourceSpec{{Name: "cpu", Weight: 1}, {Name: "memory", Weight: 1}},
+			},
+		}},
+	}}
+	return cfg
+}
+
+func TestService_Compare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     func(t *testing.T) *Input
+		wantDiffs []PlacementDifference
+		wantSame  int
+		wantA     Summary
+		wantB     Summary
+	}{
+		{
+			name: "snapshot: MostAllocated packs pods on one node",
+			input: func(t *testing.T) *Input {
+				t.Helper()
+				return &Input{
+					ConfigB: mostAllocatedConfig(t),
+					Snapshot: &Snapshot{
+						Nodes: []v1.Node{node("node-1", "4"), node("node-2", "4")},
+						Pods:  []v1.Pod{pod("pod-2", "1", 2), pod("pod-1", "1", 1), pod("pod-3", "8", 3)},
+					},
+				}
+			},
+			wantDiffs: []PlacementDifference{{Namespace: "default", Name: "pod-2", NodeA: "node-2", NodeB: "node-1"}},
+			wantSame:  2,
+			wantA:     Summary{Scheduled: 2, Unschedulable: 1, CPUFragmentation: 0.5},
+			wantB:     Summary{Scheduled: 2, Unschedulable: 1, CPUFragmentation: 1 - 4.0/6.0},
+		},
+		{
+			name: "recording: deleted pods free the capacity",
+			input: func(t *testing.T) *Input {
+				t.Helper()
+				n := node("node-1", "4")
+				p1, p2 := pod("pod-1", "3", 1), pod("pod-2", "3", 2)
+				return &Input{
+					Recording: []recorder.Record{
+						record(t, recorder.Add, &n),
+						record(t, recorder.Add, &p1),
+						record(t, recorder.Delete, &p1),
+						record(t, recorder.Add, &p2),
+					},
+				}
+			},
+			wantDiffs: []PlacementDifference{},
+			wantSame:  2,
+			wantA:     Summary{Scheduled: 2},
+			wantB:     Summary{Scheduled: 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dryRunService := dryrun.NewService(fake.NewSimpleClientset(), fakeSchedulerService{})
+			s := NewService(dryRunService, fakeSchedulerService{})
+
+			got, err := s.Compare(context.Background(), tt.input(t))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDiffs, got.PlacementDifferences)
+			assert.Equal(t, tt.wantSame, got.SamePlacements)
+			for _, c := range []struct{ got, want Summary }{{got.A, tt.wantA}, {got.B, tt.wantB}} {
+				assert.Equal(t, c.want.Scheduled, c.got.Scheduled)
+				assert.Equal(t, c.want.Unschedulable, c.got.Unschedulable)
+				assert.InDelta(t, c.want.CPUFragmentation, c.got.CPUFragmentation, 0.0001)
+			}
+		})
+	}
+}
+
+func TestService_CompareRejectsMultipleWorkloads(t *testing.T) {
+	t.Parallel()
+	s := NewService(dryrun.NewService(fake.NewSimpleClientset(), fakeSchedulerService{}), fakeSchedulerService{})
+	_, err := s.Compare(context.Background(), &Input{Snapshot: &Snapshot{}, Recording: []recorder.Record{}})
+	assert.ErrorIs(t, err, ErrMultipleWorkloads)
+}
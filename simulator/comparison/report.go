@@ -0,0 +1,164 @@
+package comparison
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Report is the result of a comparison.
+type Report struct {
+	A Summary `json:"a"`
+	B Summary `json:"b"`
+	// SamePlacements is the number of Pods placed on the same node (or unschedulable) with both configurations.
+	SamePlacements int `json:"samePlacements"`
+	// PlacementDifferences has the Pods placed differently, sorted by namespace and name.
+	PlacementDifferences []PlacementDifference `json:"placementDifferences"`
+}
+
+// Summary describes the result of the workload with one scheduler configuration.
+type Summary struct {
+	Scheduled     int `json:"scheduled"`
+	Unschedulable int `json:"unschedulable"`
+	// WouldPreempt is the number of unschedulable Pods which would fit if Pods with lower priority were evicted.
+	WouldPreempt int `json:"wouldPreempt"`
+	// CPUUtilization and MemoryUtilization are the distributions of the requested ratio of each node at the end of the workload.
+	CPUUtilization    Distribution `json:"cpuUtilization"`
+	MemoryUtilization Distribution `json:"memoryUtilization"`
+	// CPUFragmentation and MemoryFragmentation are in [0, 1], and calculated as 1 - (the largest free amount on a node / the total free amount).
+	// 0 means that all free resources are on one node, and the higher value means that the free resources are scattered across nodes.
+	CPUFragmentation    float64 `json:"cpuFragmentation"`
+	MemoryFragmentation float64 `json:"memoryFragmentation"`
+	// Nodes has the utilization of each node at the end of the workload, sorted by name.
+	Nodes []NodeUtilization `json:"nodes"`
+}
+
+// Distribution is the statistics of values.
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// NodeUtilization is the requested ratio of resources on a node.
+type NodeUtilization struct {
+	Name   string  `json:"name"`
+	Pods   int     `json:"pods"`
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+}
+
+// PlacementDifference is a Pod placed on different nodes with the two configurations.
+// The node name is empty when the Pod is unschedulable.
+type PlacementDifference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	NodeA     string `json:"nodeA"`
+	NodeB     string `json:"nodeB"`
+}
+
+func newReport(a, b *runResult) *Report {
+	r := &Report{
+		A:                    newSummary(a),
+		B:                    newSummary(b),
+		PlacementDifferences: []PlacementDifference{},
+	}
+
+	keys := make([]string, 0, len(a.placements))
+	for k := range a.placements {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		nodeA, nodeB := a.placements[k], b.placements[k]
+		if nodeA == nodeB {
+			r.SamePlacements++
+			continue
+		}
+		ns, name, _ := strings.Cut(k, "/")
+		r.PlacementDifferences = append(r.PlacementDifferences, PlacementDifference{Namespace: ns, Name: name, NodeA: nodeA, NodeB: nodeB})
+	}
+	return r
+}
+
+func newSummary(res *runResult) Summary {
+	s := Summary{WouldPreempt: res.wouldPreempt, Nodes: []NodeUtilization{}}
+	for _, node := range res.placements {
+		if node == "" {
+			s.Unschedulable++
+			continue
+		}
+		s.Scheduled++
+	}
+
+	podsOnNode := map[string][]*v1.Pod{}
+	for _, p := range res.pods {
+		podsOnNode[p.Spec.NodeName] = append(podsOnNode[p.Spec.NodeName], p)
+	}
+
+	cpus := make([]float64, 0, len(res.nodes))
+	mems := make([]float64, 0, len(res.nodes))
+	freeCPUs := make([]float64, 0, len(res.nodes))
+	freeMems := make([]float64, 0, len(res.nodes))
+	for _, n := range res.nodes {
+		ni := framework.NewNodeInfo(podsOnNode[n.Name]...)
+		ni.SetNode(n)
+
+		cpu := ratio(ni.Requested.MilliCPU, ni.Allocatable.MilliCPU)
+		mem := ratio(ni.Requested.Memory, ni.Allocatable.Memory)
+		cpus = append(cpus, cpu)
+		mems = append(mems, mem)
+		freeCPUs = append(freeCPUs, float64(max(ni.Allocatable.MilliCPU-ni.Requested.MilliCPU, 0)))
+		freeMems = append(freeMems, float64(max(ni.Allocatable.Memory-ni.Requested.Memory, 0)))
+		s.Nodes = append(s.Nodes, NodeUtilization{Name: n.Name, Pods: len(ni.Pods), CPU: cpu, Memory: mem})
+	}
+	s.CPUUtilization = distribution(cpus)
+	s.MemoryUtilization = distribution(mems)
+	s.CPUFragmentation = fragmentation(freeCPUs)
+	s.MemoryFragmentation = fragmentation(freeMems)
+
+	return s
+}
+
+func ratio(requested, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 0
+	}
+	return float64(requested) / float64(allocatable)
+}
+
+func distribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	d := Distribution{Min: values[0], Max: values[0]}
+	var sum float64
+	for _, v := range values {
+		sum += v
+		d.Min = math.Min(d.Min, v)
+		d.Max = math.Max(d.Max, v)
+	}
+	d.Mean = sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - d.Mean) * (v - d.Mean)
+	}
+	d.StdDev = math.Sqrt(variance / float64(len(values)))
+	return d
+}
+
+func fragmentation(free []float64) float64 {
+	var total, largest float64
+	for _, f := range free {
+		total += f
+		largest = math.Max(largest, f)
+	}
+	if total == 0 {
+		return 0
+	}
+	return 1 - largest/total
+}
@@ -26,6 +26,9 @@ var ErrEmptyConfig = errors.New("config is required, but empty")
 // configYaml represents the value from the config file.
 var configYaml = &v1alpha1.SimulatorConfiguration{}
 
+// DefaultSchedulerName is the name of the scheduler running in the "simulator-scheduler" container.
+const DefaultSchedulerName = "default"
+
 // defaultSchedulerCfgPath is where we have the scheduler config in the container by default.
 const defaultSchedulerCfgPath = "/config/scheduler.yaml"
 
@@ -50,6 +53,38 @@ type Config struct {
 	// This field should be set when ExternalImportEnabled == true or ResourceSyncEnabled == true.
 	ExternalKubeClientCfg *rest.Config
 	InitialSchedulerCfg   *configv1.KubeSchedulerConfiguration
+	// AdditionalSchedulers are the debuggable schedulers run along with the default one.
+	AdditionalSchedulers []AdditionalSchedulerConfig
+	// SchedulerRuntime is how the simulator runs the debuggable schedulers.
+	SchedulerRuntime SchedulerRuntime
+	// RecordCycleState indicates whether the schedulers in the simulator process record the CycleState.
+	RecordCycleState bool
+	// MockExtenders answer the requests for the extenders with their URL prefixes by the rules.
+	MockExtenders []v1alpha1.MockExtender
+	// ExtenderFaults are injected into the responses for the extenders proxied by the simulator.
+	ExtenderFaults []v1alpha1.ExtenderFault
+	// PluginExtenders are the debugging extenders attached to the plugins of the schedulers in the simulator process.
+	// The scheduler containers read them from the config file by themselves. See LoadPluginExtenders.
+	PluginExtenders []v1alpha1.PluginExtender
+}
+
+// SchedulerRuntime is how the simulator runs the debuggable schedulers.
+type SchedulerRuntime string
+
+const (
+	// DockerSchedulerRuntime runs the schedulers in containers, and restarts them via the Docker daemon.
+	DockerSchedulerRuntime SchedulerRuntime = "docker"
+	// InProcessSchedulerRuntime runs the schedulers in the simulator process.
+	InProcessSchedulerRuntime SchedulerRuntime = "inProcess"
+)
+
+// AdditionalSchedulerConfig is the configuration of a debuggable scheduler run in its own container.
+type AdditionalSchedulerConfig struct {
+	Name          string
+	ContainerName string
+	// ConfigPath is the path to the KubeSchedulerConfiguration file, which is shared with the container.
+	ConfigPath          string
+	InitialSchedulerCfg *configv1.KubeSchedulerConfiguration
 }
 
 const (
@@ -106,6 +141,26 @@ func NewConfig() (*Config, error) {
 		return nil, xerrors.Errorf("get SchedulerCfg: %w", err)
 	}
 
+	schedulerRuntime, err := getSchedulerRuntime()
+	if err != nil {
+		return nil, xerrors.Errorf("get scheduler runtime: %w", err)
+	}
+
+	additionalSchedulers, err := getAdditionalSchedulers(initialschedulerCfg, schedulerRuntime)
+	if err != nil {
+		return nil, xerrors.Errorf("get additional schedulers: %w", err)
+	}
+
+	mockExtenders, err := getMockExtenders(schedulerRuntime)
+	if err != nil {
+		return nil, xerrors.Errorf("get mock extenders: %w", err)
+	}
+
+	extenderFaults, err := getExtenderFaults(schedulerRuntime)
+	if err != nil {
+		return nil, xerrors.Errorf("get extender faults: %w", err)
+	}
+
 	return &Config{
 		Port:                        port,
 		KubeAPIServerURL:            apiurl,
@@ -118,6 +173,12 @@ func NewConfig() (*Config, error) {
 		ResourceSyncEnabled:         resourceSyncEnabled,
 		ReplayerEnabled:             replayerEnabled,
 		RecordFilePath:              recordFilePath,
+		AdditionalSchedulers:        additionalSchedulers,
+		SchedulerRuntime:            schedulerRuntime,
+		RecordCycleState:            getRecordCycleState(),
+		MockExtenders:               mockExtenders,
+		ExtenderFaults:              extenderFaults,
+		PluginExtenders:             configYaml.PluginExtenders,
 	}, nil
 }
 
@@ -128,21 +189,46 @@ func LoadYamlConfig(configFile string) error {
 		return nil
 	}
 
+	versionedConfig, err := readYamlConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	configYaml = versionedConfig
+
+	return nil
+}
+
+// LoadPluginExtenders reads pluginExtenders from the simulator config file.
+// It's used by the debuggable schedulers running in containers,
+// which are given the same config file as the simulator to attach the same debugging extenders.
+// It returns nil if configFile is empty.
+func LoadPluginExtenders(configFile string) ([]v1alpha1.PluginExtender, error) {
+	if configFile == "" {
+		return nil, nil
+	}
+
+	versionedConfig, err := readYamlConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return versionedConfig.PluginExtenders, nil
+}
+
+// readYamlConfig reads and decodes the simulator config file.
+func readYamlConfig(configFile string) (*v1alpha1.SimulatorConfiguration, error) {
 	conf, err := os.ReadFile(configFile)
 	if err != nil {
-		return xerrors.Errorf("failed to read config file: %w", err)
+		return nil, xerrors.Errorf("failed to read config file: %w", err)
 	}
 
 	versionedConfig := &v1alpha1.SimulatorConfiguration{}
 
 	decoder := scheme.Codecs.UniversalDecoder(v1alpha1.SchemeGroupVersion)
 	if err := runtime.DecodeInto(decoder, conf, versionedConfig); err != nil {
-		return xerrors.Errorf("failed decoding simulator's config %w", err)
+		return nil, xerrors.Errorf("failed decoding simulator's config %w", err)
 	}
-
-	configYaml = versionedConfig
-
-	return nil
+	return versionedConfig, nil
 }
 
 // getPort gets port from environment variable named PORT first, if empty from the config file.
@@ -243,7 +329,11 @@ func GetSchedulerCfg() (*configv1.KubeSchedulerConfiguration, error) {
 		}
 	}
 	config.SetKubeSchedulerCfgPath(kubeSchedulerConfigPath)
-	data, err := os.ReadFile(kubeSchedulerConfigPath)
+	return readSchedulerCfg(kubeSchedulerConfigPath)
+}
+
+func readSchedulerCfg(path string) (*configv1.KubeSchedulerConfiguration, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, xerrors.Errorf("read scheduler config file: %w", err)
 	}
@@ -256,6 +346,97 @@ func GetSchedulerCfg() (*configv1.KubeSchedulerConfiguration, error) {
 	return sc, nil
 }
 
+// getAdditionalSchedulers reads the configurations of the additional schedulers from the config file.
+// The schedulerNames in their profiles must not overlap with each other or with the default scheduler's,
+// because a Pod is routed to the scheduler by its spec.schedulerName.
+// containerName isn't required when the schedulers run in the simulator process.
+func getAdditionalSchedulers(defaultSchedulerCfg *configv1.KubeSchedulerConfiguration, schedulerRuntime SchedulerRuntime) ([]AdditionalSchedulerConfig, error) {
+	schedulers := make([]AdditionalSchedulerConfig, 0, len(configYaml.AdditionalSchedulers))
+	owners := map[string]string{}
+	for _, n := range config.ProfileSchedulerNames(defaultSchedulerCfg) {
+		owners[n] = DefaultSchedulerName
+	}
+	for _, as := range configYaml.AdditionalSchedulers {
+		if as.Name == "" || as.KubeSchedulerConfigPath == "" {
+			return nil, xerrors.Errorf("name and kubeSchedulerConfigPath are required for additional schedulers: %w", ErrEmptyConfig)
+		}
+		if as.ContainerName == "" && schedulerRuntime == DockerSchedulerRuntime {
+			return nil, xerrors.Errorf("containerName is required for additional schedulers run in containers: %w", ErrEmptyConfig)
+		}
+		if as.Name == DefaultSchedulerName {
+			return nil, xerrors.Errorf("the name %q is reserved for the scheduler in the simulator-scheduler container", DefaultSchedulerName)
+		}
+		cfg, err := readSchedulerCfg(as.KubeSchedulerConfigPath)
+		if err != nil {
+			return nil, xerrors.Errorf("get the scheduler config of %s: %w", as.Name, err)
+		}
+		for _, n := range config.ProfileSchedulerNames(cfg) {
+			if owner, ok := owners[n]; ok {
+				return nil, xerrors.Errorf("schedulerName %q is used by both of %s and %s", n, owner, as.Name)
+			}
+			owners[n] = as.Name
+		}
+		for _, s := range schedulers {
+			if s.Name == as.Name {
+				return nil, xerrors.Errorf("additional scheduler %s is defined twice", as.Name)
+			}
+		}
+		schedulers = append(schedulers, AdditionalSchedulerConfig{
+			Name:                as.Name,
+			ContainerName:       as.ContainerName,
+			ConfigPath:          as.KubeSchedulerConfigPath,
+			InitialSchedulerCfg: cfg,
+		})
+	}
+	return schedulers, nil
+}
+
+// getMockExtenders reads the mock extenders from the config file.
+// They're validated by extender.ValidateMockExtenders in the scheduler package.
+func getMockExtenders(schedulerRuntime SchedulerRuntime) ([]v1alpha1.MockExtender, error) {
+	if err := checkInProcessOnly("mockExtenders", len(configYaml.MockExtenders), schedulerRuntime); err != nil {
+		return nil, err
+	}
+	return configYaml.MockExtenders, nil
+}
+
+// getExtenderFaults reads the faults injected into the responses for the extenders from the config file.
+// They're validated by extender.ValidateExtenderFaults in the scheduler package.
+func getExtenderFaults(schedulerRuntime SchedulerRuntime) ([]v1alpha1.ExtenderFault, error) {
+	if err := checkInProcessOnly("extenderFaults", len(configYaml.ExtenderFaults), schedulerRuntime); err != nil {
+		return nil, err
+	}
+	return configYaml.ExtenderFaults, nil
+}
+
+// checkInProcessOnly returns an error when the field only available for the schedulers in the simulator process is set
+// while the schedulers run in containers, which would ignore it.
+// Such schedulers need to be built with the corresponding options of the debuggable scheduler instead.
+func checkInProcessOnly(field string, n int, schedulerRuntime SchedulerRuntime) error {
+	if n != 0 && schedulerRuntime != InProcessSchedulerRuntime {
+		return xerrors.Errorf("%s is only available with the %q scheduler runtime, but the runtime is %q", field, InProcessSchedulerRuntime, schedulerRuntime)
+	}
+	return nil
+}
+
+// getSchedulerRuntime reads SCHEDULER_RUNTIME
+// if empty from the config file.
+// It returns DockerSchedulerRuntime if neither is set.
+func getSchedulerRuntime() (SchedulerRuntime, error) {
+	r := os.Getenv("SCHEDULER_RUNTIME")
+	if r == "" {
+		r = configYaml.SchedulerRuntime
+	}
+	switch SchedulerRuntime(r) {
+	case "", DockerSchedulerRuntime:
+		return DockerSchedulerRuntime, nil
+	case InProcessSchedulerRuntime:
+		return InProcessSchedulerRuntime, nil
+	default:
+		return "", xerrors.Errorf("unknown scheduler runtime %q, it must be %q or %q", r, DockerSchedulerRuntime, InProcessSchedulerRuntime)
+	}
+}
+
 // getExternalImportEnabled reads EXTERNAL_IMPORT_ENABLED and convert it to bool
 // if empty from the config file.
 // This function will return `true` if `EXTERNAL_IMPORT_ENABLED` is "1".
@@ -292,6 +473,17 @@ func getReplayerEnabled() bool {
 	return replayerEnabled
 }
 
+// getRecordCycleState reads RECORD_CYCLE_STATE and converts it to bool
+// if empty from the config file.
+func getRecordCycleState() bool {
+	recordCycleStateString := os.Getenv("RECORD_CYCLE_STATE")
+	if recordCycleStateString == "" {
+		recordCycleStateString = strconv.FormatBool(configYaml.RecordCycleState)
+	}
+	recordCycleState, _ := strconv.ParseBool(recordCycleStateString)
+	return recordCycleState
+}
+
 // getRecordFilePath reads RECORD_FILE_PATH
 // if empty from the config file.
 func getRecordFilePath() string {
@@ -1,13 +1,18 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
 )
 
 func Test_decodeSchedulerCfg(t *testing.T) {
@@ -251,3 +256,104 @@ func Test_hasTwoOrMoreTrue(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkInProcessOnly(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		n                int
+		schedulerRuntime SchedulerRuntime
+		wantErr          bool
+	}{
+		{
+			name:             "set with inProcess runtime",
+			n:                1,
+			schedulerRuntime: InProcessSchedulerRuntime,
+			wantErr:          false,
+		},
+		{
+			name:             "set with docker runtime",
+			n:                1,
+			schedulerRuntime: DockerSchedulerRuntime,
+			wantErr:          true,
+		},
+		{
+			name:             "not set with docker runtime",
+			n:                0,
+			schedulerRuntime: DockerSchedulerRuntime,
+			wantErr:          false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkInProcessOnly("mockExtenders", tt.n, tt.schedulerRuntime)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadPluginExtenders(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		want    []v1alpha1.PluginExtender
+		wantErr bool
+	}{
+		{
+			name: "read pluginExtenders",
+			content: `
+apiVersion: kube-scheduler-simulator-config/v1alpha1
+kind: SimulatorConfiguration
+port: 1212
+pluginExtenders:
+  - plugin: ImageLocality
+    type: OverrideScore
+    score: 100
+`,
+			want: []v1alpha1.PluginExtender{{Plugin: "ImageLocality", Type: v1alpha1.PluginExtenderOverrideScore, Score: 100}},
+		},
+		{
+			name: "no pluginExtenders",
+			content: `
+apiVersion: kube-scheduler-simulator-config/v1alpha1
+kind: SimulatorConfiguration
+port: 1212
+`,
+			want: nil,
+		},
+		{
+			name:    "fail with broken config",
+			content: `pluginExtenders: [`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			got, err := LoadPluginExtenders(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadPluginExtenders_EmptyPath(t *testing.T) {
+	t.Parallel()
+	got, err := LoadPluginExtenders("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
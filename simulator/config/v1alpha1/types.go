@@ -77,4 +77,201 @@ type SimulatorConfiguration struct {
 	// This variable indicates whether an external scheduler
 	// is used.
 	ExternalSchedulerEnabled bool `json:"externalSchedulerEnabled,omitempty"`
+
+	// This variable indicates how the simulator runs the debuggable schedulers.
+	// "docker" (default) restarts the scheduler containers via the Docker daemon
+	// when the scheduler configuration is changed.
+	// "inProcess" runs the schedulers in the simulator process,
+	// so it works where the Docker daemon isn't reachable.
+	SchedulerRuntime string `json:"schedulerRuntime,omitempty"`
+
+	// This variable indicates whether the debuggable schedulers
+	// run in the simulator process record the CycleState entries
+	// written by the plugins in PreFilter and PreScore.
+	RecordCycleState bool `json:"recordCycleState,omitempty"`
+
+	// AdditionalSchedulers are debuggable schedulers run along with
+	// the one in the "simulator-scheduler" container.
+	// Each Pod is scheduled by the scheduler which has a profile
+	// for its spec.schedulerName.
+	AdditionalSchedulers []AdditionalScheduler `json:"additionalSchedulers,omitempty"`
+
+	// MockExtenders answer the requests for the extenders
+	// by the rules instead of the extender servers,
+	// so that the scheduler configurations with extenders
+	// can be simulated without running the extenders.
+	MockExtenders []MockExtender `json:"mockExtenders,omitempty"`
+
+	// ExtenderFaults are injected into the responses for the extenders
+	// by the simulator proxying their requests,
+	// so that you can see how the scheduler behaves on the extender outages.
+	ExtenderFaults []ExtenderFault `json:"extenderFaults,omitempty"`
+
+	// PluginExtenders are the debugging extenders from the built-in library
+	// attached to the plugins of the scheduler running in the simulator process.
+	// The extenders for the same plugin run in the order they're listed.
+	PluginExtenders []PluginExtender `json:"pluginExtenders,omitempty"`
+}
+
+// AdditionalScheduler is a debuggable scheduler run in its own container.
+type AdditionalScheduler struct {
+	// Name identifies the scheduler in the simulator's API.
+	Name string `json:"name"`
+
+	// ContainerName is the name of the container running the scheduler.
+	// The simulator restarts it when the configuration is changed.
+	// It's ignored when schedulerRuntime is "inProcess".
+	ContainerName string `json:"containerName"`
+
+	// The path to the KubeSchedulerConfiguration file of the scheduler.
+	// It must be mounted on the scheduler container as well
+	// unless schedulerRuntime is "inProcess".
+	KubeSchedulerConfigPath string `json:"kubeSchedulerConfigPath"`
+}
+
+// MockExtender answers the requests for the extender with the URL prefix by the rules.
+type MockExtender struct {
+	// URLPrefix is the urlPrefix of the extender in the KubeSchedulerConfiguration.
+	URLPrefix string `json:"urlPrefix"`
+
+	// Latency delays all the responses.
+	// The scheduler gives up the request when it exceeds httpTimeout of the extender.
+	Latency metav1.Duration `json:"latency,omitempty"`
+
+	// Filter rejects the Nodes which match any of the rules.
+	// The other Nodes pass the filter.
+	Filter []MockExtenderFilterRule `json:"filter,omitempty"`
+
+	// Prioritize scores the Nodes by the first rule they match.
+	// The Nodes matching no rule get 0.
+	Prioritize []MockExtenderPrioritizeRule `json:"prioritize,omitempty"`
+
+	// Failures make the requests matching them fail.
+	Failures []MockExtenderFailureRule `json:"failures,omitempty"`
+}
+
+// MockExtenderFilterRule rejects the Nodes matching nodeSelector for the Pods matching podSelector.
+// A nil selector matches everything.
+type MockExtenderFilterRule struct {
+	PodSelector  *metav1.LabelSelector `json:"podSelector,omitempty"`
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// Reason is the message why the Nodes are rejected.
+	Reason string `json:"reason,omitempty"`
+
+	// Unresolvable rejects the Nodes as the preemption doesn't help them.
+	Unresolvable bool `json:"unresolvable,omitempty"`
+}
+
+// MockExtenderPrioritizeRule scores the Nodes matching nodeSelector for the Pods matching podSelector.
+// A nil selector matches everything.
+type MockExtenderPrioritizeRule struct {
+	PodSelector  *metav1.LabelSelector `json:"podSelector,omitempty"`
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// Score is between 0 and 10 as the extenders return.
+	// It's scaled by the weight of the extender like the one from the extender servers.
+	Score int64 `json:"score"`
+}
+
+// MockExtenderFailureRule makes the requests of the verbs for the Pods matching podSelector fail.
+// A nil selector matches everything.
+type MockExtenderFailureRule struct {
+	// Verbs are some of "filter", "prioritize", "preempt" and "bind".
+	// It matches all the verbs when empty.
+	Verbs []string `json:"verbs,omitempty"`
+
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Error is the message of the failure.
+	Error string `json:"error"`
+}
+
+// ExtenderFaultType is the kind of the fault injected into the responses for the extenders.
+type ExtenderFaultType string
+
+const (
+	// ExtenderFaultTimeout doesn't call the extender, and responds with 504 after the delay.
+	// The delay is httpTimeout of the extender plus a second by default, so that the scheduler gives up the request.
+	ExtenderFaultTimeout ExtenderFaultType = "Timeout"
+	// ExtenderFaultServerError doesn't call the extender, and responds with the status code, 500 by default.
+	ExtenderFaultServerError ExtenderFaultType = "ServerError"
+	// ExtenderFaultPartialNodes drops the latter half of the Nodes in the result of filter or prioritize.
+	ExtenderFaultPartialNodes ExtenderFaultType = "PartialNodes"
+	// ExtenderFaultSlow delays the response from the extender.
+	ExtenderFaultSlow ExtenderFaultType = "Slow"
+	// ExtenderFaultMalformedResponse responds with the truncated JSON of the response from the extender.
+	ExtenderFaultMalformedResponse ExtenderFaultType = "MalformedResponse"
+)
+
+// ExtenderFault injects the fault into the responses for the extender at the rate.
+type ExtenderFault struct {
+	// URLPrefix is the urlPrefix of the extender in the KubeSchedulerConfiguration.
+	// It matches all the extenders when empty.
+	URLPrefix string `json:"urlPrefix,omitempty"`
+
+	// Verbs are some of "filter", "prioritize", "preempt" and "bind".
+	// It matches all the verbs when empty.
+	Verbs []string `json:"verbs,omitempty"`
+
+	Type ExtenderFaultType `json:"type"`
+
+	// Rate is the probability to inject the fault into a response, from 0 (exclusive) to 1.
+	Rate float64 `json:"rate"`
+
+	// Delay is how long Timeout and Slow delay the response.
+	Delay metav1.Duration `json:"delay,omitempty"`
+
+	// StatusCode is the status code ServerError responds with.
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// PluginExtenderType is the kind of the debugging extender attached to a plugin.
+type PluginExtenderType string
+
+const (
+	// PluginExtenderFailFilter rejects the Nodes matching nodeSelector in Filter after the plugin passes them.
+	PluginExtenderFailFilter PluginExtenderType = "FailFilter"
+	// PluginExtenderOverrideScore replaces the score the plugin gives to the Nodes matching nodeSelector.
+	PluginExtenderOverrideScore PluginExtenderType = "OverrideScore"
+	// PluginExtenderLog logs the inputs and the outputs of the plugin at all its extension points.
+	PluginExtenderLog PluginExtenderType = "Log"
+	// PluginExtenderSampleNodes narrows down the Nodes evaluated after PreFilter of the plugin to the number of Nodes.
+	PluginExtenderSampleNodes PluginExtenderType = "SampleNodes"
+	// PluginExtenderNodeInfoSnapshot records the NodeInfo the plugin sees in Filter.
+	PluginExtenderNodeInfoSnapshot PluginExtenderType = "NodeInfoSnapshot"
+	// PluginExtenderCELFilter rejects the Nodes for which expression is false in Filter before the plugin runs.
+	PluginExtenderCELFilter PluginExtenderType = "CELFilter"
+	// PluginExtenderCELScore replaces the score the plugin gives with the value of expression.
+	PluginExtenderCELScore PluginExtenderType = "CELScore"
+)
+
+// PluginExtender attaches the debugging extender of the type to the plugin
+// for the Pods matching podSelector and the Nodes matching nodeSelector.
+// A nil selector matches everything.
+type PluginExtender struct {
+	// Plugin is the name of the plugin in the KubeSchedulerConfiguration, e.g., "NodeResourcesFit".
+	Plugin string `json:"plugin"`
+
+	Type PluginExtenderType `json:"type"`
+
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// NodeSelector is ignored by SampleNodes.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// Expression is the CEL expression of CELFilter and CELScore.
+	// The Pod and the Node are given as `pod` and `node` in the same form as their manifests,
+	// e.g., `node.metadata.labels["gpu"] == "true"`.
+	// CELFilter's expression is a bool, and CELScore's one is an int between 0 and 100
+	// with the score the plugin gave as `score`.
+	Expression string `json:"expression,omitempty"`
+
+	// Reason is the message FailFilter and CELFilter reject the Nodes with.
+	Reason string `json:"reason,omitempty"`
+
+	// Score is the score OverrideScore gives, between 0 and 100.
+	Score int64 `json:"score,omitempty"`
+
+	// Nodes is the number of the Nodes SampleNodes keeps.
+	Nodes int `json:"nodes,omitempty"`
 }
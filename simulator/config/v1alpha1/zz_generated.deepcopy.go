@@ -22,9 +22,190 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalScheduler) DeepCopyInto(out *AdditionalScheduler) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalScheduler.
+func (in *AdditionalScheduler) DeepCopy() *AdditionalScheduler {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalScheduler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtenderFault) DeepCopyInto(out *ExtenderFault) {
+	*out = *in
+	if in.Verbs != nil {
+		in, out := &in.Verbs, &out.Verbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Delay = in.Delay
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtenderFault.
+func (in *ExtenderFault) DeepCopy() *ExtenderFault {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtenderFault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MockExtender) DeepCopyInto(out *MockExtender) {
+	*out = *in
+	out.Latency = in.Latency
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make([]MockExtenderFilterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prioritize != nil {
+		in, out := &in.Prioritize, &out.Prioritize
+		*out = make([]MockExtenderPrioritizeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]MockExtenderFailureRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MockExtender.
+func (in *MockExtender) DeepCopy() *MockExtender {
+	if in == nil {
+		return nil
+	}
+	out := new(MockExtender)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MockExtenderFailureRule) DeepCopyInto(out *MockExtenderFailureRule) {
+	*out = *in
+	if in.Verbs != nil {
+		in, out := &in.Verbs, &out.Verbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MockExtenderFailureRule.
+func (in *MockExtenderFailureRule) DeepCopy() *MockExtenderFailureRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MockExtenderFailureRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MockExtenderFilterRule) DeepCopyInto(out *MockExtenderFilterRule) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MockExtenderFilterRule.
+func (in *MockExtenderFilterRule) DeepCopy() *MockExtenderFilterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MockExtenderFilterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MockExtenderPrioritizeRule) DeepCopyInto(out *MockExtenderPrioritizeRule) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MockExtenderPrioritizeRule.
+func (in *MockExtenderPrioritizeRule) DeepCopy() *MockExtenderPrioritizeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MockExtenderPrioritizeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginExtender) DeepCopyInto(out *PluginExtender) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginExtender.
+func (in *PluginExtender) DeepCopy() *PluginExtender {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginExtender)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SimulatorConfiguration) DeepCopyInto(out *SimulatorConfiguration) {
 	*out = *in
@@ -34,6 +215,33 @@ func (in *SimulatorConfiguration) DeepCopyInto(out *SimulatorConfiguration) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.ResourceImportLabelSelector.DeepCopyInto(&out.ResourceImportLabelSelector)
+	if in.AdditionalSchedulers != nil {
+		in, out := &in.AdditionalSchedulers, &out.AdditionalSchedulers
+		*out = make([]AdditionalScheduler, len(*in))
+		copy(*out, *in)
+	}
+	if in.MockExtenders != nil {
+		in, out := &in.MockExtenders, &out.MockExtenders
+		*out = make([]MockExtender, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtenderFaults != nil {
+		in, out := &in.ExtenderFaults, &out.ExtenderFaults
+		*out = make([]ExtenderFault, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PluginExtenders != nil {
+		in, out := &in.PluginExtenders, &out.PluginExtenders
+		*out = make([]PluginExtender, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -0,0 +1,270 @@
+package dryrun
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/kubernetes/pkg/scheduler/backend/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	schedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+)
+
+// SchedulerService is the part of scheduler.Service used to get the current scheduler configuration.
+type SchedulerService interface {
+	GetSchedulerConfig() (*configv1.KubeSchedulerConfiguration, error)
+}
+
+// Service runs scheduling cycles for Pods against a snapshot of the cluster
+// without creating the Pods or changing anything in the cluster.
+//
+// It builds the scheduling frameworks from the current scheduler configuration with the wrapped plugins,
+// so that the results have the same format as the ones the simulator adds to Pods' annotations.
+// Note that the extenders are not called in dry-runs,
+// and only in-tree plugins are available since out-of-tree plugins are registered in the scheduler process.
+type Service struct {
+	// mu serializes dry-runs because they share the frameworks built for the current scheduler configuration.
+	mu sync.Mutex
+
+	client          clientset.Interface
+	informerFactory informers.SharedInformerFactory
+	nodeLister      listersv1.NodeLister
+	podLister       listersv1.PodLister
+	schedService    SchedulerService
+
+	// cfg is the scheduler configuration that current is built from.
+	cfg     *configv1.KubeSchedulerConfiguration
+	current *frameworks
+}
+
+// Result is the result of a dry-run for one Pod.
+type Result struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	SchedulerName string `json:"schedulerName"`
+	// NodeName is the name of the node that the Pod would be placed on.
+	// It's empty when the Pod is unschedulable.
+	NodeName string `json:"nodeName"`
+	// Message describes why the Pod is unschedulable.
+	Message string `json:"message,omitempty"`
+	// Rejections has the plugins which rejected the Pod. It's empty when the Pod is schedulable.
+	Rejections []Rejection `json:"rejections,omitempty"`
+	// Results has the results of each plugin in the same format as the annotations on Pods scheduled in the simulator.
+	Results map[string]string `json:"results,omitempty"`
+}
+
+// Rejection describes why a plugin rejected the Pod.
+type Rejection struct {
+	// NodeName is the node on which the Filter plugin rejected the Pod.
+	// It's empty when the Pod is rejected in PreFilter.
+	NodeName string   `json:"nodeName,omitempty"`
+	Plugin   string   `json:"plugin"`
+	Reasons  []string `json:"reasons"`
+}
+
+// NewService initializes Service.
+func NewService(client clientset.Interface, schedService SchedulerService) *Service {
+	// The framework records metrics in each extension point, and they need to be initialized beforehand.
+	metrics.Register()
+
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	return &Service{
+		client:          client,
+		informerFactory: informerFactory,
+		nodeLister:      informerFactory.Core().V1().Nodes().Lister(),
+		podLister:       informerFactory.Core().V1().Pods().Lister(),
+		schedService:    schedService,
+	}
+}
+
+// Schedule runs the scheduling cycles for pods in order with the current scheduler configuration.
+// Each Pod is assumed to be placed on the selected node in the scheduling cycles of the following Pods,
+// so that a batch of Pods can be evaluated at once.
+// The passed pods aren't modified.
+func (s *Service) Schedule(ctx context.Context, pods []v1.Pod) ([]Result, error) {
+	return s.run(ctx, func(i int, _ []Result) *v1.Pod {
+		if i >= len(pods) {
+			return nil
+		}
+		return pods[i].DeepCopy()
+	})
+}
+
+// ScheduleReplicas runs the scheduling cycles for replicas of template one by one
+// until a replica becomes unschedulable or maxReplicas replicas are placed.
+// Like Schedule, each replica is assumed to be placed on the selected node in the following scheduling cycles.
+// The returned results end with the unschedulable replica's one unless maxReplicas replicas are placed.
+func (s *Service) ScheduleReplicas(ctx context.Context, template *v1.Pod, maxReplicas int) ([]Result, error) {
+	return s.run(ctx, func(i int, results []Result) *v1.Pod {
+		if i >= maxReplicas || (i > 0 && results[i-1].NodeName == "") {
+			return nil
+		}
+		pod := template.DeepCopy()
+		if pod.GenerateName == "" && pod.Name != "" {
+			pod.GenerateName = pod.Name + "-"
+		}
+		// Each replica gets its own name and UID in defaultPod.
+		pod.Name = ""
+		pod.UID = ""
+		return pod
+	})
+}
+
+// run runs the scheduling cycles for the Pods returned from next until it returns nil.
+// next receives the index of the Pod and the results of the previous Pods.
+func (s *Service) run(ctx context.Context, next func(i int, results []Result) *v1.Pod) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg, err := s.schedService.GetSchedulerConfig()
+	if err != nil && !errors.Is(err, scheduler.ErrServiceDisabled) {
+		return nil, xerrors.Errorf("get scheduler config: %w", err)
+	}
+	if cfg == nil {
+		cfg, err = schedconfig.DefaultSchedulerConfig()
+		if err != nil {
+			return nil, xerrors.Errorf("get default scheduler config: %w", err)
+		}
+	}
+	if s.current == nil || !apiequality.Semantic.DeepEqual(s.cfg, cfg) {
+		fwks, err := s.newFrameworks(ctx, cfg)
+		if err != nil {
+			return nil, xerrors.Errorf("create frameworks: %w", err)
+		}
+		if s.current != nil {
+			s.current.stop()
+		}
+		s.cfg = cfg.DeepCopy()
+		s.current = fwks
+	}
+
+	nodes, pods, err := s.ClusterState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sim := newSimulation(s.current, nodes, pods)
+
+	results := []Result{}
+	for i := 0; ; i++ {
+		pod := next(i, results)
+		if pod == nil {
+			break
+		}
+		r, err := sim.Schedule(ctx, pod)
+		if err != nil {
+			return nil, xerrors.Errorf("run scheduling cycle for pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// NewSimulation creates a Simulation with cfg on the given nodes and Pods.
+// Pods without spec.nodeName are ignored.
+// When both nodes and pods are nil, the Simulation starts from the current state of the cluster.
+// The returned Simulation must be closed after use.
+func (s *Service) NewSimulation(ctx context.Context, cfg *configv1.KubeSchedulerConfiguration, nodes []*v1.Node, pods []*v1.Pod) (*Simulation, error) {
+	if nodes == nil && pods == nil {
+		var err error
+		nodes, pods, err = s.ClusterState(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fwks, err := s.newFrameworks(ctx, cfg)
+	if err != nil {
+		return nil, xerrors.Errorf("create frameworks: %w", err)
+	}
+	sim := newSimulation(fwks, nodes, pods)
+	sim.closeFn = fwks.stop
+	return sim, nil
+}
+
+// ClusterState returns the nodes and the Pods in the cluster.
+func (s *Service) ClusterState(ctx context.Context) ([]*v1.Node, []*v1.Pod, error) {
+	if err := s.startInformers(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := s.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, xerrors.Errorf("list nodes: %w", err)
+	}
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, xerrors.Errorf("list pods: %w", err)
+	}
+	return nodes, pods, nil
+}
+
+// newFrameworks builds the frameworks for each profile in cfg with the wrapped plugins.
+func (s *Service) newFrameworks(ctx context.Context, cfg *configv1.KubeSchedulerConfiguration) (*frameworks, error) {
+	versioned, err := scheduler.ConvertConfigurationForSimulator(cfg.DeepCopy())
+	if err != nil {
+		return nil, xerrors.Errorf("convert scheduler config for simulator: %w", err)
+	}
+	internalCfg, err := scheduler.ConvertSchedulerConfigToInternalConfig(versioned)
+	if err != nil {
+		return nil, xerrors.Errorf("convert scheduler config to internal one: %w", err)
+	}
+	registry, stores, err := plugin.NewRegistryWithResultStore(internalCfg, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("create plugin registry: %w", err)
+	}
+
+	fwkCtx, cancel := context.WithCancel(context.Background())
+	fwks := &frameworks{
+		profiles: make(map[string]framework.Framework, len(internalCfg.Profiles)),
+		stores:   stores,
+		lister:   &snapshotLister{snapshot: cache.NewSnapshot(nil, nil)},
+		stop:     cancel,
+	}
+	for i := range internalCfg.Profiles {
+		fwk, err := frameworkruntime.NewFramework(fwkCtx, registry, &internalCfg.Profiles[i],
+			frameworkruntime.WithClientSet(s.client),
+			frameworkruntime.WithInformerFactory(s.informerFactory),
+			frameworkruntime.WithSnapshotSharedLister(fwks.lister),
+			frameworkruntime.WithParallelism(int(internalCfg.Parallelism)),
+		)
+		if err != nil {
+			cancel()
+			return nil, xerrors.Errorf("create framework for profile %s: %w", internalCfg.Profiles[i].SchedulerName, err)
+		}
+		fwks.profiles[internalCfg.Profiles[i].SchedulerName] = fwk
+	}
+
+	// The plugins may register new informers on creation.
+	if err := s.startInformers(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return fwks, nil
+}
+
+// startInformers starts the informers which aren't started yet, and waits for their caches to be synced.
+func (s *Service) startInformers(ctx context.Context) error {
+	s.informerFactory.Start(wait.NeverStop)
+	for typ, synced := range s.informerFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return xerrors.Errorf("wait for cache sync of %v", typ)
+		}
+	}
+	return nil
+}
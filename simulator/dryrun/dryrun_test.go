@@ -0,0 +1,157 @@
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+)
+
+type fakeSchedulerService struct{}
+
+func (fakeSchedulerService) GetSchedulerConfig() (*configv1.KubeSchedulerConfiguration, error) {
+	return nil, nil
+}
+
+func node(name, cpu string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse("10Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse("10Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func pod(name, cpu string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "container",
+					Image: "image",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestService_Schedule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		pods          []v1.Pod
+		wantNodeNames []string
+	}{
+		{
+			name:          "pod is placed on the only node which has enough cpu",
+			objects:       []runtime.Object{node("node-1", "1"), node("node-2", "4")},
+			pods:          []v1.Pod{pod("pod-1", "2")},
+			wantNodeNames: []string{"node-2"},
+		},
+		{
+			name:          "pod is unschedulable when no node has enough cpu",
+			objects:       []runtime.Object{node("node-1", "1"), node("node-2", "1")},
+			pods:          []v1.Pod{pod("pod-1", "2")},
+			wantNodeNames: []string{""},
+		},
+		{
+			name:          "pods in a batch take the earlier placements into account",
+			objects:       []runtime.Object{node("node-1", "4")},
+			pods:          []v1.Pod{pod("pod-1", "3"), pod("pod-2", "3")},
+			wantNodeNames: []string{"node-1", ""},
+		},
+		{
+			name: "existing pods on nodes are taken into account",
+			objects: func() []runtime.Object {
+				p := pod("existing", "3")
+				p.Spec.NodeName = "node-1"
+				return []runtime.Object{node("node-1", "4"), node("node-2", "4"), &p}
+			}(),
+			pods:          []v1.Pod{pod("pod-1", "3")},
+			wantNodeNames: []string{"node-2"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := fake.NewSimpleClientset(tt.objects...)
+			s := NewService(client, fakeSchedulerService{})
+
+			results, err := s.Schedule(context.Background(), tt.pods)
+			require.NoError(t, err)
+			require.Len(t, results, len(tt.wantNodeNames))
+			for i, r := range results {
+				assert.Equal(t, tt.wantNodeNames[i], r.NodeName)
+				assert.NotEmpty(t, r.Results[annotation.FilterResultAnnotationKey])
+				if r.NodeName == "" {
+					assert.Contains(t, r.Message, "Insufficient cpu")
+					continue
+				}
+				assert.Equal(t, r.NodeName, r.Results[annotation.SelectedNodeAnnotationKey])
+				assert.NotEmpty(t, r.Results[annotation.FinalScoreResultAnnotationKey])
+			}
+
+			// The dry-run must not create any pod.
+			pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			for _, p := range pods.Items {
+				assert.Equal(t, "existing", p.Name)
+			}
+		})
+	}
+}
+
+func TestService_ScheduleReplicas(t *testing.T) {
+	t.Parallel()
+	client := fake.NewSimpleClientset(node("node-1", "4"), node("node-2", "3"))
+	s := NewService(client, fakeSchedulerService{})
+
+	template := pod("replica", "2")
+	results, err := s.ScheduleReplicas(context.Background(), &template, 10)
+	require.NoError(t, err)
+	// node-1 and node-2 can take 2 and 1 replicas respectively, and the 4th replica is unschedulable.
+	require.Len(t, results, 4)
+	placed := map[string]int{}
+	names := map[string]bool{}
+	for _, r := range results[:3] {
+		placed[r.NodeName]++
+		names[r.Name] = true
+	}
+	assert.Equal(t, map[string]int{"node-1": 2, "node-2": 1}, placed)
+	assert.Len(t, names, 3)
+	assert.Empty(t, results[3].NodeName)
+	assert.Len(t, results[3].Rejections, 2)
+	for _, r := range results[3].Rejections {
+		assert.Equal(t, "NodeResourcesFit", r.Plugin)
+		assert.Equal(t, []string{"Insufficient cpu"}, r.Reasons)
+	}
+
+	results, err = s.ScheduleReplicas(context.Background(), &template, 2)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
@@ -0,0 +1,341 @@
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/pkg/scheduler/backend/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+)
+
+// frameworks has the frameworks built from a scheduler configuration, keyed by the scheduler name of each profile.
+type frameworks struct {
+	profiles map[string]framework.Framework
+	// stores have the results of the wrapped plugins in the frameworks.
+	stores *plugin.ResultStores
+	// lister is the snapshot referred from the frameworks.
+	lister *snapshotLister
+	// stop stops the goroutines started by the frameworks.
+	stop context.CancelFunc
+}
+
+// snapshotLister is the framework.SharedLister whose snapshot can be replaced in each scheduling cycle.
+type snapshotLister struct {
+	snapshot *cache.Snapshot
+}
+
+func (l *snapshotLister) NodeInfos() framework.NodeInfoLister {
+	return l.snapshot.NodeInfos()
+}
+
+func (l *snapshotLister) StorageInfos() framework.StorageInfoLister {
+	return l.snapshot.StorageInfos()
+}
+
+// Simulation runs scheduling cycles on its own nodes and Pods, which are separated from the cluster.
+// It's not safe for concurrent use.
+type Simulation struct {
+	fwks *frameworks
+	// nodes is keyed by the node name.
+	nodes map[string]*v1.Node
+	// pods has the Pods placed on nodes, keyed by "namespace/name".
+	pods map[string]*v1.Pod
+	// scheduled is the number of Pods passed to Schedule, which is used to name Pods without a name.
+	scheduled int
+	closeFn   func()
+}
+
+func newSimulation(fwks *frameworks, nodes []*v1.Node, pods []*v1.Pod) *Simulation {
+	sim := &Simulation{
+		fwks:  fwks,
+		nodes: make(map[string]*v1.Node, len(nodes)),
+		pods:  make(map[string]*v1.Pod, len(pods)),
+	}
+	for _, n := range nodes {
+		sim.nodes[n.Name] = n
+	}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		sim.pods[podKey(p.Namespace, p.Name)] = p
+	}
+	return sim
+}
+
+func podKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// Close stops the frameworks used in the Simulation.
+func (sim *Simulation) Close() {
+	if sim.closeFn != nil {
+		sim.closeFn()
+	}
+}
+
+// AddNode adds node to the Simulation, or replaces the node with the same name.
+func (sim *Simulation) AddNode(node *v1.Node) {
+	sim.nodes[node.Name] = node
+}
+
+// DeleteNode deletes the node from the Simulation. The Pods on the node are deleted together.
+func (sim *Simulation) DeleteNode(name string) {
+	delete(sim.nodes, name)
+	for k, p := range sim.pods {
+		if p.Spec.NodeName == name {
+			delete(sim.pods, k)
+		}
+	}
+}
+
+// DeletePod deletes the Pod from the Simulation.
+func (sim *Simulation) DeletePod(namespace, name string) {
+	delete(sim.pods, podKey(namespace, name))
+}
+
+// Nodes returns the nodes in the Simulation sorted by name.
+func (sim *Simulation) Nodes() []*v1.Node {
+	ret := make([]*v1.Node, 0, len(sim.nodes))
+	for _, n := range sim.nodes {
+		ret = append(ret, n)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// Pods returns the Pods placed on nodes in the Simulation sorted by namespace and name.
+func (sim *Simulation) Pods() []*v1.Pod {
+	ret := make([]*v1.Pod, 0, len(sim.pods))
+	for _, p := range sim.pods {
+		ret = append(ret, p)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return podKey(ret[i].Namespace, ret[i].Name) < podKey(ret[j].Namespace, ret[j].Name)
+	})
+	return ret
+}
+
+// Schedule runs the scheduling cycle for pod, and places it on the selected node in the Simulation.
+// The passed pod isn't modified, and its spec.nodeName is ignored.
+func (sim *Simulation) Schedule(ctx context.Context, pod *v1.Pod) (Result, error) {
+	pod = pod.DeepCopy()
+	pod.Spec.NodeName = ""
+	defaultPod(pod, sim.scheduled)
+	sim.scheduled++
+
+	sim.fwks.lister.snapshot = cache.NewSnapshot(sim.podList(nil), sim.Nodes())
+	r, err := sim.schedule(ctx, pod)
+	if err != nil {
+		return r, err
+	}
+
+	if r.NodeName != "" {
+		pod.Spec.NodeName = r.NodeName
+		sim.pods[podKey(pod.Namespace, pod.Name)] = pod
+	}
+	return r, nil
+}
+
+// WouldPreempt returns true when pod would fit on some node if all Pods with lower priority were evicted.
+// It only runs PreFilter and Filter, and doesn't change the Simulation.
+// Note that it's an approximation of the preemption, which selects the victims on one node.
+func (sim *Simulation) WouldPreempt(ctx context.Context, pod *v1.Pod) (bool, error) {
+	pod = pod.DeepCopy()
+	pod.Spec.NodeName = ""
+	defaultPod(pod, sim.scheduled)
+
+	fwk, ok := sim.fwks.profiles[pod.Spec.SchedulerName]
+	if !ok {
+		return false, nil
+	}
+	defer sim.fwks.stores.Get(pod.Spec.SchedulerName).DeleteData(*pod)
+
+	priority := podPriority(pod)
+	sim.fwks.lister.snapshot = cache.NewSnapshot(sim.podList(func(p *v1.Pod) bool {
+		return podPriority(p) >= priority
+	}), sim.Nodes())
+
+	state := framework.NewCycleState()
+	feasible, _, status, err := sim.runFilters(ctx, fwk, state, pod)
+	if err != nil {
+		return false, err
+	}
+	return status.IsSuccess() && len(feasible) != 0, nil
+}
+
+func (sim *Simulation) podList(filter func(*v1.Pod) bool) []*v1.Pod {
+	ret := make([]*v1.Pod, 0, len(sim.pods))
+	for _, p := range sim.pods {
+		if filter != nil && !filter(p) {
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
+// schedule runs PreFilter, Filter, PreScore and Score on the current snapshot for pod.
+// The Reserve, Permit and binding phases aren't run since they may change the cluster,
+// and neither is PostFilter since the preemption deletes the victim Pods.
+func (sim *Simulation) schedule(ctx context.Context, pod *v1.Pod) (Result, error) {
+	r := Result{
+		Namespace:     pod.Namespace,
+		Name:          pod.Name,
+		SchedulerName: pod.Spec.SchedulerName,
+	}
+	fwk, ok := sim.fwks.profiles[pod.Spec.SchedulerName]
+	if !ok {
+		r.Message = fmt.Sprintf("no profile is configured for scheduler name %q", pod.Spec.SchedulerName)
+		return r, nil
+	}
+	store := sim.fwks.stores.Get(pod.Spec.SchedulerName)
+	defer store.DeleteData(*pod)
+
+	state := framework.NewCycleState()
+	feasible, rejections, status, err := sim.runFilters(ctx, fwk, state, pod)
+	if err != nil {
+		return r, err
+	}
+	if !status.IsSuccess() {
+		r.Message = status.Message()
+		r.Rejections = rejections
+		r.Results = store.GetStoredResult(pod)
+		return r, nil
+	}
+
+	if status := fwk.RunPreScorePlugins(ctx, state, pod, feasible); !status.IsSuccess() {
+		return r, xerrors.Errorf("run PreScore plugins: %w", status.AsError())
+	}
+	scores, status := fwk.RunScorePlugins(ctx, state, pod, feasible)
+	if !status.IsSuccess() {
+		return r, xerrors.Errorf("run Score plugins: %w", status.AsError())
+	}
+
+	r.NodeName = selectHost(scores)
+	store.AddSelectedNode(pod.Namespace, pod.Name, r.NodeName)
+	r.Results = store.GetStoredResult(pod)
+
+	return r, nil
+}
+
+// runFilters runs PreFilter and Filter plugins, and returns the feasible nodes.
+// When no node is feasible, it returns the rejections and the unschedulable status.
+func (sim *Simulation) runFilters(ctx context.Context, fwk framework.Framework, state *framework.CycleState, pod *v1.Pod) ([]*framework.NodeInfo, []Rejection, *framework.Status, error) {
+	nodeInfos, err := sim.fwks.lister.NodeInfos().List()
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("list node infos: %w", err)
+	}
+
+	preFilterResult, status, _ := fwk.RunPreFilterPlugins(ctx, state, pod)
+	if !status.IsSuccess() {
+		if !status.IsRejected() {
+			return nil, nil, nil, xerrors.Errorf("run PreFilter plugins: %w", status.AsError())
+		}
+		rejections := []Rejection{{Plugin: plugin.OriginalPluginName(status.Plugin()), Reasons: status.Reasons()}}
+		return nil, rejections, status, nil
+	}
+	if !preFilterResult.AllNodes() {
+		filtered := make([]*framework.NodeInfo, 0, len(preFilterResult.NodeNames))
+		for _, n := range nodeInfos {
+			if preFilterResult.NodeNames.Has(n.Node().Name) {
+				filtered = append(filtered, n)
+			}
+		}
+		nodeInfos = filtered
+	}
+
+	statuses := make([]*framework.Status, len(nodeInfos))
+	fwk.Parallelizer().Until(ctx, len(nodeInfos), func(i int) {
+		statuses[i] = fwk.RunFilterPlugins(ctx, state, pod, nodeInfos[i])
+	}, "Filter")
+	feasible := make([]*framework.NodeInfo, 0, len(nodeInfos))
+	var rejections []Rejection
+	for i, st := range statuses {
+		if st.IsSuccess() {
+			feasible = append(feasible, nodeInfos[i])
+			continue
+		}
+		if !st.IsRejected() {
+			return nil, nil, nil, xerrors.Errorf("run Filter plugins on node %s: %w", nodeInfos[i].Node().Name, st.AsError())
+		}
+		rejections = append(rejections, Rejection{
+			NodeName: nodeInfos[i].Node().Name,
+			Plugin:   plugin.OriginalPluginName(st.Plugin()),
+			Reasons:  st.Reasons(),
+		})
+	}
+	if len(feasible) == 0 {
+		msg := unschedulableMessage(sim.fwks.lister.snapshot.NumNodes(), statuses)
+		return nil, rejections, framework.NewStatus(framework.Unschedulable, msg), nil
+	}
+
+	return feasible, nil, nil, nil
+}
+
+// selectHost returns the node with the highest total score.
+// Unlike the scheduler, which chooses one of the nodes with the highest score at random,
+// it chooses the one with the smallest name so that the result is stable.
+func selectHost(scores []framework.NodePluginScores) string {
+	var selected *framework.NodePluginScores
+	for i := range scores {
+		s := &scores[i]
+		if selected == nil || s.TotalScore > selected.TotalScore || (s.TotalScore == selected.TotalScore && s.Name < selected.Name) {
+			selected = s
+		}
+	}
+	return selected.Name
+}
+
+// unschedulableMessage builds the message like the scheduler's FitError
+// (e.g., "0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had untolerated taint.").
+func unschedulableMessage(numAllNodes int, statuses []*framework.Status) string {
+	reasons := map[string]int{}
+	for _, st := range statuses {
+		for _, reason := range st.Reasons() {
+			reasons[reason]++
+		}
+	}
+	strs := make([]string, 0, len(reasons))
+	for reason, count := range reasons {
+		strs = append(strs, strconv.Itoa(count)+" "+reason)
+	}
+	sort.Strings(strs)
+
+	msg := fmt.Sprintf("0/%d nodes are available", numAllNodes)
+	if len(strs) != 0 {
+		msg += ": " + strings.Join(strs, ", ")
+	}
+	return msg + "."
+}
+
+// defaultPod fills the fields which the scheduling framework expects to be set.
+func defaultPod(pod *v1.Pod, index int) {
+	if pod.Namespace == "" {
+		pod.Namespace = v1.NamespaceDefault
+	}
+	if pod.Name == "" {
+		pod.Name = pod.GenerateName + "dryrun-" + strconv.Itoa(index)
+	}
+	if pod.UID == "" {
+		pod.UID = uuid.NewUUID()
+	}
+	if pod.Spec.SchedulerName == "" {
+		pod.Spec.SchedulerName = v1.DefaultSchedulerName
+	}
+}
@@ -0,0 +1,149 @@
+// Package gang shows the groups (gangs) of Pods scheduled together by coscheduling-style Permit plugins,
+// based on the gang results recorded on the Pods by the debuggable scheduler.
+package gang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/resultstore"
+)
+
+// ErrGroupNotFound is returned when no Pod belongs to the group.
+var ErrGroupNotFound = errors.New("pod group not found")
+
+// Status summarizes the outcomes of the members of a group.
+type Status string
+
+const (
+	// StatusTimedOut means at least one member timed out waiting for the others.
+	StatusTimedOut Status = "timedOut"
+	// StatusRejected means at least one member was rejected, and no member timed out.
+	StatusRejected Status = "rejected"
+	// StatusWaiting means at least one member is waiting, and no member was rejected.
+	StatusWaiting Status = "waiting"
+	// StatusAdmitted means the members which reached the Permit phase were all admitted.
+	StatusAdmitted Status = "admitted"
+	// StatusPending means no member has reached the Permit phase.
+	StatusPending Status = "pending"
+)
+
+// Service shows the groups of Pods.
+type Service struct {
+	client clientset.Interface
+}
+
+// Group is a group of Pods.
+type Group struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Status    Status   `json:"status"`
+	Members   []Member `json:"members"`
+}
+
+// Member is a Pod in a group.
+type Member struct {
+	Name     string `json:"name"`
+	NodeName string `json:"nodeName,omitempty"`
+	// Result is the result of the latest Permit phase of the Pod.
+	// It's nil when the Pod hasn't reached the Permit phase, e.g., it didn't pass the filters.
+	Result *resultstore.GangResult `json:"result,omitempty"`
+}
+
+// NewService initializes Service.
+func NewService(client clientset.Interface) *Service {
+	return &Service{client: client}
+}
+
+// List returns the groups in the namespace ordered by their namespaces and names.
+// All namespaces are used if the namespace is empty.
+func (s *Service) List(ctx context.Context, namespace string) ([]Group, error) {
+	pods, err := s.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("list pods: %w", err)
+	}
+
+	groups := map[string]*Group{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		g := resultstore.PodGroup(pod)
+		if g == "" {
+			continue
+		}
+		if _, ok := groups[g]; !ok {
+			groups[g] = &Group{Namespace: pod.Namespace, Name: strings.TrimPrefix(g, pod.Namespace+"/"), Members: []Member{}}
+		}
+		groups[g].Members = append(groups[g].Members, Member{Name: pod.Name, NodeName: pod.Spec.NodeName, Result: gangResult(pod)})
+	}
+
+	ret := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.Members, func(i, j int) bool { return g.Members[i].Name < g.Members[j].Name })
+		g.Status = groupStatus(g.Members)
+		ret = append(ret, *g)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Namespace != ret[j].Namespace {
+			return ret[i].Namespace < ret[j].Namespace
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// Get returns the group with the name in the namespace.
+func (s *Service) Get(ctx context.Context, namespace, name string) (*Group, error) {
+	groups, err := s.List(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Name == name {
+			return &groups[i], nil
+		}
+	}
+	return nil, xerrors.Errorf("get group %s/%s: %w", namespace, name, ErrGroupNotFound)
+}
+
+func gangResult(pod *corev1.Pod) *resultstore.GangResult {
+	a, ok := pod.GetAnnotations()[annotation.GangResultAnnotationKey]
+	if !ok {
+		return nil
+	}
+	r := &resultstore.GangResult{}
+	if err := json.Unmarshal([]byte(a), r); err != nil {
+		klog.ErrorS(err, "cannot parse "+annotation.GangResultAnnotationKey, "pod", klog.KObj(pod))
+		return nil
+	}
+	return r
+}
+
+func groupStatus(members []Member) Status {
+	outcomes := map[resultstore.GangOutcome]bool{}
+	for _, m := range members {
+		if m.Result != nil {
+			outcomes[m.Result.Outcome] = true
+		}
+	}
+	switch {
+	case outcomes[resultstore.GangOutcomeTimedOut]:
+		return StatusTimedOut
+	case outcomes[resultstore.GangOutcomeRejected]:
+		return StatusRejected
+	case outcomes[resultstore.GangOutcomeWaiting]:
+		return StatusWaiting
+	case outcomes[resultstore.GangOutcomeAdmitted]:
+		return StatusAdmitted
+	}
+	return StatusPending
+}
@@ -0,0 +1,68 @@
+package gang
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/resultstore"
+)
+
+func groupPod(name, group, gangResult string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"scheduling.x-k8s.io/pod-group": group},
+		},
+	}
+	if gangResult != "" {
+		pod.Annotations = map[string]string{annotation.GangResultAnnotationKey: gangResult}
+	}
+	return pod
+}
+
+func TestService_List(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset(
+		groupPod("pod1", "pg1", `{"group":"default/pg1","plugin":"Coscheduling","outcome":"timedOut","waited":"10s","timeout":"10s"}`),
+		groupPod("pod2", "pg1", ""),
+		groupPod("pod3", "pg2", `{"group":"default/pg2","plugin":"Coscheduling","outcome":"admitted","waited":"1s","timeout":"10s","waitingWith":["default/pod4"]}`),
+		groupPod("pod4", "pg2", `{"group":"default/pg2","plugin":"Coscheduling","outcome":"admitted","waited":"0s","timeout":"0s"}`),
+		groupPod("pod5", "pg3", ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "not-in-group", Namespace: "default"}},
+	)
+	s := NewService(client)
+
+	got, err := s.List(context.Background(), "default")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "pg1", got[0].Name)
+	assert.Equal(t, StatusTimedOut, got[0].Status)
+	require.Len(t, got[0].Members, 2)
+	assert.Equal(t, resultstore.GangOutcomeTimedOut, got[0].Members[0].Result.Outcome)
+	assert.Nil(t, got[0].Members[1].Result)
+
+	assert.Equal(t, "pg2", got[1].Name)
+	assert.Equal(t, StatusAdmitted, got[1].Status)
+	assert.Equal(t, []string{"default/pod4"}, got[1].Members[0].Result.WaitingWith)
+
+	assert.Equal(t, "pg3", got[2].Name)
+	assert.Equal(t, StatusPending, got[2].Status)
+
+	g, err := s.Get(context.Background(), "default", "pg2")
+	require.NoError(t, err)
+	assert.Equal(t, got[1], *g)
+
+	_, err = s.Get(context.Background(), "default", "unknown")
+	assert.True(t, errors.Is(err, ErrGroupNotFound))
+}
@@ -0,0 +1,237 @@
+package optimizer
+
+import (
+	"context"
+	"math"
+
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/rescore"
+)
+
+// Objective is what the optimization maximizes.
+type Objective string
+
+const (
+	// ObjectiveBinPacking is the average utilization of the Nodes which have at least one Pod.
+	// It gets higher as the Pods are packed into fewer Nodes.
+	ObjectiveBinPacking Objective = "binPacking"
+	// ObjectiveSpread is one minus the standard deviation of the utilization of all Nodes.
+	// It gets higher as the Pods are spread evenly.
+	ObjectiveSpread Objective = "spread"
+	// ObjectiveAgreement is the ratio of the Pods placed on the Node in Request.ExpectedPlacements.
+	ObjectiveAgreement Objective = "agreement"
+)
+
+// usage is the resources requested by Pods. cpu is in millicores and memory is in bytes.
+type usage struct {
+	pods   int
+	cpu    int64
+	memory int64
+}
+
+func (u *usage) add(o usage) {
+	u.pods += o.pods
+	u.cpu += o.cpu
+	u.memory += o.memory
+}
+
+// evalPod is a Pod used for the evaluation.
+type evalPod struct {
+	recorded *rescore.RecordedScores
+	requests usage
+	// expected is the Node in Request.ExpectedPlacements. It's empty when the Pod has no expectation.
+	expected string
+}
+
+// evaluator calculates the objective for a set of weights.
+type evaluator struct {
+	objective Objective
+	pods      []evalPod
+	skipped   int
+	nodes     []string
+	// allocatable is the allocatable resources of each Node.
+	allocatable map[string]usage
+	// baseUsage is the resources requested by the Pods on each Node which aren't rescored.
+	baseUsage map[string]usage
+}
+
+func newEvaluator(ctx context.Context, client clientset.Interface, req *Request, recorded []rescore.RecordedScores) (*evaluator, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("list nodes: %w", err)
+	}
+	allPods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("list pods: %w", err)
+	}
+
+	ev := &evaluator{
+		objective:   req.Objective,
+		allocatable: map[string]usage{},
+		baseUsage:   map[string]usage{},
+	}
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		ev.nodes = append(ev.nodes, n.Name)
+		ev.allocatable[n.Name] = usage{cpu: n.Status.Allocatable.Cpu().MilliValue(), memory: n.Status.Allocatable.Memory().Value()}
+	}
+
+	// All parameters are used to find the Pods which can be rescored,
+	// because a Pod without the recorded weight of a parameter cannot be rescored with any weights.
+	probe := map[string]int32{}
+	for _, p := range req.Parameters {
+		probe[p.Plugin] = p.Min
+	}
+	rescored := map[string]bool{}
+	for i := range recorded {
+		r := &recorded[i]
+		if _, err := r.Rescore(probe); err != nil {
+			ev.skipped++
+			continue
+		}
+		key := r.Pod.Namespace + "/" + r.Pod.Name
+		rescored[key] = true
+		ev.pods = append(ev.pods, evalPod{recorded: r, requests: podRequests(r.Pod), expected: req.ExpectedPlacements[key]})
+	}
+
+	for i := range allPods.Items {
+		p := &allPods.Items[i]
+		if p.Spec.NodeName == "" || rescored[p.Namespace+"/"+p.Name] {
+			continue
+		}
+		u := ev.baseUsage[p.Spec.NodeName]
+		u.add(podRequests(p))
+		ev.baseUsage[p.Spec.NodeName] = u
+	}
+	return ev, nil
+}
+
+// evaluate returns the objective when the Pods are placed with the weights.
+// nil weights mean the weights used when the Pods were scheduled.
+func (ev *evaluator) evaluate(weights map[string]int32) float64 {
+	placements := make([]string, len(ev.pods))
+	for i := range ev.pods {
+		p := &ev.pods[i]
+		// The errors are excluded in newEvaluator.
+		scores, _ := p.recorded.Rescore(weights)
+		placements[i] = rescore.HighestScoreNode(scores, p.recorded.CurrentNode)
+	}
+
+	switch ev.objective {
+	case ObjectiveAgreement:
+		return ev.agreement(placements)
+	case ObjectiveBinPacking:
+		return ev.binPacking(placements)
+	case ObjectiveSpread:
+		return ev.spread(placements)
+	}
+	return 0
+}
+
+func (ev *evaluator) agreement(placements []string) float64 {
+	expected, agreed := 0, 0
+	for i, p := range ev.pods {
+		if p.expected == "" {
+			continue
+		}
+		expected++
+		if placements[i] == p.expected {
+			agreed++
+		}
+	}
+	if expected == 0 {
+		return 0
+	}
+	return float64(agreed) / float64(expected)
+}
+
+func (ev *evaluator) binPacking(placements []string) float64 {
+	used := ev.usage(placements)
+	var sum float64
+	occupied := 0
+	for _, node := range ev.nodes {
+		if used[node].pods == 0 {
+			continue
+		}
+		u, ok := ev.utilization(node, used[node])
+		if !ok {
+			continue
+		}
+		sum += u
+		occupied++
+	}
+	if occupied == 0 {
+		return 0
+	}
+	return sum / float64(occupied)
+}
+
+func (ev *evaluator) spread(placements []string) float64 {
+	used := ev.usage(placements)
+	utils := make([]float64, 0, len(ev.nodes))
+	var mean float64
+	for _, node := range ev.nodes {
+		u, ok := ev.utilization(node, used[node])
+		if !ok {
+			continue
+		}
+		utils = append(utils, u)
+		mean += u
+	}
+	if len(utils) == 0 {
+		return 0
+	}
+	mean /= float64(len(utils))
+	var variance float64
+	for _, u := range utils {
+		variance += (u - mean) * (u - mean)
+	}
+	return 1 - math.Sqrt(variance/float64(len(utils)))
+}
+
+// usage returns the resources requested on each Node when the Pods are placed on placements.
+func (ev *evaluator) usage(placements []string) map[string]usage {
+	used := make(map[string]usage, len(ev.nodes))
+	for node, u := range ev.baseUsage {
+		used[node] = u
+	}
+	for i, node := range placements {
+		u := used[node]
+		u.add(ev.pods[i].requests)
+		used[node] = u
+	}
+	return used
+}
+
+// utilization returns the average of the CPU and memory utilization of the Node.
+// The resources which the Node doesn't have are ignored, and it returns false if the Node has neither.
+func (ev *evaluator) utilization(node string, used usage) (float64, bool) {
+	alloc := ev.allocatable[node]
+	var sum float64
+	resources := 0
+	if alloc.cpu > 0 {
+		sum += float64(used.cpu) / float64(alloc.cpu)
+		resources++
+	}
+	if alloc.memory > 0 {
+		sum += float64(used.memory) / float64(alloc.memory)
+		resources++
+	}
+	if resources == 0 {
+		return 0, false
+	}
+	return sum / float64(resources), true
+}
+
+func podRequests(pod *corev1.Pod) usage {
+	u := usage{pods: 1}
+	for _, c := range pod.Spec.Containers {
+		u.cpu += c.Resources.Requests.Cpu().MilliValue()
+		u.memory += c.Resources.Requests.Memory().Value()
+	}
+	return u
+}
@@ -0,0 +1,262 @@
+// Package optimizer searches the weights of score plugins which maximize an objective,
+// based on the scores recorded on Pods by the debuggable scheduler.
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"golang.org/x/xerrors"
+	clientset "k8s.io/client-go/kubernetes"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/rescore"
+)
+
+// ErrInvalidRequest is returned when the request cannot be used to optimize the weights.
+var ErrInvalidRequest = errors.New("invalid optimization request")
+
+const (
+	defaultTrials = 50
+	// maxTrials bounds the number of the weights evaluated in one optimization, including the grid search.
+	maxTrials = 10000
+)
+
+// SchedulerService is the part of scheduler.Service used to build the optimized configuration.
+type SchedulerService interface {
+	GetNamedSchedulerConfig(name string) (*configv1.KubeSchedulerConfiguration, error)
+}
+
+// Service optimizes the weights of score plugins.
+type Service struct {
+	client       clientset.Interface
+	schedService SchedulerService
+}
+
+// Request is the input of Optimize.
+type Request struct {
+	// Scheduler is the name of the scheduler whose configuration is optimized. The default scheduler is used if it's empty.
+	Scheduler string `json:"scheduler,omitempty"`
+	// Profile is the scheduler name of the profile to optimize.
+	// Only the Pods scheduled by the profile are used. The first profile is used if it's empty.
+	Profile string `json:"profile,omitempty"`
+	// Namespace limits the Pods used for the evaluation. All namespaces are used if it's empty.
+	Namespace string    `json:"namespace,omitempty"`
+	Objective Objective `json:"objective"`
+	// ExpectedPlacements are the Nodes where the Pods are placed in the real cluster, keyed by "<namespace>/<name>".
+	// It's required for ObjectiveAgreement.
+	ExpectedPlacements map[string]string `json:"expectedPlacements,omitempty"`
+	Strategy           Strategy          `json:"strategy"`
+	// Parameters are the plugins whose weights are searched. The other plugins keep their weights.
+	Parameters []Parameter `json:"parameters"`
+	// Trials is the number of the weights evaluated by the random and bayesian search. It defaults to 50.
+	// The grid search evaluates all combinations of the parameters instead.
+	Trials int `json:"trials,omitempty"`
+	// Seed makes the random and bayesian search reproducible.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Parameter is the range of the weight of a score plugin.
+type Parameter struct {
+	Plugin string `json:"plugin"`
+	Min    int32  `json:"min"`
+	Max    int32  `json:"max"`
+	// Step is the interval of the weights in the grid search. It defaults to 1.
+	Step int32 `json:"step,omitempty"`
+}
+
+// Trial is the evaluation of a set of weights.
+type Trial struct {
+	Weights map[string]int32 `json:"weights"`
+	Score   float64          `json:"score"`
+}
+
+// Result is the output of Optimize.
+type Result struct {
+	Objective Objective `json:"objective"`
+	Strategy  Strategy  `json:"strategy"`
+	// Baseline is the score with the weights in the current configuration.
+	Baseline float64 `json:"baseline"`
+	// Best is the trial with the highest score. The earliest one wins when trials tie.
+	Best Trial `json:"best"`
+	// Trials are all evaluated trials in the evaluated order.
+	Trials []Trial `json:"trials"`
+	// Pods is the number of the Pods used for the evaluation.
+	Pods int `json:"pods"`
+	// Skipped is the number of the Pods which cannot be rescored and aren't used for the evaluation.
+	Skipped int `json:"skipped"`
+	// Config is the current configuration of the scheduler with the best weights.
+	Config *configv1.KubeSchedulerConfiguration `json:"config"`
+}
+
+// NewService initializes Service.
+func NewService(client clientset.Interface, schedService SchedulerService) *Service {
+	return &Service{client: client, schedService: schedService}
+}
+
+// Optimize searches the weights of the plugins in req.Parameters which maximize req.Objective.
+//
+// Each set of weights is evaluated by rescoring the recorded scores in the same way as the rescore package,
+// so the scheduler isn't restarted during the search.
+// Each Pod is rescored independently, meaning that the Pods placed earlier don't change the scores of the later ones;
+// it's an approximation and it's recommended to confirm the result by applying the configuration and scheduling the Pods again.
+func (s *Service) Optimize(ctx context.Context, req *Request) (*Result, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	schedulerName := req.Scheduler
+	if schedulerName == "" {
+		schedulerName = simulatorconfig.DefaultSchedulerName
+	}
+	cfg, err := s.schedService.GetNamedSchedulerConfig(schedulerName)
+	if err != nil {
+		return nil, xerrors.Errorf("get scheduler config: %w", err)
+	}
+	profileIndex, err := findProfile(cfg, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	profileName := profileSchedulerName(cfg.Profiles[profileIndex])
+
+	recorded, err := rescore.ListRecordedScores(ctx, s.client, req.Namespace, profileName)
+	if err != nil {
+		return nil, xerrors.Errorf("list recorded scores: %w", err)
+	}
+	ev, err := newEvaluator(ctx, s.client, req, recorded)
+	if err != nil {
+		return nil, xerrors.Errorf("initialize evaluator: %w", err)
+	}
+
+	search, err := newSearcher(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Objective: req.Objective,
+		Strategy:  req.Strategy,
+		Baseline:  ev.evaluate(nil),
+		Trials:    []Trial{},
+		Pods:      len(ev.pods),
+		Skipped:   ev.skipped,
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, xerrors.Errorf("optimization is canceled: %w", err)
+		}
+		weights, ok := search.next()
+		if !ok {
+			break
+		}
+		score := ev.evaluate(weights)
+		search.observe(weights, score)
+		result.Trials = append(result.Trials, Trial{Weights: weights, Score: score})
+		if len(result.Trials) == 1 || score > result.Best.Score {
+			result.Best = result.Trials[len(result.Trials)-1]
+		}
+	}
+
+	result.Config = cfg.DeepCopy()
+	setWeights(&result.Config.Profiles[profileIndex], result.Best.Weights)
+	return result, nil
+}
+
+func validateRequest(req *Request) error {
+	switch req.Objective {
+	case ObjectiveBinPacking, ObjectiveSpread:
+	case ObjectiveAgreement:
+		if len(req.ExpectedPlacements) == 0 {
+			return xerrors.Errorf("expectedPlacements is required for the objective %s: %w", req.Objective, ErrInvalidRequest)
+		}
+	default:
+		return xerrors.Errorf("unknown objective %q: %w", req.Objective, ErrInvalidRequest)
+	}
+
+	if len(req.Parameters) == 0 {
+		return xerrors.Errorf("no parameters: %w", ErrInvalidRequest)
+	}
+	seen := map[string]bool{}
+	for _, p := range req.Parameters {
+		if p.Plugin == "" {
+			return xerrors.Errorf("plugin of a parameter is empty: %w", ErrInvalidRequest)
+		}
+		if seen[p.Plugin] {
+			return xerrors.Errorf("parameter of %s is duplicated: %w", p.Plugin, ErrInvalidRequest)
+		}
+		seen[p.Plugin] = true
+		// The weight of score plugins must be positive in KubeSchedulerConfiguration.
+		if p.Min < 1 || p.Max < p.Min {
+			return xerrors.Errorf("the range of %s must satisfy 1 <= min <= max: %w", p.Plugin, ErrInvalidRequest)
+		}
+		if p.Step < 0 {
+			return xerrors.Errorf("the step of %s must not be negative: %w", p.Plugin, ErrInvalidRequest)
+		}
+	}
+
+	if req.Trials < 0 || req.Trials > maxTrials {
+		return xerrors.Errorf("trials must be between 0 and %d: %w", maxTrials, ErrInvalidRequest)
+	}
+	return nil
+}
+
+func findProfile(cfg *configv1.KubeSchedulerConfiguration, name string) (int, error) {
+	if len(cfg.Profiles) == 0 {
+		return 0, xerrors.Errorf("the scheduler has no profiles: %w", ErrInvalidRequest)
+	}
+	if name == "" {
+		return 0, nil
+	}
+	for i, p := range cfg.Profiles {
+		if profileSchedulerName(p) == name {
+			return i, nil
+		}
+	}
+	return 0, xerrors.Errorf("profile %s is not found: %w", name, ErrInvalidRequest)
+}
+
+func profileSchedulerName(p configv1.KubeSchedulerProfile) string {
+	if p.SchedulerName == nil || *p.SchedulerName == "" {
+		return "default-scheduler"
+	}
+	return *p.SchedulerName
+}
+
+// setWeights sets the weights on the plugins enabled in the profile.
+// The weight is set on the entry in the score extension point if any, otherwise on the one at multiPoint.
+// The plugins not explicitly enabled, e.g., the default plugins, are added to multiPoint with the weight,
+// which overrides the default weight.
+func setWeights(profile *configv1.KubeSchedulerProfile, weights map[string]int32) {
+	if len(weights) == 0 {
+		return
+	}
+	if profile.Plugins == nil {
+		profile.Plugins = &configv1.Plugins{}
+	}
+
+	plugins := make([]string, 0, len(weights))
+	for p := range weights {
+		plugins = append(plugins, p)
+	}
+	sort.Strings(plugins)
+
+	for _, name := range plugins {
+		w := weights[name]
+		if setWeight(profile.Plugins.Score.Enabled, name, w) || setWeight(profile.Plugins.MultiPoint.Enabled, name, w) {
+			continue
+		}
+		profile.Plugins.MultiPoint.Enabled = append(profile.Plugins.MultiPoint.Enabled, configv1.Plugin{Name: name, Weight: &w})
+	}
+}
+
+func setWeight(enabled []configv1.Plugin, name string, weight int32) bool {
+	for i := range enabled {
+		if enabled[i].Name == name {
+			w := weight
+			enabled[i].Weight = &w
+			return true
+		}
+	}
+	return false
+}
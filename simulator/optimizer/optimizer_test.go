@@ -0,0 +1,246 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+)
+
+type fakeSchedulerService struct {
+	cfg *configv1.KubeSchedulerConfiguration
+}
+
+func (f *fakeSchedulerService) GetNamedSchedulerConfig(string) (*configv1.KubeSchedulerConfiguration, error) {
+	return f.cfg, nil
+}
+
+func node(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+}
+
+func scoredPod(name, nodeName, finalScore string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Annotations: map[string]string{
+				annotation.SelectedNodeAnnotationKey:      nodeName,
+				annotation.FinalScoreResultAnnotationKey:  finalScore,
+				annotation.ScorePluginWeightAnnotationKey: `{"ImageLocality":1,"NodeResourcesFit":1}`,
+				storereflector.ProfileAnnotation:          "default-scheduler",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestService_Optimize(t *testing.T) {
+	t.Parallel()
+
+	// pod1 moves to node2 when the weight of NodeResourcesFit is 2 or more, while pod2 stays on node2.
+	pod1 := scoredPod("pod1", "node1", `{"node1":{"ImageLocality":"100","NodeResourcesFit":"20"},"node2":{"ImageLocality":"0","NodeResourcesFit":"80"}}`)
+	pod2 := scoredPod("pod2", "node2", `{"node1":{"ImageLocality":"0","NodeResourcesFit":"30"},"node2":{"ImageLocality":"50","NodeResourcesFit":"60"}}`)
+	params := []Parameter{{Plugin: "NodeResourcesFit", Min: 1, Max: 3}}
+
+	tests := []struct {
+		name         string
+		req          *Request
+		wantBaseline float64
+		wantBest     Trial
+		wantTrials   int
+	}{
+		{
+			name:         "binPacking prefers the weight packing the Pods into node2",
+			req:          &Request{Objective: ObjectiveBinPacking, Strategy: StrategyGrid, Parameters: params},
+			wantBaseline: 0.25,
+			wantBest:     Trial{Weights: map[string]int32{"NodeResourcesFit": 2}, Score: 0.5},
+			wantTrials:   3,
+		},
+		{
+			name:         "spread prefers the weight keeping the Pods on different Nodes",
+			req:          &Request{Objective: ObjectiveSpread, Strategy: StrategyGrid, Parameters: params},
+			wantBaseline: 1,
+			wantBest:     Trial{Weights: map[string]int32{"NodeResourcesFit": 1}, Score: 1},
+			wantTrials:   3,
+		},
+		{
+			name: "agreement prefers the weight reproducing the expected placements",
+			req: &Request{
+				Objective:          ObjectiveAgreement,
+				ExpectedPlacements: map[string]string{"default/pod1": "node2", "default/pod2": "node2"},
+				Strategy:           StrategyGrid,
+				Parameters:         params,
+			},
+			wantBaseline: 0.5,
+			wantBest:     Trial{Weights: map[string]int32{"NodeResourcesFit": 2}, Score: 1},
+			wantTrials:   3,
+		},
+		{
+			name:         "random search evaluates the given number of trials",
+			req:          &Request{Objective: ObjectiveBinPacking, Strategy: StrategyRandom, Parameters: []Parameter{{Plugin: "NodeResourcesFit", Min: 2, Max: 3}}, Trials: 5},
+			wantBaseline: 0.25,
+			wantTrials:   5,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := fake.NewSimpleClientset(node("node1"), node("node2"), pod1.DeepCopy(), pod2.DeepCopy())
+			cfg := &configv1.KubeSchedulerConfiguration{Profiles: []configv1.KubeSchedulerProfile{{SchedulerName: ptr.To("default-scheduler")}}}
+			s := NewService(client, &fakeSchedulerService{cfg: cfg})
+
+			got, err := s.Optimize(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, 2, got.Pods)
+			assert.InDelta(t, tt.wantBaseline, got.Baseline, 1e-9)
+			assert.Len(t, got.Trials, tt.wantTrials)
+			if tt.wantBest.Weights != nil {
+				assert.Equal(t, tt.wantBest.Weights, got.Best.Weights)
+				assert.InDelta(t, tt.wantBest.Score, got.Best.Score, 1e-9)
+			}
+			// The best weights are added to multiPoint to override the default weight.
+			assert.Equal(t, []configv1.Plugin{{Name: "NodeResourcesFit", Weight: ptr.To(got.Best.Weights["NodeResourcesFit"])}}, got.Config.Profiles[0].Plugins.MultiPoint.Enabled)
+			// The current configuration isn't modified.
+			assert.Nil(t, cfg.Profiles[0].Plugins)
+		})
+	}
+}
+
+func TestService_Optimize_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	params := []Parameter{{Plugin: "NodeResourcesFit", Min: 1, Max: 3}}
+	tests := []struct {
+		name string
+		req  *Request
+	}{
+		{
+			name: "unknown objective",
+			req:  &Request{Objective: "unknown", Strategy: StrategyGrid, Parameters: params},
+		},
+		{
+			name: "agreement without expected placements",
+			req:  &Request{Objective: ObjectiveAgreement, Strategy: StrategyGrid, Parameters: params},
+		},
+		{
+			name: "unknown strategy",
+			req:  &Request{Objective: ObjectiveSpread, Strategy: "unknown", Parameters: params},
+		},
+		{
+			name: "weight range contains 0",
+			req:  &Request{Objective: ObjectiveSpread, Strategy: StrategyGrid, Parameters: []Parameter{{Plugin: "NodeResourcesFit", Min: 0, Max: 3}}},
+		},
+		{
+			name: "too large grid",
+			req: &Request{Objective: ObjectiveSpread, Strategy: StrategyGrid, Parameters: []Parameter{
+				{Plugin: "NodeResourcesFit", Min: 1, Max: 1000},
+				{Plugin: "ImageLocality", Min: 1, Max: 1000},
+			}},
+		},
+		{
+			name: "unknown profile",
+			req:  &Request{Objective: ObjectiveSpread, Strategy: StrategyGrid, Parameters: params, Profile: "unknown"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &configv1.KubeSchedulerConfiguration{Profiles: []configv1.KubeSchedulerProfile{{SchedulerName: ptr.To("default-scheduler")}}}
+			s := NewService(fake.NewSimpleClientset(), &fakeSchedulerService{cfg: cfg})
+			_, err := s.Optimize(context.Background(), tt.req)
+			assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestGridSearcher(t *testing.T) {
+	t.Parallel()
+
+	s, err := newGridSearcher([]Parameter{
+		{Plugin: "a", Min: 1, Max: 2},
+		{Plugin: "b", Min: 1, Max: 5, Step: 2},
+	})
+	require.NoError(t, err)
+
+	got := []map[string]int32{}
+	for {
+		w, ok := s.next()
+		if !ok {
+			break
+		}
+		got = append(got, w)
+	}
+	assert.Equal(t, []map[string]int32{
+		{"a": 1, "b": 1}, {"a": 1, "b": 3}, {"a": 1, "b": 5},
+		{"a": 2, "b": 1}, {"a": 2, "b": 3}, {"a": 2, "b": 5},
+	}, got)
+}
+
+func TestTPESearcher(t *testing.T) {
+	t.Parallel()
+
+	params := []Parameter{{Plugin: "a", Min: 1, Max: 100}}
+	s := newTPESearcher(params, 60, 1)
+	// The score is the highest at 80.
+	objective := func(w map[string]int32) float64 {
+		d := float64(w["a"] - 80)
+		return -d * d
+	}
+
+	var suggested []int32
+	for {
+		w, ok := s.next()
+		if !ok {
+			break
+		}
+		require.GreaterOrEqual(t, w["a"], int32(1))
+		require.LessOrEqual(t, w["a"], int32(100))
+		s.observe(w, objective(w))
+		if len(s.observed) > s.startup {
+			suggested = append(suggested, w["a"])
+		}
+	}
+	require.Len(t, s.observed, 60)
+
+	// Most of the suggested weights should be near the best one.
+	near := 0
+	for _, w := range suggested {
+		if w >= 60 && w <= 100 {
+			near++
+		}
+	}
+	assert.Greater(t, near, len(suggested)/2, "suggested: %v", suggested)
+}
@@ -0,0 +1,233 @@
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// Strategy is how the weights are searched.
+type Strategy string
+
+const (
+	// StrategyGrid evaluates all combinations of the weights from min to max by step.
+	StrategyGrid Strategy = "grid"
+	// StrategyRandom evaluates the weights drawn uniformly from the ranges.
+	StrategyRandom Strategy = "random"
+	// StrategyBayesian evaluates the weights suggested by the tree-structured Parzen estimator (TPE)
+	// built from the previous trials, after evaluating some random weights.
+	StrategyBayesian Strategy = "bayesian"
+)
+
+// searcher suggests the weights to evaluate next.
+type searcher interface {
+	// next returns the weights to evaluate next. It returns false when the search is done.
+	next() (map[string]int32, bool)
+	// observe receives the score of the weights returned by next.
+	observe(weights map[string]int32, score float64)
+}
+
+func newSearcher(req *Request) (searcher, error) {
+	trials := req.Trials
+	if trials == 0 {
+		trials = defaultTrials
+	}
+	switch req.Strategy {
+	case StrategyGrid:
+		return newGridSearcher(req.Parameters)
+	case StrategyRandom:
+		return &randomSearcher{params: req.Parameters, trials: trials, rand: newRand(req.Seed)}, nil
+	case StrategyBayesian:
+		return newTPESearcher(req.Parameters, trials, req.Seed), nil
+	}
+	return nil, xerrors.Errorf("unknown strategy %q: %w", req.Strategy, ErrInvalidRequest)
+}
+
+// gridSearcher enumerates the combinations like an odometer; the last parameter changes fastest.
+type gridSearcher struct {
+	params []Parameter
+	// values are the candidate weights of each parameter.
+	values [][]int32
+	// indexes are the indexes in values of the next weights. It's nil when all combinations are returned.
+	indexes []int
+}
+
+func newGridSearcher(params []Parameter) (*gridSearcher, error) {
+	s := &gridSearcher{params: params, indexes: make([]int, len(params))}
+	total := 1
+	for _, p := range params {
+		step := p.Step
+		if step == 0 {
+			step = 1
+		}
+		values := []int32{}
+		for w := p.Min; w <= p.Max; w += step {
+			values = append(values, w)
+			if w > math.MaxInt32-step {
+				break
+			}
+		}
+		total *= len(values)
+		if total > maxTrials {
+			return nil, xerrors.Errorf("the grid has more than %d combinations, use a larger step or another strategy: %w", maxTrials, ErrInvalidRequest)
+		}
+		s.values = append(s.values, values)
+	}
+	return s, nil
+}
+
+func (s *gridSearcher) next() (map[string]int32, bool) {
+	if s.indexes == nil {
+		return nil, false
+	}
+	weights := make(map[string]int32, len(s.params))
+	for i, p := range s.params {
+		weights[p.Plugin] = s.values[i][s.indexes[i]]
+	}
+
+	// Advance the indexes.
+	for i := len(s.indexes) - 1; ; i-- {
+		if i < 0 {
+			s.indexes = nil
+			break
+		}
+		s.indexes[i]++
+		if s.indexes[i] < len(s.values[i]) {
+			break
+		}
+		s.indexes[i] = 0
+	}
+	return weights, true
+}
+
+func (s *gridSearcher) observe(map[string]int32, float64) {}
+
+type randomSearcher struct {
+	params []Parameter
+	trials int
+	done   int
+	rand   *rand.Rand
+}
+
+func (s *randomSearcher) next() (map[string]int32, bool) {
+	if s.done >= s.trials {
+		return nil, false
+	}
+	s.done++
+	return randomWeights(s.params, s.rand), true
+}
+
+func (s *randomSearcher) observe(map[string]int32, float64) {}
+
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed)) //nolint:gosec // The search doesn't need a secure random number.
+}
+
+func randomWeights(params []Parameter, r *rand.Rand) map[string]int32 {
+	weights := make(map[string]int32, len(params))
+	for _, p := range params {
+		weights[p.Plugin] = p.Min + int32(r.Int63n(int64(p.Max-p.Min)+1))
+	}
+	return weights
+}
+
+const (
+	// tpeGamma is the ratio of the trials regarded as good ones.
+	tpeGamma = 0.25
+	// tpeCandidates is the number of the candidates drawn from the good trials in each suggestion.
+	tpeCandidates = 24
+	// tpeMinStartupTrials is the minimum number of the random trials before the suggestion starts.
+	tpeMinStartupTrials = 10
+)
+
+// tpeSearcher is a simple tree-structured Parzen estimator.
+// It splits the trials into good ones and bad ones by the score, models each parameter of them with Gaussian kernels,
+// and suggests the candidate drawn from the good model which maximizes the ratio l(x)/g(x) of the densities of the good and bad models.
+// The parameters are modeled independently.
+type tpeSearcher struct {
+	params   []Parameter
+	trials   int
+	startup  int
+	rand     *rand.Rand
+	observed []Trial
+	done     int
+}
+
+func newTPESearcher(params []Parameter, trials int, seed int64) *tpeSearcher {
+	startup := trials / 5
+	if startup < tpeMinStartupTrials {
+		startup = tpeMinStartupTrials
+	}
+	return &tpeSearcher{
+		params:  params,
+		trials:  trials,
+		startup: startup,
+		rand:    newRand(seed),
+	}
+}
+
+func (s *tpeSearcher) next() (map[string]int32, bool) {
+	if s.done >= s.trials {
+		return nil, false
+	}
+	s.done++
+	if len(s.observed) < s.startup {
+		return randomWeights(s.params, s.rand), true
+	}
+	return s.suggest(), true
+}
+
+func (s *tpeSearcher) observe(weights map[string]int32, score float64) {
+	s.observed = append(s.observed, Trial{Weights: weights, Score: score})
+}
+
+func (s *tpeSearcher) suggest() map[string]int32 {
+	sorted := make([]Trial, len(s.observed))
+	copy(sorted, s.observed)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+	nGood := int(math.Ceil(tpeGamma * float64(len(sorted))))
+	good, bad := sorted[:nGood], sorted[nGood:]
+
+	weights := make(map[string]int32, len(s.params))
+	for _, p := range s.params {
+		goodValues, badValues := weightsOf(good, p.Plugin), weightsOf(bad, p.Plugin)
+		bandwidth := math.Max(1, float64(p.Max-p.Min)/5)
+
+		best, bestRatio := p.Min, math.Inf(-1)
+		for i := 0; i < tpeCandidates; i++ {
+			center := goodValues[s.rand.Intn(len(goodValues))]
+			c := clamp(math.Round(center+s.rand.NormFloat64()*bandwidth), p)
+			ratio := math.Log(parzen(c, goodValues, bandwidth, p)) - math.Log(parzen(c, badValues, bandwidth, p))
+			if ratio > bestRatio {
+				best, bestRatio = int32(c), ratio
+			}
+		}
+		weights[p.Plugin] = best
+	}
+	return weights
+}
+
+func weightsOf(trials []Trial, plugin string) []float64 {
+	ret := make([]float64, 0, len(trials))
+	for _, t := range trials {
+		ret = append(ret, float64(t.Weights[plugin]))
+	}
+	return ret
+}
+
+// parzen returns the density at x of the mixture of the Gaussian kernels on the values and the uniform prior on the range.
+func parzen(x float64, values []float64, bandwidth float64, p Parameter) float64 {
+	prior := 1 / float64(p.Max-p.Min+1)
+	sum := prior
+	for _, v := range values {
+		d := (x - v) / bandwidth
+		sum += math.Exp(-d*d/2) / (bandwidth * math.Sqrt(2*math.Pi))
+	}
+	return sum / float64(len(values)+1)
+}
+
+func clamp(v float64, p Parameter) float64 {
+	return math.Min(math.Max(v, float64(p.Min)), float64(p.Max))
+}
@@ -1,14 +1,28 @@
 package debuggablescheduler
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/xerrors"
+	"k8s.io/apiserver/pkg/server"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/featuregate"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	"k8s.io/component-base/version/verflag"
 	"k8s.io/kubernetes/cmd/kube-scheduler/app"
+	schedulerappoptions "k8s.io/kubernetes/cmd/kube-scheduler/app/options"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
 	simulatorschedulerconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/cyclestate"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/debugextender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 )
 
 func NewSchedulerCommand(opts ...Option) (*cobra.Command, func(), error) {
@@ -25,20 +39,36 @@ func NewSchedulerCommand(opts ...Option) (*cobra.Command, func(), error) {
 	if err != nil {
 		return nil, nil, xerrors.Errorf("failed to NewConfigs(): %w", err)
 	}
+	if opt.recordCycleState && configs.cycleState == nil {
+		configs.cycleState = cyclestate.InTreeRegistry()
+	}
+	if configs.cycleState != nil {
+		for _, cs := range opt.cycleStateSerializers {
+			configs.cycleState.Register(cs.pluginName, cs.key, cs.serializer)
+		}
+	}
 
 	// Extender service must be initialized using `KubeSchedulerConfiguration.Extenders` config which is not override for simulator (before calling OverrideExtendersCfgToSimulator()).
 	// The override will be do within CreateOptions().
-	extenderService, err := extender.New(configs.clientSet, configs.versioned.Extenders, configs.sharedStore)
+	extenderService, err := extender.New(configs.clientSet, configs.versioned.Extenders, configs.sharedStore, extender.WithMockExtenders(opt.mockExtenders), extender.WithExtenderFaults(opt.extenderFaults))
 	if err != nil {
 		return nil, nil, xerrors.Errorf("failed to New Extender service: %w", err)
 	}
 
-	schedulerOpts, cancelFn, err := CreateOptions(configs, opt.pluginExtender)
+	// The ones in the simulator config run after the ones given by WithDebugPluginExtenders.
+	debugExtenders, err := debugextender.New(append(opt.debugExtenders, configs.pluginExtenders...))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create debugging plugin extenders: %w", err)
+	}
+
+	schedulerOpts, cancelFn, err := CreateOptions(configs, debugextender.Merge(opt.pluginExtender, debugExtenders))
 	if err != nil {
 		return nil, cancelFn, err
 	}
 	// Launch the proxy HTTP server for Extender, which is used to store the Extender's results.
-	s := NewExtenderServer(extenderService)
+	// The scheduling queue is served there as well after the scheduler is set up.
+	inspector := queue.NewInspector()
+	s := NewExtenderServer(extenderService, inspector, configs.breakpoints)
 	shutdownFn, err := s.Start(configs.port)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("start extender proxy server: %w", err)
@@ -48,14 +78,94 @@ func NewSchedulerCommand(opts ...Option) (*cobra.Command, func(), error) {
 		cancelFn()
 		shutdownFn()
 	}
-	command := app.NewSchedulerCommand(schedulerOpts...)
+	command, err := newSchedulerCommand(inspector, schedulerOpts...)
+	if err != nil {
+		return nil, cancel, xerrors.Errorf("create scheduler command: %w", err)
+	}
 
 	return command, cancel, nil
 }
 
+// newSchedulerCommand creates the upstream scheduler command, which sets up the scheduler with app.Setup and runs it with app.Run,
+// so that the inspector looks into the scheduling queue of the scheduler.
+// The upstream command doesn't give us the scheduler it creates, so its flags are bound to the options given to app.Setup here.
+func newSchedulerCommand(inspector *queue.Inspector, registryOptions ...app.Option) (*cobra.Command, error) {
+	command := app.NewSchedulerCommand(registryOptions...)
+	opts := schedulerappoptions.NewOptions()
+	if err := bindFlags(command, opts); err != nil {
+		return nil, err
+	}
+
+	// The same as the RunE of the upstream command, except that the inspector is set before the scheduler runs.
+	command.RunE = func(cmd *cobra.Command, _ []string) error {
+		verflag.PrintAndExitIfRequested()
+		fg := opts.ComponentGlobalsRegistry.FeatureGateFor(featuregate.DefaultKubeComponent)
+		if err := logsapi.ValidateAndApply(opts.Logs, fg); err != nil {
+			return xerrors.Errorf("apply logging configuration: %w", err)
+		}
+		cliflag.PrintFlags(cmd.Flags())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-server.SetupSignalHandler()
+			cancel()
+		}()
+
+		cc, sched, err := app.Setup(ctx, opts, registryOptions...)
+		if err != nil {
+			return xerrors.Errorf("set up scheduler: %w", err)
+		}
+		if sched.SchedulingQueue == nil {
+			return xerrors.New("the scheduler has no scheduling queue to look into")
+		}
+		inspector.SetScheduler(sched, cc.ComponentConfig.PodInitialBackoffSeconds, cc.ComponentConfig.PodMaxBackoffSeconds)
+
+		if mfg, ok := fg.(featuregate.MutableFeatureGate); ok {
+			mfg.AddMetrics()
+		}
+		return app.Run(ctx, cc, sched)
+	}
+	return command, nil
+}
+
+// bindFlags makes the flags of the command set opts instead of the options held by the command.
+// The flags in opts are replaced with the ones of the command, so that opts tells which flags are set on the command line.
+func bindFlags(command *cobra.Command, opts *schedulerappoptions.Options) error {
+	for name, fs := range opts.Flags.FlagSets {
+		bound := pflag.NewFlagSet(name, pflag.ContinueOnError)
+		var missing []string
+		fs.VisitAll(func(f *pflag.Flag) {
+			cf := command.Flags().Lookup(f.Name)
+			if cf == nil {
+				missing = append(missing, f.Name)
+				return
+			}
+			cf.Value = f.Value
+			bound.AddFlag(cf)
+		})
+		if len(missing) > 0 {
+			return xerrors.Errorf("flags %v aren't defined in the upstream scheduler command", missing)
+		}
+		opts.Flags.FlagSets[name] = bound
+	}
+	return nil
+}
+
 type options struct {
-	outOfTreeRegistry runtime.Registry
-	pluginExtender    map[string]plugin.PluginExtenderInitializer
+	outOfTreeRegistry     runtime.Registry
+	pluginExtender        map[string]plugin.PluginExtenderInitializer
+	recordCycleState      bool
+	cycleStateSerializers []cycleStateSerializer
+	mockExtenders         []v1alpha1.MockExtender
+	extenderFaults        []v1alpha1.ExtenderFault
+	debugExtenders        []v1alpha1.PluginExtender
+}
+
+type cycleStateSerializer struct {
+	pluginName string
+	key        framework.StateKey
+	serializer cyclestate.Serializer
 }
 
 type Option func(opt *options)
@@ -73,3 +183,47 @@ func WithPluginExtenders(pluginName string, e plugin.PluginExtenderInitializer)
 		opt.pluginExtender[pluginName] = e
 	}
 }
+
+// WithCycleStateRecording makes the wrapped plugins record the CycleState entries written in PreFilter and PreScore.
+// The entries of the in-tree plugins and the ones registered by WithCycleStateSerializer are recorded.
+// It's the same as setting RECORD_CYCLE_STATE to true.
+func WithCycleStateRecording() Option {
+	return func(opt *options) {
+		opt.recordCycleState = true
+	}
+}
+
+// WithCycleStateSerializer registers the serializer for the CycleState entry with the key written by the plugin,
+// e.g., the state of your out-of-tree plugin.
+// It's used only when the CycleState is recorded.
+func WithCycleStateSerializer(pluginName string, key framework.StateKey, s cyclestate.Serializer) Option {
+	return func(opt *options) {
+		opt.cycleStateSerializers = append(opt.cycleStateSerializers, cycleStateSerializer{pluginName: pluginName, key: key, serializer: s})
+	}
+}
+
+// WithMockExtenders makes the extender proxy answer the requests for the extenders with the URL prefixes of mocks by their rules,
+// instead of sending them to the extender servers.
+// It's the same as mockExtenders in the simulator config for the scheduler running in the simulator process.
+func WithMockExtenders(mocks ...v1alpha1.MockExtender) Option {
+	return func(opt *options) {
+		opt.mockExtenders = append(opt.mockExtenders, mocks...)
+	}
+}
+
+// WithExtenderFaults makes the extender proxy inject the faults into the responses for the extenders.
+// It's the same as extenderFaults in the simulator config for the scheduler running in the simulator process.
+func WithExtenderFaults(faults ...v1alpha1.ExtenderFault) Option {
+	return func(opt *options) {
+		opt.extenderFaults = append(opt.extenderFaults, faults...)
+	}
+}
+
+// WithDebugPluginExtenders attaches the debugging extenders from the built-in library to the plugins.
+// They run after the ones given by WithPluginExtenders for the same plugin.
+// It's the same as pluginExtenders in the simulator config, which the scheduler reads when SIMULATOR_CONFIG_PATH is set.
+func WithDebugPluginExtenders(extenders ...v1alpha1.PluginExtender) Option {
+	return func(opt *options) {
+		opt.debugExtenders = append(opt.debugExtenders, extenders...)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"strconv"
 
 	"golang.org/x/xerrors"
 	clientset "k8s.io/client-go/kubernetes"
@@ -21,10 +22,13 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 
 	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	simulatorv1alpha1 "sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
 	simulatorschedulerconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/cyclestate"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
 )
 
@@ -34,6 +38,13 @@ type Configs struct {
 	clientSet   *clientset.Clientset
 	sharedStore storereflector.Reflector
 	port        int
+	// breakpoints are served on the proxy server, and checked by the wrapped plugins.
+	breakpoints *breakpoint.Manager
+	// cycleState serializes the CycleState entries recorded by the wrapped plugins.
+	// It's nil when the CycleState isn't recorded.
+	cycleState *cyclestate.Registry
+	// pluginExtenders are the debugging extenders read from the simulator config file at SIMULATOR_CONFIG_PATH.
+	pluginExtenders []simulatorv1alpha1.PluginExtender
 }
 
 // NewConfigs loads flags and initializes kube scheduler configuration and clientSet.
@@ -42,7 +53,9 @@ type Configs struct {
 // - reads the scheduling config passed from users (or use the default config).
 // - converts it for enabling wrapped plugins.
 // - reads the kubeConfig and creates clientSet to enables storereflector to communicates with the api-server.
-// - initialize the store reflector.
+// - initialize the store reflector, which tags the results with the fingerprint of the config.
+// - enables recording the CycleState when RECORD_CYCLE_STATE is true.
+// - reads pluginExtenders from the simulator config file at SIMULATOR_CONFIG_PATH if it's set.
 func NewConfigs() (Configs, error) {
 	// flags defined in the upstream scheduler
 	configFile := flag.String("config", "", "")
@@ -56,12 +69,21 @@ func NewConfigs() (Configs, error) {
 	if err != nil {
 		return Configs{}, xerrors.Errorf("load scheduler config: %w", err)
 	}
+	// The fingerprint is taken from the file as it is so that it matches the one the simulator has.
+	fingerprint, err := simulatorschedulerconfig.FileFingerprint(*configFile)
+	if err != nil {
+		return Configs{}, xerrors.Errorf("get fingerprint of scheduler config: %w", err)
+	}
 
-	// Register wasm plugins to the wasm registry.
+	// Register wasm plugins and bridge plugins to the out-of-tree registry.
 	// This _needs_ to happen before the scheduler configuration is converted.
-	if err := simulatorschedulerconfig.RegisterWasmPlugins(versionedcfg); err != nil {
+	// The process runs only this scheduler.
+	if err := simulatorschedulerconfig.RegisterWasmPlugins("", versionedcfg); err != nil {
 		return Configs{}, xerrors.Errorf("register wasm plugins: %w", err)
 	}
+	if err := simulatorschedulerconfig.RegisterBridgePlugins("", versionedcfg); err != nil {
+		return Configs{}, xerrors.Errorf("register bridge plugins: %w", err)
+	}
 
 	versioned, err := scheduler.ConvertConfigurationForSimulator(versionedcfg)
 	if err != nil {
@@ -78,12 +100,26 @@ func NewConfigs() (Configs, error) {
 		return Configs{}, xerrors.Errorf("load kubeconfig: %w", err)
 	}
 
+	var cycleState *cyclestate.Registry
+	// The env is used instead of a flag because the flags are parsed by the upstream scheduler command as well.
+	if recordCycleState, _ := strconv.ParseBool(os.Getenv("RECORD_CYCLE_STATE")); recordCycleState {
+		cycleState = cyclestate.InTreeRegistry()
+	}
+
+	pluginExtenders, err := simulatorconfig.LoadPluginExtenders(os.Getenv("SIMULATOR_CONFIG_PATH"))
+	if err != nil {
+		return Configs{}, xerrors.Errorf("load plugin extenders from simulator config: %w", err)
+	}
+
 	return Configs{
-		versioned:   versioned,
-		internalCfg: internalCfg,
-		clientSet:   clientSet,
-		sharedStore: storereflector.New(),
-		port:        *port,
+		versioned:       versioned,
+		internalCfg:     internalCfg,
+		clientSet:       clientSet,
+		sharedStore:     storereflector.New(storereflector.WithConfigFingerprint(fingerprint)),
+		port:            *port,
+		breakpoints:     breakpoint.NewManager(),
+		cycleState:      cycleState,
+		pluginExtenders: pluginExtenders,
 	}, nil
 }
 
@@ -95,7 +131,11 @@ func CreateOptions(configs Configs, pluginExtender map[string]plugin.PluginExten
 	// Override the Extenders config so that the connection is directed to the simulator server.
 	extender.OverrideExtendersCfgToSimulator(configs.versioned, configs.port)
 
-	opts, err := CreateOptionForPlugin(pluginExtender, configs.sharedStore, configs.internalCfg)
+	wrapOpts := []plugin.Option{plugin.WithBreakpointsOption(configs.breakpoints)}
+	if configs.cycleState != nil {
+		wrapOpts = append(wrapOpts, plugin.WithCycleStateOption(configs.cycleState))
+	}
+	opts, err := CreateOptionForPlugin(pluginExtender, configs.sharedStore, configs.internalCfg, wrapOpts...)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("CreateOptionForPlugin: %w", err)
 	}
@@ -125,9 +165,10 @@ func CreateOptions(configs Configs, pluginExtender map[string]plugin.PluginExten
 
 // CreateOptionForPlugin creates Option for in/out of tree plugins.
 // It does create the wrapped plugin registries and return the registries as app.Option.
-func CreateOptionForPlugin(pluginExtender map[string]plugin.PluginExtenderInitializer, sharedStore storereflector.Reflector, internalCfg *config.KubeSchedulerConfiguration) ([]app.Option, error) {
+// wrapOpts are applied to all wrapped plugins.
+func CreateOptionForPlugin(pluginExtender map[string]plugin.PluginExtenderInitializer, sharedStore storereflector.Reflector, internalCfg *config.KubeSchedulerConfiguration, wrapOpts ...plugin.Option) ([]app.Option, error) {
 	// loads in/out of tree plugins and wraps it for debuggable.
-	registry, err := plugin.NewRegistry(sharedStore, internalCfg, pluginExtender)
+	registry, err := plugin.NewRegistry(sharedStore, internalCfg, pluginExtender, wrapOpts...)
 	if err != nil {
 		return nil, xerrors.Errorf("convert scheduler config to apply: %w", err)
 	}
@@ -11,7 +11,9 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/handler"
 )
@@ -23,14 +25,22 @@ type ExtenderServer struct {
 
 // NewExtenderServer initialize ExtenderServer.
 // This server is used as a proxy server to store Extender results.
-func NewExtenderServer(service *extender.Service) ExtenderServer {
+// It also serves the scheduling queue of the scheduler via the inspector, the breakpoints
+// and the calls to the extenders to the simulator server.
+func NewExtenderServer(service *extender.Service, inspector *queue.Inspector, breakpoints *breakpoint.Manager) ExtenderServer {
 	e := echo.New()
 	e.Use(middleware.Logger())
 
 	extenderHandler := handler.NewExtenderHandler(service)
+	extenderCallHandler := handler.NewExtenderCallHandler(service)
+	queueHandler := handler.NewSchedulingQueueHandler(inspector)
+	breakpointHandler := handler.NewBreakpointHandler(breakpointService{manager: breakpoints})
 	// register apis
 	v1 := e.Group("/api/v1")
 	server.RouteExtender(v1, extenderHandler)
+	server.RouteExtenderCalls(v1, extenderCallHandler)
+	server.RouteSchedulingQueue(v1, queueHandler)
+	server.RouteBreakpoints(v1, breakpointHandler)
 	s := ExtenderServer{e: e}
 	s.e.Logger.SetLevel(log.INFO)
 	return s
@@ -58,3 +68,12 @@ func (s *ExtenderServer) Start(port int) (
 
 	return shutdownFn, nil
 }
+
+// breakpointService serves the breakpoints of the scheduler in this process regardless of the scheduler name.
+type breakpointService struct {
+	manager *breakpoint.Manager
+}
+
+func (s breakpointService) Debugger(string) (breakpoint.Debugger, error) {
+	return s.manager, nil
+}
@@ -73,7 +73,11 @@ func CreateOptionForOutOfTreePlugin(outOfTreePluginRegistry runtime.Registry, pl
 		return nil, nil, xerrors.Errorf("convert scheduler config to internal one: %w", err)
 	}
 
-	sharedStore := storereflector.New()
+	fingerprint, err := simulatorschedulerconfig.FileFingerprint(*configFile)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("get fingerprint of scheduler config: %w", err)
+	}
+	sharedStore := storereflector.New(storereflector.WithConfigFingerprint(fingerprint))
 
 	registry, err := plugin.NewRegistry(sharedStore, internalCfg, pluginExtender)
 	if err != nil {
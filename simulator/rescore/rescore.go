@@ -0,0 +1,228 @@
+// Package rescore recalculates the final scores recorded on Pods with other score plugin weights
+// to see which Pods would have been placed on other Nodes, without restarting the scheduler.
+package rescore
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+)
+
+// Service recalculates the scores recorded on Pods by the debuggable scheduler.
+type Service struct {
+	client clientset.Interface
+}
+
+// Request is the input of Rescore.
+type Request struct {
+	// Weights are the new weights of the score plugins.
+	// The plugins not in Weights keep the weights used when the Pods were scheduled.
+	// The weight 0 disables the plugin.
+	Weights map[string]int32 `json:"weights"`
+	// Namespace limits the Pods to rescore. All namespaces are used if it's empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Profile limits the Pods to the ones scheduled by the profile with the name.
+	Profile string `json:"profile,omitempty"`
+}
+
+// Report is the result of Rescore.
+type Report struct {
+	// Moved is the number of Pods which would have landed on another Node.
+	Moved int `json:"moved"`
+	// Skipped is the number of Pods which cannot be rescored.
+	Skipped int         `json:"skipped"`
+	Pods    []PodResult `json:"pods"`
+}
+
+// PodResult is the rescored result of a Pod.
+type PodResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// CurrentNode is the Node selected by the scheduler.
+	CurrentNode string `json:"currentNode"`
+	// NewNode is the Node with the highest score with the new weights.
+	// When multiple Nodes have the highest score, CurrentNode is preferred because the scheduler picks one of them randomly.
+	NewNode string `json:"newNode,omitempty"`
+	Moved   bool   `json:"moved"`
+	// Scores are the total scores of the Nodes with the new weights.
+	Scores map[string]int64 `json:"scores,omitempty"`
+	// SkippedReason is set when the Pod cannot be rescored.
+	SkippedReason string `json:"skippedReason,omitempty"`
+}
+
+// NewService initializes Service.
+func NewService(client clientset.Interface) *Service {
+	return &Service{client: client}
+}
+
+// Rescore recalculates the final scores of the scheduled Pods with the weights in req.
+// The normalized scores are derived from the recorded final scores and weights,
+// so the Pods are rescored without running the plugins again.
+// Note that the scores from the extenders aren't taken into account, as they aren't recorded in the final scores.
+func (s *Service) Rescore(ctx context.Context, req *Request) (*Report, error) {
+	recorded, err := ListRecordedScores(ctx, s.client, req.Namespace, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Pods: []PodResult{}}
+	for i := range recorded {
+		r := recorded[i].result(req.Weights)
+		if r.SkippedReason != "" {
+			report.Skipped++
+		}
+		if r.Moved {
+			report.Moved++
+		}
+		report.Pods = append(report.Pods, r)
+	}
+	return report, nil
+}
+
+// RecordedScores has the scores recorded on a Pod by the debuggable scheduler.
+type RecordedScores struct {
+	Pod *corev1.Pod
+	// CurrentNode is the Node selected by the scheduler.
+	CurrentNode string
+	// FinalScores are the final scores of each plugin on each Node. (node name → plugin name → score)
+	FinalScores map[string]map[string]int64
+	// Weights are the weights of the plugins used to calculate FinalScores.
+	Weights map[string]int32
+	// InvalidReason is set when the scores cannot be used to rescore the Pod.
+	InvalidReason string
+}
+
+// ListRecordedScores returns the scores recorded on the Pods in the namespace scheduled by the profile.
+// All namespaces and profiles are used if they're empty.
+// The Pods which haven't been scheduled by the debuggable scheduler are omitted.
+func ListRecordedScores(ctx context.Context, client clientset.Interface, namespace, profile string) ([]RecordedScores, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("list pods: %w", err)
+	}
+
+	ret := []RecordedScores{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		finalScore, ok := pod.Annotations[annotation.FinalScoreResultAnnotationKey]
+		if !ok {
+			continue
+		}
+		if profile != "" && pod.Annotations[storereflector.ProfileAnnotation] != profile {
+			continue
+		}
+		ret = append(ret, parseRecordedScores(pod, finalScore))
+	}
+	return ret, nil
+}
+
+func parseRecordedScores(pod *corev1.Pod, finalScoreAnnotation string) RecordedScores {
+	r := RecordedScores{Pod: pod, CurrentNode: pod.Annotations[annotation.SelectedNodeAnnotationKey]}
+	if r.CurrentNode == "" {
+		r.CurrentNode = pod.Spec.NodeName
+	}
+
+	finalScores := map[string]map[string]string{}
+	if err := json.Unmarshal([]byte(finalScoreAnnotation), &finalScores); err != nil {
+		r.InvalidReason = "cannot parse " + annotation.FinalScoreResultAnnotationKey + ": " + err.Error()
+		return r
+	}
+	if len(finalScores) == 0 {
+		// The scheduler skips scoring when only one Node passes the filters.
+		r.InvalidReason = "the Pod wasn't scored"
+		return r
+	}
+	r.Weights = map[string]int32{}
+	if a, ok := pod.Annotations[annotation.ScorePluginWeightAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(a), &r.Weights); err != nil {
+			r.InvalidReason = "cannot parse " + annotation.ScorePluginWeightAnnotationKey + ": " + err.Error()
+			return r
+		}
+	}
+
+	r.FinalScores = map[string]map[string]int64{}
+	for node, scores := range finalScores {
+		r.FinalScores[node] = map[string]int64{}
+		for plugin, v := range scores {
+			score, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				r.InvalidReason = "invalid final score of " + plugin + " on " + node + ": " + v
+				r.FinalScores = nil
+				return r
+			}
+			r.FinalScores[node][plugin] = score
+		}
+	}
+	return r
+}
+
+// Rescore returns the total scores of the Nodes with the new weights.
+// The plugins not in newWeights keep their weights.
+func (r *RecordedScores) Rescore(newWeights map[string]int32) (map[string]int64, error) {
+	if r.InvalidReason != "" {
+		return nil, xerrors.New(r.InvalidReason)
+	}
+
+	totals := make(map[string]int64, len(r.FinalScores))
+	for node, scores := range r.FinalScores {
+		var total int64
+		for plugin, score := range scores {
+			newWeight, changed := newWeights[plugin]
+			if !changed {
+				total += score
+				continue
+			}
+			oldWeight := r.Weights[plugin]
+			if oldWeight == 0 {
+				return nil, xerrors.Errorf("the weight of %s used by the scheduler isn't recorded", plugin)
+			}
+			total += score * int64(newWeight) / int64(oldWeight)
+		}
+		totals[node] = total
+	}
+	return totals, nil
+}
+
+func (r *RecordedScores) result(newWeights map[string]int32) PodResult {
+	ret := PodResult{Namespace: r.Pod.Namespace, Name: r.Pod.Name, CurrentNode: r.CurrentNode}
+	scores, err := r.Rescore(newWeights)
+	if err != nil {
+		ret.SkippedReason = err.Error()
+		return ret
+	}
+	ret.Scores = scores
+	ret.NewNode = HighestScoreNode(scores, r.CurrentNode)
+	ret.Moved = ret.NewNode != r.CurrentNode
+	return ret
+}
+
+// HighestScoreNode returns the Node with the highest score.
+// preferred is returned if it's one of the Nodes with the highest score, because the scheduler picks one of them randomly.
+// Otherwise, the first one in the name order is returned to make the result stable.
+func HighestScoreNode(scores map[string]int64, preferred string) string {
+	nodes := make([]string, 0, len(scores))
+	for n := range scores {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	best := ""
+	for _, n := range nodes {
+		if best == "" || scores[n] > scores[best] {
+			best = n
+		}
+	}
+	if s, ok := scores[preferred]; ok && s == scores[best] {
+		return preferred
+	}
+	return best
+}
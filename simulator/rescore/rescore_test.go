@@ -0,0 +1,106 @@
+package rescore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+)
+
+func TestService_Rescore(t *testing.T) {
+	t.Parallel()
+
+	// node1 wins by ImageLocality, and node2 wins by NodeResourcesFit.
+	scheduled := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				annotation.SelectedNodeAnnotationKey:      "node1",
+				annotation.FinalScoreResultAnnotationKey:  `{"node1":{"ImageLocality":"100","NodeResourcesFit":"20"},"node2":{"ImageLocality":"0","NodeResourcesFit":"80"}}`,
+				annotation.ScorePluginWeightAnnotationKey: `{"ImageLocality":1,"NodeResourcesFit":1}`,
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: "node1"},
+	}
+	withoutWeights := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod2",
+			Namespace: "default",
+			Annotations: map[string]string{
+				annotation.SelectedNodeAnnotationKey:     "node2",
+				annotation.FinalScoreResultAnnotationKey: `{"node1":{"NodeResourcesFit":"20"},"node2":{"NodeResourcesFit":"80"}}`,
+			},
+		},
+	}
+	notScheduledByDebuggableScheduler := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod3", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name string
+		req  *Request
+		want *Report
+	}{
+		{
+			name: "no change in weights",
+			req:  &Request{Namespace: "default"},
+			want: &Report{
+				Pods: []PodResult{
+					{Namespace: "default", Name: "pod1", CurrentNode: "node1", NewNode: "node1", Scores: map[string]int64{"node1": 120, "node2": 80}},
+					{Namespace: "default", Name: "pod2", CurrentNode: "node2", NewNode: "node2", Scores: map[string]int64{"node1": 20, "node2": 80}},
+				},
+			},
+		},
+		{
+			name: "NodeResourcesFit gets heavier",
+			req:  &Request{Weights: map[string]int32{"NodeResourcesFit": 3}},
+			want: &Report{
+				Moved:   1,
+				Skipped: 1,
+				Pods: []PodResult{
+					{Namespace: "default", Name: "pod1", CurrentNode: "node1", NewNode: "node2", Moved: true, Scores: map[string]int64{"node1": 160, "node2": 240}},
+					{Namespace: "default", Name: "pod2", CurrentNode: "node2", SkippedReason: "the weight of NodeResourcesFit used by the scheduler isn't recorded"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := fake.NewSimpleClientset(scheduled.DeepCopy(), withoutWeights.DeepCopy(), notScheduledByDebuggableScheduler.DeepCopy())
+			s := NewService(client)
+
+			got, err := s.Rescore(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_HighestScoreNode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "node2", HighestScoreNode(map[string]int64{"node1": 10, "node2": 20, "node3": 20}, "node1"))
+	assert.Equal(t, "node3", HighestScoreNode(map[string]int64{"node1": 10, "node2": 20, "node3": 20}, "node3"))
+}
+
+func TestRecordedScores_Rescore(t *testing.T) {
+	t.Parallel()
+
+	r := &RecordedScores{
+		FinalScores: map[string]map[string]int64{"node1": {"ImageLocality": 5, "NodeResourcesFit": 10}},
+		Weights:     map[string]int32{"ImageLocality": 2, "NodeResourcesFit": 1},
+	}
+	got, err := r.Rescore(map[string]int32{"ImageLocality": 3})
+	require.NoError(t, err)
+	// 5 / 2 * 3 would truncate the score to 6.
+	assert.Equal(t, map[string]int64{"node1": 17}, got)
+}
@@ -0,0 +1,148 @@
+// Package results provides the scheduling results recorded on Pods by the debuggable schedulers.
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+)
+
+// ErrInvalidFilter is returned when the filter has both ConfigFingerprint and ConfigVersion.
+var ErrInvalidFilter = errors.New("configFingerprint and configVersion cannot be specified together")
+
+// SchedulerService is the part of scheduler.Service used to resolve the configuration versions.
+type SchedulerService interface {
+	GetSchedulerConfigVersion(name string, version int) (*scheduler.ConfigVersion, error)
+}
+
+// Service lists the scheduling results recorded in the result history annotation of Pods.
+type Service struct {
+	client       clientset.Interface
+	schedService SchedulerService
+}
+
+// Filter narrows down the results. The empty fields match any results.
+type Filter struct {
+	Namespace string
+	// ConfigFingerprint matches the results produced by the configuration with the fingerprint.
+	ConfigFingerprint string
+	// Scheduler and ConfigVersion match the results produced by the version of the configuration of the scheduler.
+	// They're resolved to ConfigFingerprint, so they can't be used with it.
+	// Scheduler is the default scheduler if it's empty.
+	Scheduler     string
+	ConfigVersion int
+	// Profile matches the results produced by the scheduler profile with the name.
+	Profile string
+}
+
+// PodResults has the results of a Pod, oldest first.
+type PodResults struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	NodeName  string   `json:"nodeName,omitempty"`
+	Results   []Result `json:"results"`
+}
+
+// Result is a result of a scheduling attempt of a Pod.
+type Result struct {
+	// ConfigFingerprint is empty when the result was recorded by a scheduler which doesn't tag the results.
+	ConfigFingerprint string `json:"configFingerprint,omitempty"`
+	Profile           string `json:"profile,omitempty"`
+	// Annotations are the result annotations recorded in the attempt.
+	Annotations map[string]string `json:"annotations"`
+}
+
+// NewService initializes Service.
+func NewService(client clientset.Interface, schedService SchedulerService) *Service {
+	return &Service{client: client, schedService: schedService}
+}
+
+// List returns the results of Pods matching the filter.
+// The Pods without any matching results are omitted.
+func (s *Service) List(ctx context.Context, filter Filter) ([]PodResults, error) {
+	fingerprint := filter.ConfigFingerprint
+	if filter.ConfigVersion != 0 {
+		if fingerprint != "" {
+			return nil, ErrInvalidFilter
+		}
+		name := filter.Scheduler
+		if name == "" {
+			name = simulatorconfig.DefaultSchedulerName
+		}
+		v, err := s.schedService.GetSchedulerConfigVersion(name, filter.ConfigVersion)
+		if err != nil {
+			return nil, xerrors.Errorf("get config version %d of scheduler %s: %w", filter.ConfigVersion, name, err)
+		}
+		fingerprint = v.Fingerprint
+	}
+
+	pods, err := s.client.CoreV1().Pods(filter.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("list pods: %w", err)
+	}
+
+	ret := []PodResults{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		results := []Result{}
+		for _, r := range podResults(pod) {
+			if fingerprint != "" && r.ConfigFingerprint != fingerprint {
+				continue
+			}
+			if filter.Profile != "" && r.Profile != filter.Profile {
+				continue
+			}
+			results = append(results, r)
+		}
+		if len(results) == 0 {
+			continue
+		}
+		ret = append(ret, PodResults{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			NodeName:  pod.Spec.NodeName,
+			Results:   results,
+		})
+	}
+	return ret, nil
+}
+
+// podResults parses the result history annotation of the Pod.
+func podResults(pod *corev1.Pod) []Result {
+	a, ok := pod.GetAnnotations()[storereflector.ResultsHistoryAnnotation]
+	if !ok {
+		return nil
+	}
+	history := []map[string]string{}
+	if err := json.Unmarshal([]byte(a), &history); err != nil {
+		klog.ErrorS(err, "cannot parse "+storereflector.ResultsHistoryAnnotation, "pod", klog.KObj(pod))
+		return nil
+	}
+
+	results := make([]Result, 0, len(history))
+	for _, h := range history {
+		r := Result{
+			ConfigFingerprint: h[storereflector.ConfigFingerprintAnnotation],
+			Profile:           h[storereflector.ProfileAnnotation],
+			Annotations:       map[string]string{},
+		}
+		for k, v := range h {
+			if k == storereflector.ConfigFingerprintAnnotation || k == storereflector.ProfileAnnotation {
+				continue
+			}
+			r.Annotations[k] = v
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,129 @@
+package results
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+)
+
+type fakeSchedulerService struct {
+	versions map[string][]scheduler.ConfigVersion
+}
+
+func (f *fakeSchedulerService) GetSchedulerConfigVersion(name string, version int) (*scheduler.ConfigVersion, error) {
+	vs, ok := f.versions[name]
+	if !ok {
+		return nil, scheduler.ErrSchedulerNotFound
+	}
+	if version < 1 || version > len(vs) {
+		return nil, scheduler.ErrConfigVersionNotFound
+	}
+	return &vs[version-1], nil
+}
+
+func TestService_List(t *testing.T) {
+	t.Parallel()
+
+	const filterResult = "kube-scheduler-simulator.sigs.k8s.io/filter-result"
+	pods := []*corev1.Pod{
+		pod("pod1", "node1", `[
+			{"kube-scheduler-simulator.sigs.k8s.io/filter-result":"a","kube-scheduler-simulator.sigs.k8s.io/config-fingerprint":"aaa","kube-scheduler-simulator.sigs.k8s.io/profile":"default-scheduler"},
+			{"kube-scheduler-simulator.sigs.k8s.io/filter-result":"b","kube-scheduler-simulator.sigs.k8s.io/config-fingerprint":"bbb","kube-scheduler-simulator.sigs.k8s.io/profile":"default-scheduler"}
+		]`),
+		pod("pod2", "", `[
+			{"kube-scheduler-simulator.sigs.k8s.io/filter-result":"c","kube-scheduler-simulator.sigs.k8s.io/config-fingerprint":"bbb","kube-scheduler-simulator.sigs.k8s.io/profile":"batch-scheduler"}
+		]`),
+		pod("pod3", "", ""),
+	}
+	schedService := &fakeSchedulerService{versions: map[string][]scheduler.ConfigVersion{
+		"default": {{Version: 1, Fingerprint: "aaa"}, {Version: 2, Fingerprint: "bbb"}},
+	}}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    []PodResults
+		wantErr error
+	}{
+		{
+			name:   "no filter returns all results",
+			filter: Filter{},
+			want: []PodResults{
+				{Namespace: "default", Name: "pod1", NodeName: "node1", Results: []Result{
+					{ConfigFingerprint: "aaa", Profile: "default-scheduler", Annotations: map[string]string{filterResult: "a"}},
+					{ConfigFingerprint: "bbb", Profile: "default-scheduler", Annotations: map[string]string{filterResult: "b"}},
+				}},
+				{Namespace: "default", Name: "pod2", Results: []Result{
+					{ConfigFingerprint: "bbb", Profile: "batch-scheduler", Annotations: map[string]string{filterResult: "c"}},
+				}},
+			},
+		},
+		{
+			name:   "filter by the config version",
+			filter: Filter{ConfigVersion: 1},
+			want: []PodResults{
+				{Namespace: "default", Name: "pod1", NodeName: "node1", Results: []Result{
+					{ConfigFingerprint: "aaa", Profile: "default-scheduler", Annotations: map[string]string{filterResult: "a"}},
+				}},
+			},
+		},
+		{
+			name:   "filter by the fingerprint and the profile",
+			filter: Filter{ConfigFingerprint: "bbb", Profile: "batch-scheduler"},
+			want: []PodResults{
+				{Namespace: "default", Name: "pod2", Results: []Result{
+					{ConfigFingerprint: "bbb", Profile: "batch-scheduler", Annotations: map[string]string{filterResult: "c"}},
+				}},
+			},
+		},
+		{
+			name:    "fingerprint and version cannot be used together",
+			filter:  Filter{ConfigFingerprint: "bbb", ConfigVersion: 2},
+			wantErr: ErrInvalidFilter,
+		},
+		{
+			name:    "unknown version",
+			filter:  Filter{ConfigVersion: 3},
+			wantErr: scheduler.ErrConfigVersionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := fake.NewSimpleClientset()
+			for _, p := range pods {
+				_, err := client.CoreV1().Pods(p.Namespace).Create(context.Background(), p, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			s := NewService(client, schedService)
+
+			got, err := s.List(context.Background(), tt.filter)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func pod(name, nodeName, history string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+	}
+	if history != "" {
+		p.Annotations = map[string]string{storereflector.ResultsHistoryAnnotation: history}
+	}
+	return p
+}
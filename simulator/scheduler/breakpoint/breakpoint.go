@@ -0,0 +1,306 @@
+// Package breakpoint pauses the scheduling cycles at the extension points of the wrapped plugins,
+// so that users can look into the CycleState and the Node being evaluated and resume the scheduling when they want.
+package breakpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+var (
+	// ErrInvalidBreakpoint is returned when a breakpoint has an unknown extension point or an invalid Pod selector.
+	ErrInvalidBreakpoint = errors.New("invalid breakpoint")
+	// ErrBreakpointNotFound is returned when no breakpoint has the given ID.
+	ErrBreakpointNotFound = errors.New("breakpoint not found")
+	// ErrHitNotFound is returned when no scheduling is paused with the given ID.
+	ErrHitNotFound = errors.New("paused scheduling not found")
+)
+
+// ExtensionPoints are the extension points where the scheduling can be paused.
+// PreEnqueue isn't included because it's called from the scheduling queue, not in a scheduling cycle.
+var ExtensionPoints = sets.New(
+	"PreFilter", "Filter", "PostFilter", "PreScore", "Score", "NormalizeScore",
+	"Reserve", "Unreserve", "Permit", "PreBind", "Bind", "PostBind",
+)
+
+// Breakpoint pauses the scheduling when a wrapped plugin is called at a location matching all of its conditions.
+// The empty conditions match any location.
+type Breakpoint struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace,omitempty"`
+	// PodSelector selects the Pods by their labels.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Plugin is the name of the original plugin.
+	Plugin         string `json:"plugin,omitempty"`
+	ExtensionPoint string `json:"extensionPoint,omitempty"`
+	// Node only matches the extension points called for a Node, e.g., Filter and Score.
+	Node string `json:"node,omitempty"`
+	// Hits is how many times the breakpoint has paused the scheduling.
+	Hits int `json:"hits"`
+
+	selector labels.Selector
+}
+
+// Location is where a wrapped plugin is called.
+type Location struct {
+	Pod            *v1.Pod
+	Plugin         string
+	ExtensionPoint string
+	// Node is empty at the extension points not called for a Node.
+	Node string
+}
+
+func (b *Breakpoint) matches(loc Location) bool {
+	switch {
+	case b.Namespace != "" && b.Namespace != loc.Pod.Namespace:
+		return false
+	case b.selector != nil && !b.selector.Matches(labels.Set(loc.Pod.Labels)):
+		return false
+	case b.Plugin != "" && b.Plugin != loc.Plugin:
+		return false
+	case b.ExtensionPoint != "" && b.ExtensionPoint != loc.ExtensionPoint:
+		return false
+	case b.Node != "" && b.Node != loc.Node:
+		return false
+	}
+	return true
+}
+
+// Snapshot is the state of the scheduling cycle when it's paused.
+type Snapshot struct {
+	CycleState *CycleState `json:"cycleState,omitempty"`
+	// NodeInfo is the Node being evaluated. It's nil at the extension points not called for a Node.
+	NodeInfo *NodeInfo `json:"nodeInfo,omitempty"`
+}
+
+// CycleState is the content of framework.CycleState.
+type CycleState struct {
+	SkipFilterPlugins []string `json:"skipFilterPlugins,omitempty"`
+	SkipScorePlugins  []string `json:"skipScorePlugins,omitempty"`
+	// Entries are the entries written by the plugins, encoded in JSON and keyed by their state keys.
+	// Only the entries whose serializers are registered are included.
+	Entries map[string]json.RawMessage `json:"entries,omitempty"`
+}
+
+// NodeInfo is the content of framework.NodeInfo used in the scheduling cycle.
+type NodeInfo struct {
+	Name string `json:"name"`
+	// Pods are the Pods on the Node, including the ones assumed in the scheduling, in "<namespace>/<name>".
+	Pods        []string            `json:"pods"`
+	Requested   *framework.Resource `json:"requested"`
+	Allocatable *framework.Resource `json:"allocatable"`
+}
+
+// NewNodeInfo copies framework.NodeInfo to NodeInfo. It returns nil if nodeInfo has no Node.
+func NewNodeInfo(nodeInfo *framework.NodeInfo) *NodeInfo {
+	if nodeInfo == nil || nodeInfo.Node() == nil {
+		return nil
+	}
+	ret := &NodeInfo{
+		Name:        nodeInfo.Node().Name,
+		Pods:        make([]string, 0, len(nodeInfo.Pods)),
+		Requested:   cloneResource(nodeInfo.Requested),
+		Allocatable: cloneResource(nodeInfo.Allocatable),
+	}
+	for _, p := range nodeInfo.Pods {
+		ret.Pods = append(ret.Pods, p.Pod.Namespace+"/"+p.Pod.Name)
+	}
+	sort.Strings(ret.Pods)
+	return ret
+}
+
+func cloneResource(r *framework.Resource) *framework.Resource {
+	if r == nil {
+		return nil
+	}
+	return r.Clone()
+}
+
+// Hit is a scheduling paused at a breakpoint.
+type Hit struct {
+	ID             string    `json:"id"`
+	Breakpoint     string    `json:"breakpoint"`
+	Namespace      string    `json:"namespace"`
+	Pod            string    `json:"pod"`
+	Plugin         string    `json:"plugin"`
+	ExtensionPoint string    `json:"extensionPoint"`
+	Node           string    `json:"node,omitempty"`
+	PausedAt       time.Time `json:"pausedAt"`
+	Snapshot
+
+	resume chan struct{}
+}
+
+// Debugger manages the breakpoints and the paused scheduling of a scheduler.
+// Manager implements it for the scheduler in the same process, and Client does for the one behind the proxy server.
+type Debugger interface {
+	ListBreakpoints(ctx context.Context) ([]Breakpoint, error)
+	AddBreakpoint(ctx context.Context, b *Breakpoint) (*Breakpoint, error)
+	// DeleteBreakpoint deletes the breakpoint, and resumes the scheduling paused at it.
+	DeleteBreakpoint(ctx context.Context, id string) error
+	ListHits(ctx context.Context) ([]Hit, error)
+	Resume(ctx context.Context, hitID string) error
+}
+
+// Manager keeps the breakpoints, and pauses the scheduling at them.
+type Manager struct {
+	mu          sync.Mutex
+	breakpoints []*Breakpoint
+	hits        []*Hit
+	// lastID is the last ID given to a breakpoint or a hit.
+	lastID int
+}
+
+var _ Debugger = &Manager{}
+
+// NewManager initializes Manager without breakpoints.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Pause blocks until the scheduling is resumed if a breakpoint matches the location.
+// snapshot is called only when the scheduling is paused.
+// It also returns when ctx is done, e.g., when the scheduler is stopped.
+func (m *Manager) Pause(ctx context.Context, loc Location, snapshot func() Snapshot) {
+	m.mu.Lock()
+	var matched *Breakpoint
+	for _, b := range m.breakpoints {
+		if b.matches(loc) {
+			matched = b
+			break
+		}
+	}
+	if matched == nil {
+		m.mu.Unlock()
+		return
+	}
+	matched.Hits++
+	m.lastID++
+	hit := &Hit{
+		ID:             strconv.Itoa(m.lastID),
+		Breakpoint:     matched.ID,
+		Namespace:      loc.Pod.Namespace,
+		Pod:            loc.Pod.Name,
+		Plugin:         loc.Plugin,
+		ExtensionPoint: loc.ExtensionPoint,
+		Node:           loc.Node,
+		PausedAt:       time.Now(),
+		Snapshot:       snapshot(),
+		resume:         make(chan struct{}),
+	}
+	m.hits = append(m.hits, hit)
+	m.mu.Unlock()
+
+	klog.InfoS("scheduling is paused at breakpoint", "breakpoint", hit.Breakpoint, "hit", hit.ID, "pod", klog.KObj(loc.Pod), "plugin", loc.Plugin, "extensionPoint", loc.ExtensionPoint, "node", loc.Node)
+	select {
+	case <-hit.resume:
+	case <-ctx.Done():
+		m.mu.Lock()
+		m.removeHit(hit.ID)
+		m.mu.Unlock()
+	}
+}
+
+// ListBreakpoints returns the breakpoints in the order they're added.
+func (m *Manager) ListBreakpoints(_ context.Context) ([]Breakpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ret := make([]Breakpoint, 0, len(m.breakpoints))
+	for _, b := range m.breakpoints {
+		ret = append(ret, *b)
+	}
+	return ret, nil
+}
+
+// AddBreakpoint adds the breakpoint with a new ID. The ID and Hits of b are ignored.
+func (m *Manager) AddBreakpoint(_ context.Context, b *Breakpoint) (*Breakpoint, error) {
+	if b.ExtensionPoint != "" && !ExtensionPoints.Has(b.ExtensionPoint) {
+		return nil, xerrors.Errorf("unknown extension point %q: %w", b.ExtensionPoint, ErrInvalidBreakpoint)
+	}
+	added := *b
+	added.Hits = 0
+	if b.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(b.PodSelector)
+		if err != nil {
+			return nil, xerrors.Errorf("parse pod selector: %v: %w", err, ErrInvalidBreakpoint)
+		}
+		added.selector = selector
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	added.ID = strconv.Itoa(m.lastID)
+	m.breakpoints = append(m.breakpoints, &added)
+	ret := added
+	return &ret, nil
+}
+
+func (m *Manager) DeleteBreakpoint(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, b := range m.breakpoints {
+		if b.ID != id {
+			continue
+		}
+		m.breakpoints = append(m.breakpoints[:i], m.breakpoints[i+1:]...)
+		for _, h := range append([]*Hit{}, m.hits...) {
+			if h.Breakpoint == id {
+				close(h.resume)
+				m.removeHit(h.ID)
+			}
+		}
+		return nil
+	}
+	return xerrors.Errorf("delete breakpoint %s: %w", id, ErrBreakpointNotFound)
+}
+
+// ListHits returns the paused scheduling in the order they're paused.
+func (m *Manager) ListHits(_ context.Context) ([]Hit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ret := make([]Hit, 0, len(m.hits))
+	for _, h := range m.hits {
+		ret = append(ret, *h)
+	}
+	return ret, nil
+}
+
+func (m *Manager) Resume(_ context.Context, hitID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := m.removeHit(hitID)
+	if h == nil {
+		return xerrors.Errorf("resume %s: %w", hitID, ErrHitNotFound)
+	}
+	close(h.resume)
+	return nil
+}
+
+// removeHit removes the hit with the ID, and returns it. m.mu must be held.
+func (m *Manager) removeHit(id string) *Hit {
+	for i, h := range m.hits {
+		if h.ID == id {
+			m.hits = append(m.hits[:i], m.hits[i+1:]...)
+			return h
+		}
+	}
+	return nil
+}
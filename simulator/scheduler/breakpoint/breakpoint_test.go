@@ -0,0 +1,174 @@
+package breakpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(name string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+}
+
+func TestBreakpoint_matches(t *testing.T) {
+	t.Parallel()
+
+	loc := Location{Pod: pod("pod1", map[string]string{"app": "web"}), Plugin: "NodeResourcesFit", ExtensionPoint: "Filter", Node: "node1"}
+	tests := []struct {
+		name string
+		bp   *Breakpoint
+		want bool
+	}{
+		{
+			name: "empty conditions match any location",
+			bp:   &Breakpoint{},
+			want: true,
+		},
+		{
+			name: "all conditions match",
+			bp: &Breakpoint{
+				Namespace:      "default",
+				PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Plugin:         "NodeResourcesFit",
+				ExtensionPoint: "Filter",
+				Node:           "node1",
+			},
+			want: true,
+		},
+		{
+			name: "pod selector doesn't match",
+			bp:   &Breakpoint{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			want: false,
+		},
+		{
+			name: "extension point doesn't match",
+			bp:   &Breakpoint{ExtensionPoint: "Score"},
+			want: false,
+		},
+		{
+			name: "node doesn't match",
+			bp:   &Breakpoint{Node: "node2"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewManager()
+			bp, err := m.AddBreakpoint(context.Background(), tt.bp)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.breakpoints[0].matches(loc), "breakpoint: %+v", bp)
+		})
+	}
+}
+
+func TestManager_AddBreakpoint_Invalid(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	_, err := m.AddBreakpoint(context.Background(), &Breakpoint{ExtensionPoint: "PreEnqueue"})
+	assert.ErrorIs(t, err, ErrInvalidBreakpoint)
+	_, err = m.AddBreakpoint(context.Background(), &Breakpoint{PodSelector: &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidBreakpoint)
+}
+
+func TestManager_PauseAndResume(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewManager()
+	bp, err := m.AddBreakpoint(ctx, &Breakpoint{ExtensionPoint: "Filter"})
+	require.NoError(t, err)
+
+	// The location not matching the breakpoint doesn't pause.
+	m.Pause(ctx, Location{Pod: pod("pod1", nil), Plugin: "NodeResourcesFit", ExtensionPoint: "Score"}, func() Snapshot {
+		t.Error("snapshot must not be taken")
+		return Snapshot{}
+	})
+
+	resumed := make(chan struct{})
+	go func() {
+		m.Pause(ctx, Location{Pod: pod("pod1", nil), Plugin: "NodeResourcesFit", ExtensionPoint: "Filter", Node: "node1"}, func() Snapshot {
+			return Snapshot{CycleState: &CycleState{SkipScorePlugins: []string{"ImageLocality"}}}
+		})
+		close(resumed)
+	}()
+
+	hits := waitForHits(t, m, 1)
+	assert.Equal(t, bp.ID, hits[0].Breakpoint)
+	assert.Equal(t, "pod1", hits[0].Pod)
+	assert.Equal(t, "node1", hits[0].Node)
+	assert.Equal(t, []string{"ImageLocality"}, hits[0].CycleState.SkipScorePlugins)
+
+	assert.ErrorIs(t, m.Resume(ctx, "unknown"), ErrHitNotFound)
+	require.NoError(t, m.Resume(ctx, hits[0].ID))
+	<-resumed
+
+	bps, err := m.ListBreakpoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bps[0].Hits)
+	hits, err = m.ListHits(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestManager_DeleteBreakpoint_ResumesHits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewManager()
+	bp, err := m.AddBreakpoint(ctx, &Breakpoint{})
+	require.NoError(t, err)
+
+	resumed := make(chan struct{})
+	go func() {
+		m.Pause(ctx, Location{Pod: pod("pod1", nil), Plugin: "NodeResourcesFit", ExtensionPoint: "PreFilter"}, func() Snapshot { return Snapshot{} })
+		close(resumed)
+	}()
+	waitForHits(t, m, 1)
+
+	require.NoError(t, m.DeleteBreakpoint(ctx, bp.ID))
+	<-resumed
+	assert.ErrorIs(t, m.DeleteBreakpoint(ctx, bp.ID), ErrBreakpointNotFound)
+}
+
+func TestManager_Pause_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	_, err := m.AddBreakpoint(context.Background(), &Breakpoint{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resumed := make(chan struct{})
+	go func() {
+		m.Pause(ctx, Location{Pod: pod("pod1", nil), Plugin: "NodeResourcesFit", ExtensionPoint: "PreFilter"}, func() Snapshot { return Snapshot{} })
+		close(resumed)
+	}()
+	waitForHits(t, m, 1)
+
+	cancel()
+	<-resumed
+	hits, err := m.ListHits(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func waitForHits(t *testing.T, m *Manager, n int) []Hit {
+	t.Helper()
+	var hits []Hit
+	require.Eventually(t, func() bool {
+		var err error
+		hits, err = m.ListHits(context.Background())
+		return err == nil && len(hits) == n
+	}, 5*time.Second, 10*time.Millisecond)
+	return hits
+}
@@ -0,0 +1,116 @@
+package breakpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// Client is the Debugger of the debuggable scheduler behind its proxy server.
+type Client struct {
+	// baseURL is the URL of the API group serving the breakpoints, e.g., "http://simulator-scheduler:1212/api/v1".
+	baseURL string
+	client  *http.Client
+}
+
+var _ Debugger = &Client{}
+
+// NewClient initializes Client to call the breakpoint API under baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{baseURL: baseURL, client: http.DefaultClient}
+}
+
+func (c *Client) ListBreakpoints(ctx context.Context) ([]Breakpoint, error) {
+	ret := []Breakpoint{}
+	if err := c.do(ctx, http.MethodGet, "/breakpoints", nil, &ret); err != nil {
+		return nil, xerrors.Errorf("list breakpoints: %w", err)
+	}
+	return ret, nil
+}
+
+func (c *Client) AddBreakpoint(ctx context.Context, b *Breakpoint) (*Breakpoint, error) {
+	ret := &Breakpoint{}
+	if err := c.do(ctx, http.MethodPost, "/breakpoints", b, ret); err != nil {
+		return nil, xerrors.Errorf("add breakpoint: %w", err)
+	}
+	return ret, nil
+}
+
+func (c *Client) DeleteBreakpoint(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodDelete, "/breakpoints/"+id, nil, nil); err != nil {
+		return xerrors.Errorf("delete breakpoint %s: %w", id, err)
+	}
+	return nil
+}
+
+func (c *Client) ListHits(ctx context.Context) ([]Hit, error) {
+	ret := []Hit{}
+	if err := c.do(ctx, http.MethodGet, "/breakpoints/hits", nil, &ret); err != nil {
+		return nil, xerrors.Errorf("list paused scheduling: %w", err)
+	}
+	return ret, nil
+}
+
+func (c *Client) Resume(ctx context.Context, hitID string) error {
+	if err := c.do(ctx, http.MethodPost, "/breakpoints/hits/"+hitID+"/resume", nil, nil); err != nil {
+		return xerrors.Errorf("resume %s: %w", hitID, err)
+	}
+	return nil
+}
+
+// do sends the request with reqBody in JSON, and decodes the response into respBody unless it's nil.
+// The status codes for the errors of Manager are converted back to them.
+func (c *Client) do(ctx context.Context, method, path string, reqBody, respBody interface{}) error {
+	var body io.Reader = http.NoBody
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return xerrors.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("call %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusBadRequest:
+		return xerrors.Errorf("%s %s: %s: %w", method, path, readMessage(resp.Body), ErrInvalidBreakpoint)
+	case http.StatusNotFound:
+		if method == http.MethodDelete {
+			return xerrors.Errorf("%s %s: %w", method, path, ErrBreakpointNotFound)
+		}
+		return xerrors.Errorf("%s %s: %w", method, path, ErrHitNotFound)
+	default:
+		return xerrors.Errorf("%s %s: unexpected status %s: %s", method, path, resp.Status, readMessage(resp.Body))
+	}
+
+	if respBody == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+		return xerrors.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func readMessage(r io.Reader) string {
+	b, err := io.ReadAll(io.LimitReader(r, 4096))
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(b))
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"golang.org/x/xerrors"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/bridge"
+)
+
+// bridgePluginKind is the kind of the profile registries for bridge plugins.
+const bridgePluginKind = "bridge"
+
+// RegisterBridgePlugins registers the bridge plugins, which forward the extension points to external processes,
+// from the given configuration of the debuggable scheduler.
+// scheduler is the name of the debuggable scheduler, which is empty for the default one.
+// The bridge plugins registered from the configuration of the scheduler before are replaced.
+func RegisterBridgePlugins(scheduler string, versionedCfg *configv1.KubeSchedulerConfiguration) error {
+	cfg := config.KubeSchedulerConfiguration{}
+	if err := scheme.Scheme.Convert(versionedCfg, &cfg, nil); err != nil {
+		return xerrors.Errorf("convert configuration: %w", err)
+	}
+
+	setProfileRegistries(scheduler, bridgePluginKind, getBridgeRegistryFromUnversionedConfig(&cfg))
+
+	return nil
+}
+
+// getBridgeRegistryFromUnversionedConfig registers the enabled plugins whose PluginConfig has endpoint.
+// It returns the registries keyed by the scheduler name of the profiles.
+func getBridgeRegistryFromUnversionedConfig(cfg *config.KubeSchedulerConfiguration) map[string]runtime.Registry {
+	registries := map[string]runtime.Registry{}
+
+	for _, profile := range cfg.Profiles {
+		registry := runtime.Registry{}
+		registries[profile.SchedulerName] = registry
+		enabled := enabledPluginNames(profile.Plugins)
+		for _, pc := range profile.PluginConfig {
+			if enabled.Has(pc.Name) && bridge.DecodePluginConfig(pc.Args) != nil {
+				registry[pc.Name] = bridge.PluginFactory(pc.Name)
+			}
+		}
+	}
+
+	return registries
+}
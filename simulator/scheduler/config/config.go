@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/xerrors"
 	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/kube-scheduler/config/v1"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
 )
@@ -35,6 +36,11 @@ func UpdateSchedulerConfig(cfg *v1.KubeSchedulerConfiguration) error {
 	if kubeSchedulerConfigPath == "" {
 		return xerrors.New("kubeSchedulerConfigPath isn't initialized, which is likely a bug in the simulator")
 	}
+	return WriteSchedulerConfig(kubeSchedulerConfigPath, cfg)
+}
+
+// WriteSchedulerConfig writes the given scheduler config to path in YAML.
+func WriteSchedulerConfig(path string, cfg *v1.KubeSchedulerConfiguration) error {
 	jsonData, err := json.Marshal(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal jsonData: %w", err)
@@ -52,9 +58,26 @@ func UpdateSchedulerConfig(cfg *v1.KubeSchedulerConfiguration) error {
 		return fmt.Errorf("failed to marshal yaml: %w", err)
 	}
 
-	if err := os.WriteFile(kubeSchedulerConfigPath, data, 0o600); err != nil {
+	if err := os.WriteFile(path, data, 0o600); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
+
+// ProfileSchedulerNames returns the schedulerName of each profile in cfg.
+// A scheduler only handles Pods whose spec.schedulerName matches one of them.
+func ProfileSchedulerNames(cfg *v1.KubeSchedulerConfiguration) []string {
+	if len(cfg.Profiles) == 0 {
+		return []string{corev1.DefaultSchedulerName}
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for _, p := range cfg.Profiles {
+		if p.SchedulerName == nil || *p.SchedulerName == "" {
+			names = append(names, corev1.DefaultSchedulerName)
+			continue
+		}
+		names = append(names, *p.SchedulerName)
+	}
+	return names
+}
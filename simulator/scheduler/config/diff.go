@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/kube-scheduler/config/v1"
+)
+
+// ChangeType is the kind of a change between two configurations.
+type ChangeType string
+
+const (
+	ChangeTypeAdded   ChangeType = "added"
+	ChangeTypeRemoved ChangeType = "removed"
+	ChangeTypeChanged ChangeType = "changed"
+)
+
+// Change is a difference in a field between two configurations.
+type Change struct {
+	// Path is the path to the field, e.g., "profiles[0].pluginConfig[1].args.hardPodAffinityWeight".
+	Path string     `json:"path"`
+	Type ChangeType `json:"type"`
+	// From is the value in the old configuration. It's nil when the field is added.
+	From interface{} `json:"from,omitempty"`
+	// To is the value in the new configuration. It's nil when the field is removed.
+	To interface{} `json:"to,omitempty"`
+}
+
+// DiffSchedulerConfigs returns the fields changed from oldCfg to newCfg, ordered by their paths.
+// The fields are compared in the JSON representation so that the paths match the field names users write.
+// The elements of lists are compared by their indexes.
+func DiffSchedulerConfigs(oldCfg, newCfg *v1.KubeSchedulerConfiguration) ([]Change, error) {
+	oldObj, err := toJSONObject(oldCfg)
+	if err != nil {
+		return nil, xerrors.Errorf("convert old config: %w", err)
+	}
+	newObj, err := toJSONObject(newCfg)
+	if err != nil {
+		return nil, xerrors.Errorf("convert new config: %w", err)
+	}
+
+	changes := []Change{}
+	diffValues("", oldObj, newObj, &changes)
+	return changes, nil
+}
+
+func toJSONObject(cfg *v1.KubeSchedulerConfiguration) (interface{}, error) {
+	if cfg == nil {
+		return map[string]interface{}{}, nil
+	}
+	cfg = cfg.DeepCopy()
+	// The args decoded into the typed objects are encoded as they are in the config file.
+	for i := range cfg.Profiles {
+		for j := range cfg.Profiles[i].PluginConfig {
+			args := &cfg.Profiles[i].PluginConfig[j].Args
+			if args.Raw != nil || args.Object == nil {
+				continue
+			}
+			raw, err := json.Marshal(args.Object)
+			if err != nil {
+				return nil, xerrors.Errorf("encode args of %s: %w", cfg.Profiles[i].PluginConfig[j].Name, err)
+			}
+			args.Raw = raw
+		}
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, xerrors.Errorf("encode config: %w", err)
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, xerrors.Errorf("decode config: %w", err)
+	}
+	return obj, nil
+}
+
+func diffValues(path string, oldVal, newVal interface{}, changes *[]Change) {
+	switch {
+	case oldVal == nil && newVal == nil:
+		return
+	case oldVal == nil:
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeAdded, To: newVal})
+		return
+	case newVal == nil:
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeRemoved, From: oldVal})
+		return
+	}
+
+	oldMap, oldIsMap := oldVal.(map[string]interface{})
+	newMap, newIsMap := newVal.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := map[string]struct{}{}
+		for k := range oldMap {
+			keys[k] = struct{}{}
+		}
+		for k := range newMap {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			diffValues(childPath, oldMap[k], newMap[k], changes)
+		}
+		return
+	}
+
+	oldList, oldIsList := oldVal.([]interface{})
+	newList, newIsList := newVal.([]interface{})
+	if oldIsList && newIsList {
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			var o, n interface{}
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				n = newList[i]
+			}
+			diffValues(path+"["+strconv.Itoa(i)+"]", o, n, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeChanged, From: oldVal, To: newVal})
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDiffSchedulerConfigs(t *testing.T) {
+	t.Parallel()
+
+	base := func() *v1.KubeSchedulerConfiguration {
+		return &v1.KubeSchedulerConfiguration{
+			Parallelism: ptr.To[int32](16),
+			Profiles: []v1.KubeSchedulerProfile{{
+				SchedulerName: ptr.To("default-scheduler"),
+				PluginConfig: []v1.PluginConfig{
+					{Name: "InterPodAffinity", Args: runtime.RawExtension{Raw: []byte(`{"hardPodAffinityWeight":1}`)}},
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *v1.KubeSchedulerConfiguration)
+		want   []Change
+	}{
+		{
+			name:   "no changes",
+			modify: func(_ *v1.KubeSchedulerConfiguration) {},
+			want:   []Change{},
+		},
+		{
+			name: "a field is changed",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Parallelism = ptr.To[int32](8)
+			},
+			want: []Change{{Path: "parallelism", Type: ChangeTypeChanged, From: float64(16), To: float64(8)}},
+		},
+		{
+			name: "args of a plugin are changed",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].PluginConfig[0].Args.Raw = []byte(`{"hardPodAffinityWeight":5}`)
+			},
+			want: []Change{{Path: "profiles[0].pluginConfig[0].args.hardPodAffinityWeight", Type: ChangeTypeChanged, From: float64(1), To: float64(5)}},
+		},
+		{
+			name: "a profile is added and a field is removed",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Parallelism = nil
+				cfg.Profiles = append(cfg.Profiles, v1.KubeSchedulerProfile{SchedulerName: ptr.To("another-scheduler")})
+			},
+			want: []Change{
+				{Path: "parallelism", Type: ChangeTypeRemoved, From: float64(16)},
+				{Path: "profiles[1]", Type: ChangeTypeAdded, To: map[string]interface{}{"schedulerName": "another-scheduler"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			newCfg := base()
+			tt.modify(newCfg)
+
+			got, err := DiffSchedulerConfigs(base(), newCfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
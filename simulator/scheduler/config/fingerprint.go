@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+)
+
+// Fingerprint returns a short hash identifying the scheduler configuration.
+// The configuration is defaulted and encoded in the canonical form before hashing,
+// so the simulator and the scheduler get the same fingerprint
+// whether the configuration is given via the API or loaded from the file.
+func Fingerprint(cfg *v1.KubeSchedulerConfiguration) (string, error) {
+	versioned := cfg.DeepCopy()
+	versioned.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("KubeSchedulerConfiguration"))
+	codec := scheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+	data, err := runtime.Encode(codec, versioned)
+	if err != nil {
+		return "", xerrors.Errorf("encode scheduler config: %w", err)
+	}
+	// Decoding applies the defaults to the configuration and the args of in-tree plugins.
+	obj, _, err := scheme.Codecs.UniversalDecoder(v1.SchemeGroupVersion).Decode(data, nil, nil)
+	if err != nil {
+		return "", xerrors.Errorf("decode scheduler config: %w", err)
+	}
+	data, err = runtime.Encode(codec, obj)
+	if err != nil {
+		return "", xerrors.Errorf("encode defaulted scheduler config: %w", err)
+	}
+
+	// Re-encode it via a generic object to sort the keys in the args.
+	var canonical interface{}
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return "", xerrors.Errorf("decode encoded scheduler config: %w", err)
+	}
+	data, err = json.Marshal(canonical)
+	if err != nil {
+		return "", xerrors.Errorf("encode canonical scheduler config: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12], nil
+}
+
+// FileFingerprint returns the fingerprint of the scheduler configuration in the file.
+// It's the default configuration's if path is empty, in the same way as kube-scheduler.
+func FileFingerprint(path string) (string, error) {
+	if path == "" {
+		cfg, err := DefaultSchedulerConfig()
+		if err != nil {
+			return "", xerrors.Errorf("get default scheduler config: %w", err)
+		}
+		return Fingerprint(cfg)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", xerrors.Errorf("read scheduler config file: %w", err)
+	}
+	obj, _, err := scheme.Codecs.UniversalDecoder(v1.SchemeGroupVersion).Decode(data, nil, nil)
+	if err != nil {
+		return "", xerrors.Errorf("decode scheduler config file: %w", err)
+	}
+	cfg, ok := obj.(*v1.KubeSchedulerConfiguration)
+	if !ok {
+		return "", xerrors.Errorf("unexpected type of scheduler config: %T", obj)
+	}
+	return Fingerprint(cfg)
+}
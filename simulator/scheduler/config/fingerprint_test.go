@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	cfg := func(weight int32) *v1.KubeSchedulerConfiguration {
+		c := &v1.KubeSchedulerConfiguration{
+			Profiles: []v1.KubeSchedulerProfile{{
+				SchedulerName: ptr.To("default-scheduler"),
+				PluginConfig: []v1.PluginConfig{
+					{Name: "InterPodAffinity", Args: runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"hardPodAffinityWeight":%d}`, weight))}},
+				},
+			}},
+		}
+		c.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("KubeSchedulerConfiguration"))
+		return c
+	}
+
+	fp1, err := Fingerprint(cfg(1))
+	require.NoError(t, err)
+	fp2, err := Fingerprint(cfg(2))
+	require.NoError(t, err)
+	assert.NotEqual(t, fp1, fp2)
+
+	// The config written to the file for the scheduler has the same fingerprint.
+	path := filepath.Join(t.TempDir(), "scheduler.yaml")
+	require.NoError(t, WriteSchedulerConfig(path, cfg(1)))
+	fromFile, err := FileFingerprint(path)
+	require.NoError(t, err)
+	assert.Equal(t, fp1, fromFile)
+
+	// The defaults are applied before hashing.
+	defaultCfg, err := DefaultSchedulerConfig()
+	require.NoError(t, err)
+	fpDefault, err := Fingerprint(defaultCfg)
+	require.NoError(t, err)
+	fpEmpty, err := Fingerprint(&v1.KubeSchedulerConfiguration{})
+	require.NoError(t, err)
+	assert.Equal(t, fpDefault, fpEmpty)
+	fpNoFile, err := FileFingerprint("")
+	require.NoError(t, err)
+	assert.Equal(t, fpDefault, fpNoFile)
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"sync"
+
 	"golang.org/x/xerrors"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins"
@@ -11,6 +13,17 @@ var outOfTreeRegistries = runtime.Registry{
 	// TODO(user): add your plugins registries here.
 }
 
+// profileRegistries are the registries of the plugins configured in the profiles, e.g., wasm plugins.
+// They're keyed by the debuggable scheduler whose configuration has the plugins, by the kind of the plugins,
+// and then by the scheduler name of the profile.
+// The registries of a kind of a scheduler are replaced every time the plugins are registered from its configuration,
+// so that the plugins and the profiles removed from the configuration don't remain registered,
+// while the ones of the other schedulers running in the same process are kept.
+var (
+	profileRegistriesMu sync.RWMutex
+	profileRegistries   = map[string]map[string]map[string]runtime.Registry{}
+)
+
 // RegisteredMultiPointPluginNames returns all registered multipoint plugin names.
 // in-tree plugins and your original plugins listed in outOfTreeRegistries above.
 func RegisteredMultiPointPluginNames() ([]string, error) {
@@ -27,6 +40,24 @@ func RegisteredMultiPointPluginNames() ([]string, error) {
 	return append(enabledPls, OutOfTreeMultiPointPluginNames()...), nil
 }
 
+// staticPluginNames returns the names of the in-tree plugins and the plugins in outOfTreeRegistries,
+// excluding the plugins configured in the profiles, which are registered from the configuration the scheduler is started with.
+func staticPluginNames() ([]string, error) {
+	def, err := InTreeMultiPointPluginSet()
+	if err != nil {
+		return nil, xerrors.Errorf("get default multi point plugins: %w", err)
+	}
+
+	ret := make([]string, 0, len(def.Enabled)+len(outOfTreeRegistries))
+	for _, e := range def.Enabled {
+		ret = append(ret, e.Name)
+	}
+	for k := range outOfTreeRegistries {
+		ret = append(ret, k)
+	}
+	return ret, nil
+}
+
 // InTreeMultiPointPluginSet returns default multipoint plugins.
 // See also: https://github.com/kubernetes/kubernetes/blob/475f9010f5faa7bdd439944a6f5f1ec206297602/pkg/scheduler/apis/config/v1/default_plugins.go#L30https://github.com/kubernetes/kubernetes/blob/475f9010f5faa7bdd439944a6f5f1ec206297602/pkg/scheduler/apis/config/v1/default_plugins.go#L30
 func InTreeMultiPointPluginSet() (configv1.PluginSet, error) {
@@ -39,8 +70,9 @@ func InTreeMultiPointPluginSet() (configv1.PluginSet, error) {
 }
 
 func OutOfTreeMultiPointPluginNames() []string {
-	registeredOutOfTreeMultiPointName := make([]string, 0, len(outOfTreeRegistries))
-	for k := range outOfTreeRegistries {
+	registries := OutOfTreeRegistries()
+	registeredOutOfTreeMultiPointName := make([]string, 0, len(registries))
+	for k := range registries {
 		registeredOutOfTreeMultiPointName = append(registeredOutOfTreeMultiPointName, k)
 	}
 	return registeredOutOfTreeMultiPointName
@@ -50,8 +82,66 @@ func InTreeRegistries() runtime.Registry {
 	return plugins.NewInTreeRegistry()
 }
 
+// OutOfTreeRegistries returns the registries in outOfTreeRegistries and the ones of the plugins configured in the profiles.
 func OutOfTreeRegistries() runtime.Registry {
-	return outOfTreeRegistries
+	profileRegistriesMu.RLock()
+	defer profileRegistriesMu.RUnlock()
+	if len(profileRegistries) == 0 {
+		return outOfTreeRegistries
+	}
+
+	ret := runtime.Registry{}
+	for _, kinds := range profileRegistries {
+		for _, registries := range kinds {
+			for _, r := range registries {
+				for k, v := range r {
+					ret[k] = v
+				}
+			}
+		}
+	}
+	// The plugins registered by users take precedence.
+	for k, v := range outOfTreeRegistries {
+		ret[k] = v
+	}
+	return ret
+}
+
+// ProfileOutOfTreeRegistry returns the registries in outOfTreeRegistries and the ones of the plugins configured in the profile with the scheduler name.
+// Unlike OutOfTreeRegistries, the plugins configured in the other profiles aren't included.
+func ProfileOutOfTreeRegistry(schedulerName string) runtime.Registry {
+	ret := ProfileRegistry(schedulerName)
+	// The plugins registered by users take precedence.
+	for k, v := range outOfTreeRegistries {
+		ret[k] = v
+	}
+	return ret
+}
+
+// ProfileRegistry returns the registry of the plugins configured in the profile with the scheduler name.
+// The scheduler names of the profiles are unique among the debuggable schedulers.
+func ProfileRegistry(schedulerName string) runtime.Registry {
+	profileRegistriesMu.RLock()
+	defer profileRegistriesMu.RUnlock()
+	ret := runtime.Registry{}
+	for _, kinds := range profileRegistries {
+		for _, registries := range kinds {
+			for k, v := range registries[schedulerName] {
+				ret[k] = v
+			}
+		}
+	}
+	return ret
+}
+
+// setProfileRegistries replaces the registries of the plugins of the kind for the debuggable scheduler with the given ones as a whole.
+func setProfileRegistries(scheduler, kind string, registries map[string]runtime.Registry) {
+	profileRegistriesMu.Lock()
+	defer profileRegistriesMu.Unlock()
+	if _, ok := profileRegistries[scheduler]; !ok {
+		profileRegistries[scheduler] = map[string]map[string]runtime.Registry{}
+	}
+	profileRegistries[scheduler][kind] = registries
 }
 
 func SetOutOfTreeRegistries(r runtime.Registry) {
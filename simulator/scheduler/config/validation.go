@@ -0,0 +1,351 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	v1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/validation"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	wasm "sigs.k8s.io/kube-scheduler-wasm-extension/scheduler/plugin"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/bridge"
+)
+
+// FieldError is a problem in a field of KubeSchedulerConfiguration.
+type FieldError struct {
+	// Field is the path to the field, e.g., "profiles[0].plugins.filter.enabled[1].name".
+	// It's empty when the problem isn't about a specific field.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when KubeSchedulerConfiguration is invalid.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "invalid scheduler configuration"
+	}
+	if e.Errors[0].Field == "" {
+		return "invalid scheduler configuration: " + e.Errors[0].Message
+	}
+	return "invalid scheduler configuration: " + e.Errors[0].Field + ": " + e.Errors[0].Message
+}
+
+// ValidateSchedulerConfig validates the scheduler configuration given by users without applying it.
+// In addition to the upstream validation, it checks that
+// (1) all enabled plugins are registered to the simulator or configured as wasm or bridge plugins,
+// (2) the args in every PluginConfig can be decoded,
+// (3) the wasm plugins have a valid guestURL, and the bridge plugins have a valid endpoint.
+// It returns nil if the configuration is valid; otherwise *ValidationError.
+func ValidateSchedulerConfig(cfg *v1.KubeSchedulerConfiguration) error {
+	_, err := validateSchedulerConfig(cfg, false)
+	return err
+}
+
+// ValidateSchedulerConfigForContainer validates the scheduler configuration applied to a scheduler running in a container.
+// It's the same as ValidateSchedulerConfig, except that the enabled plugins which aren't registered to the simulator
+// are returned as the warnings instead of the errors, because they may be registered to the debuggable scheduler in the container.
+// The error is nil if the configuration is valid; otherwise *ValidationError.
+func ValidateSchedulerConfigForContainer(cfg *v1.KubeSchedulerConfiguration) ([]FieldError, error) {
+	return validateSchedulerConfig(cfg, true)
+}
+
+// validateSchedulerConfig returns the warnings and *ValidationError.
+// The enabled plugins which aren't registered are warned about only when unknownPluginsAllowed is true.
+func validateSchedulerConfig(cfg *v1.KubeSchedulerConfiguration, unknownPluginsAllowed bool) ([]FieldError, error) {
+	errs, warnings := field.ErrorList{}, field.ErrorList{}
+
+	// The wasm and bridge plugins registered for the current configuration are validated with their PluginConfig as well.
+	registered, err := staticPluginNames()
+	if err != nil {
+		return nil, xerrors.Errorf("get registered plugin names: %w", err)
+	}
+	registeredSet := sets.New(registered...)
+
+	profilesPath := field.NewPath("profiles")
+	argsDecoded := true
+	for i, profile := range cfg.Profiles {
+		path := profilesPath.Index(i)
+		argsErrs := validatePluginConfigArgs(path, profile)
+		argsDecoded = argsDecoded && len(argsErrs) == 0
+		errs = append(errs, argsErrs...)
+		enabledErrs, enabledWarnings := validateEnabledPlugins(path, profile, registeredSet, unknownPluginsAllowed)
+		errs = append(errs, enabledErrs...)
+		warnings = append(warnings, enabledWarnings...)
+		errs = append(errs, validateUnusedWasmPluginConfigs(path, profile, registeredSet)...)
+	}
+
+	ret := toFieldErrors(errs)
+	// The upstream validation is done only when the configuration can be converted to the internal one.
+	if argsDecoded {
+		ret = append(ret, validateInternalConfig(cfg)...)
+	}
+
+	if len(ret) == 0 {
+		return toFieldErrors(warnings), nil
+	}
+	return toFieldErrors(warnings), &ValidationError{Errors: ret}
+}
+
+// validatePluginConfigArgs checks that the args of each PluginConfig can be decoded.
+// The args of in-tree plugins are decoded into their own type when the configuration is loaded by kube-scheduler,
+// so each PluginConfig is decoded one by one to know which one is broken.
+func validatePluginConfigArgs(path *field.Path, profile v1.KubeSchedulerProfile) field.ErrorList {
+	errs := field.ErrorList{}
+	for j, pc := range profile.PluginConfig {
+		single := &v1.KubeSchedulerConfiguration{
+			Profiles: []v1.KubeSchedulerProfile{{SchedulerName: profile.SchedulerName, PluginConfig: []v1.PluginConfig{pc}}},
+		}
+		if _, err := decodeToInternalConfig(single); err != nil {
+			errs = append(errs, field.Invalid(path.Child("pluginConfig").Index(j).Child("args"), pc.Name, "cannot be decoded: "+err.Error()))
+		}
+	}
+	return errs
+}
+
+// decodeToInternalConfig encodes cfg and decodes it with the strict decoder in the same way as kube-scheduler loads the config file.
+// Unlike the conversion, it decodes the args of in-tree plugins given as raw bytes into their own type and applies the defaults.
+func decodeToInternalConfig(cfg *v1.KubeSchedulerConfiguration) (*config.KubeSchedulerConfiguration, error) {
+	versioned := cfg.DeepCopy()
+	versioned.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("KubeSchedulerConfiguration"))
+	data, err := runtime.Encode(scheme.Codecs.LegacyCodec(v1.SchemeGroupVersion), versioned)
+	if err != nil {
+		return nil, xerrors.Errorf("encode scheduler config: %w", err)
+	}
+	obj, _, err := scheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("decode scheduler config: %w", err)
+	}
+	internalCfg, ok := obj.(*config.KubeSchedulerConfiguration)
+	if !ok {
+		return nil, xerrors.Errorf("unexpected type of scheduler config: %T", obj)
+	}
+	return internalCfg, nil
+}
+
+// validateEnabledPlugins checks that the enabled plugins are registered to the simulator.
+// A plugin which isn't registered is regarded as a wasm plugin if it has a PluginConfig,
+// in the same way as RegisterWasmPlugins, or as a bridge plugin if the PluginConfig has endpoint.
+// When unknownPluginsAllowed is true, the plugins which aren't registered are returned as the warnings,
+// unless their PluginConfig has endpoint or guestURL.
+func validateEnabledPlugins(path *field.Path, profile v1.KubeSchedulerProfile, registered sets.Set[string], unknownPluginsAllowed bool) (field.ErrorList, field.ErrorList) {
+	errs, warnings := field.ErrorList{}, field.ErrorList{}
+	if profile.Plugins == nil {
+		return errs, warnings
+	}
+
+	pluginConfigIndex := map[string]int{}
+	for j, pc := range profile.PluginConfig {
+		pluginConfigIndex[pc.Name] = j
+	}
+
+	pluginsPath := path.Child("plugins")
+	validated := sets.New[string]()
+	for _, ps := range pluginSetsOf(profile.Plugins) {
+		for k, p := range ps.set.Enabled {
+			if registered.Has(p.Name) {
+				continue
+			}
+			namePath := pluginsPath.Child(ps.name, "enabled").Index(k).Child("name")
+			j, hasConfig := pluginConfigIndex[p.Name]
+			if !hasConfig {
+				if unknownPluginsAllowed {
+					warnings = append(warnings, unregisteredPlugin(namePath, p.Name))
+					continue
+				}
+				errs = append(errs, field.NotSupported(namePath, p.Name, sets.List(registered)))
+				continue
+			}
+			if validated.Has(p.Name) {
+				// The plugin enabled at multiple extension points is validated once.
+				continue
+			}
+			validated.Insert(p.Name)
+			argsPath := path.Child("pluginConfig").Index(j).Child("args")
+			if isBridge, err := validateBridgePluginConfig(argsPath, profile.PluginConfig[j]); isBridge {
+				errs = append(errs, err...)
+				continue
+			}
+			if unknownPluginsAllowed {
+				if wasmCfg := decodeWasmPluginConfig(profile.PluginConfig[j]); wasmCfg == nil || wasmCfg.GuestURL == "" {
+					// The PluginConfig may have the args of the plugin registered to the scheduler.
+					warnings = append(warnings, unregisteredPlugin(namePath, p.Name))
+					continue
+				}
+			}
+			errs = append(errs, validateWasmPluginConfig(argsPath, profile.PluginConfig[j])...)
+		}
+	}
+	return errs, warnings
+}
+
+func unregisteredPlugin(path *field.Path, name string) *field.Error {
+	return field.Invalid(path, name, "isn't registered to the simulator, so it has to be registered to the scheduler")
+}
+
+// validateUnusedWasmPluginConfigs checks that the wasm plugins having guestURL in their PluginConfig are enabled,
+// in the same way as RegisterWasmPlugins.
+func validateUnusedWasmPluginConfigs(path *field.Path, profile v1.KubeSchedulerProfile, registered sets.Set[string]) field.ErrorList {
+	errs := field.ErrorList{}
+	for j, pc := range profile.PluginConfig {
+		if registered.Has(pc.Name) || isEnabled(profile.Plugins, pc.Name) {
+			continue
+		}
+		wasmCfg := decodeWasmPluginConfig(pc)
+		if wasmCfg == nil || wasmCfg.GuestURL == "" {
+			continue
+		}
+		errs = append(errs, field.Invalid(path.Child("pluginConfig").Index(j).Child("name"), pc.Name, "the wasm plugin isn't enabled at any extension point"))
+	}
+	return errs
+}
+
+// pluginSet is a set of the plugins with the name of its extension point.
+type pluginSet struct {
+	name string
+	set  v1.PluginSet
+}
+
+// pluginSetsOf returns the plugin sets of all extension points, including multiPoint.
+func pluginSetsOf(plugins *v1.Plugins) []pluginSet {
+	if plugins == nil {
+		return nil
+	}
+	return []pluginSet{
+		{"multiPoint", plugins.MultiPoint},
+		{"preEnqueue", plugins.PreEnqueue},
+		{"queueSort", plugins.QueueSort},
+		{"preFilter", plugins.PreFilter},
+		{"filter", plugins.Filter},
+		{"postFilter", plugins.PostFilter},
+		{"preScore", plugins.PreScore},
+		{"score", plugins.Score},
+		{"reserve", plugins.Reserve},
+		{"permit", plugins.Permit},
+		{"preBind", plugins.PreBind},
+		{"bind", plugins.Bind},
+		{"postBind", plugins.PostBind},
+	}
+}
+
+// isEnabled returns true if the plugin with the name is enabled at any extension point.
+func isEnabled(plugins *v1.Plugins, name string) bool {
+	for _, ps := range pluginSetsOf(plugins) {
+		for _, p := range ps.set.Enabled {
+			if p.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validateWasmPluginConfig(path *field.Path, pc v1.PluginConfig) field.ErrorList {
+	args, ok := toUnversionedArgs(pc)
+	if !ok {
+		// It's reported by validatePluginConfigArgs.
+		return nil
+	}
+
+	wasmCfg := &wasm.PluginConfig{}
+	if err := frameworkruntime.DecodeInto(args, wasmCfg); err != nil {
+		return field.ErrorList{field.Invalid(path, pc.Name, "cannot be decoded as the config of a wasm plugin: "+err.Error())}
+	}
+	guestURLPath := path.Child("guestURL")
+	if wasmCfg.GuestURL == "" {
+		return field.ErrorList{field.Required(guestURLPath, "the wasm plugin needs guestURL")}
+	}
+	u, err := url.Parse(wasmCfg.GuestURL)
+	if err != nil {
+		return field.ErrorList{field.Invalid(guestURLPath, wasmCfg.GuestURL, err.Error())}
+	}
+	if u.Scheme != "file" && u.Scheme != "http" && u.Scheme != "https" {
+		return field.ErrorList{field.NotSupported(guestURLPath, u.Scheme, []string{"file", "http", "https"})}
+	}
+	return nil
+}
+
+// validateBridgePluginConfig validates the PluginConfig if it's for a bridge plugin.
+// It returns false when the PluginConfig isn't for a bridge plugin.
+func validateBridgePluginConfig(path *field.Path, pc v1.PluginConfig) (bool, field.ErrorList) {
+	args, ok := toUnversionedArgs(pc)
+	if !ok {
+		return false, nil
+	}
+
+	bridgeCfg := bridge.DecodePluginConfig(args)
+	if bridgeCfg == nil {
+		return false, nil
+	}
+	if err := bridgeCfg.Validate(); err != nil {
+		return true, field.ErrorList{field.Invalid(path, pc.Name, "invalid config of a bridge plugin: "+err.Error())}
+	}
+	return true, nil
+}
+
+// decodeWasmPluginConfig returns the config of the wasm plugin in the PluginConfig.
+// It returns nil when the PluginConfig isn't for a wasm plugin.
+func decodeWasmPluginConfig(pc v1.PluginConfig) *wasm.PluginConfig {
+	args, ok := toUnversionedArgs(pc)
+	if !ok || bridge.DecodePluginConfig(args) != nil {
+		return nil
+	}
+	wasmCfg := &wasm.PluginConfig{}
+	if err := frameworkruntime.DecodeInto(args, wasmCfg); err != nil {
+		return nil
+	}
+	return wasmCfg
+}
+
+// toUnversionedArgs converts the args of the PluginConfig in the same way as the scheduler configuration is converted.
+// It returns false when the args can't be converted.
+func toUnversionedArgs(pc v1.PluginConfig) (runtime.Object, bool) {
+	unversioned := &config.KubeSchedulerConfiguration{}
+	versioned := &v1.KubeSchedulerConfiguration{Profiles: []v1.KubeSchedulerProfile{{PluginConfig: []v1.PluginConfig{pc}}}}
+	if err := scheme.Scheme.Convert(versioned, unversioned, nil); err != nil {
+		return nil, false
+	}
+	return unversioned.Profiles[0].PluginConfig[0].Args, true
+}
+
+// validateInternalConfig runs the upstream validation in the same way as kube-scheduler does on startup.
+func validateInternalConfig(cfg *v1.KubeSchedulerConfiguration) []FieldError {
+	internalCfg, err := decodeToInternalConfig(cfg)
+	if err != nil {
+		return []FieldError{{Message: err.Error()}}
+	}
+	internalCfg.TypeMeta.APIVersion = v1.SchemeGroupVersion.String()
+
+	agg := validation.ValidateKubeSchedulerConfiguration(internalCfg)
+	if agg == nil {
+		return nil
+	}
+	ret := []FieldError{}
+	for _, err := range agg.Errors() {
+		var fe *field.Error
+		if errors.As(err, &fe) {
+			ret = append(ret, toFieldErrors(field.ErrorList{fe})...)
+			continue
+		}
+		ret = append(ret, FieldError{Message: err.Error()})
+	}
+	return ret
+}
+
+func toFieldErrors(errs field.ErrorList) []FieldError {
+	ret := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		ret = append(ret, FieldError{Field: e.Field, Message: e.ErrorBody()})
+	}
+	return ret
+}
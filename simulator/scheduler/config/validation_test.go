@@ -0,0 +1,236 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/kube-scheduler/config/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateSchedulerConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// modify modifies the default config.
+		modify         func(cfg *v1.KubeSchedulerConfiguration)
+		wantErrorField []string
+	}{
+		{
+			name:   "default config is valid",
+			modify: func(_ *v1.KubeSchedulerConfiguration) {},
+		},
+		{
+			name: "unknown plugin is enabled",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					Filter: v1.PluginSet{Enabled: []v1.Plugin{{Name: "NoSuchPlugin"}}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].plugins.filter.enabled[0].name"},
+		},
+		{
+			name: "args of in-tree plugin cannot be decoded",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "NodeResourcesFit", Args: runtime.RawExtension{Raw: []byte(`{"scoringStrategy": "not an object"}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args"},
+		},
+		{
+			name: "args of in-tree plugin has unknown field",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "NodeResourcesFit", Args: runtime.RawExtension{Raw: []byte(`{"unknownField": 1}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args"},
+		},
+		{
+			name: "args of in-tree plugin is invalid",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "InterPodAffinity", Args: runtime.RawExtension{Raw: []byte(`{"hardPodAffinityWeight": 1000}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args.hardPodAffinityWeight"},
+		},
+		{
+			name: "wasm plugin without guestURL",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					MultiPoint: v1.PluginSet{Enabled: []v1.Plugin{{Name: "wasmPlugin"}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args.guestURL"},
+		},
+		{
+			name: "wasm plugin enabled at filter and score",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					Filter: v1.PluginSet{Enabled: []v1.Plugin{{Name: "wasmPlugin"}}},
+					Score:  v1.PluginSet{Enabled: []v1.Plugin{{Name: "wasmPlugin", Weight: ptr.To[int32](1)}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{"guestURL": "file:///plugin.wasm"}`)}},
+				}
+			},
+		},
+		{
+			name: "wasm plugin isn't enabled",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{"guestURL": "file:///plugin.wasm"}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].name"},
+		},
+		{
+			name: "bridge plugin is valid",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					MultiPoint: v1.PluginSet{Enabled: []v1.Plugin{{Name: "bridgePlugin"}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "bridgePlugin", Args: runtime.RawExtension{Raw: []byte(`{"endpoint": "http://localhost:8080", "extensionPoints": ["filter"]}`)}},
+				}
+			},
+		},
+		{
+			name: "bridge plugin with unknown extension point",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					MultiPoint: v1.PluginSet{Enabled: []v1.Plugin{{Name: "bridgePlugin"}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "bridgePlugin", Args: runtime.RawExtension{Raw: []byte(`{"endpoint": "http://localhost:8080", "extensionPoints": ["bind"]}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args"},
+		},
+		{
+			name: "upstream validation fails",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Parallelism = ptr.To[int32](-1)
+			},
+			wantErrorField: []string{"parallelism"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := DefaultSchedulerConfig()
+			require.NoError(t, err)
+			tt.modify(cfg)
+
+			err = ValidateSchedulerConfig(cfg)
+			if len(tt.wantErrorField) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			fields := []string{}
+			for _, e := range verr.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.wantErrorField, fields)
+		})
+	}
+}
+
+func TestValidateSchedulerConfigForContainer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// modify modifies the default config.
+		modify           func(cfg *v1.KubeSchedulerConfiguration)
+		wantWarningField []string
+		wantErrorField   []string
+	}{
+		{
+			name: "unknown plugin is warned about",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					Filter: v1.PluginSet{Enabled: []v1.Plugin{{Name: "CustomPlugin"}}},
+				}
+			},
+			wantWarningField: []string{"profiles[0].plugins.filter.enabled[0].name"},
+		},
+		{
+			name: "unknown plugin with its own args is warned about",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					MultiPoint: v1.PluginSet{Enabled: []v1.Plugin{{Name: "CustomPlugin"}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "CustomPlugin", Args: runtime.RawExtension{Raw: []byte(`{"threshold": 1}`)}},
+				}
+			},
+			wantWarningField: []string{"profiles[0].plugins.multiPoint.enabled[0].name"},
+		},
+		{
+			name: "wasm plugin with invalid guestURL",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					MultiPoint: v1.PluginSet{Enabled: []v1.Plugin{{Name: "wasmPlugin"}}},
+				}
+				cfg.Profiles[0].PluginConfig = []v1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{"guestURL": "ftp://plugin.wasm"}`)}},
+				}
+			},
+			wantErrorField: []string{"profiles[0].pluginConfig[0].args.guestURL"},
+		},
+		{
+			name: "upstream validation fails",
+			modify: func(cfg *v1.KubeSchedulerConfiguration) {
+				cfg.Profiles[0].Plugins = &v1.Plugins{
+					Filter: v1.PluginSet{Enabled: []v1.Plugin{{Name: "CustomPlugin"}}},
+				}
+				cfg.Parallelism = ptr.To[int32](-1)
+			},
+			wantWarningField: []string{"profiles[0].plugins.filter.enabled[0].name"},
+			wantErrorField:   []string{"parallelism"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := DefaultSchedulerConfig()
+			require.NoError(t, err)
+			tt.modify(cfg)
+
+			warnings, err := ValidateSchedulerConfigForContainer(cfg)
+			warningFields := []string{}
+			for _, w := range warnings {
+				warningFields = append(warningFields, w.Field)
+			}
+			if len(tt.wantWarningField) == 0 {
+				assert.Empty(t, warningFields)
+			} else {
+				assert.Equal(t, tt.wantWarningField, warningFields)
+			}
+			if len(tt.wantErrorField) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			fields := []string{}
+			for _, e := range verr.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.wantErrorField, fields)
+		})
+	}
+}
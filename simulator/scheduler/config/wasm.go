@@ -1,58 +1,177 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+
 	"golang.org/x/xerrors"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
 	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	wasm "sigs.k8s.io/kube-scheduler-wasm-extension/scheduler/plugin"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/bridge"
 )
 
-// RegisterWasmPlugins registers wasm plugins from the given configuration.
-func RegisterWasmPlugins(versionedCfg *configv1.KubeSchedulerConfiguration) error {
+// wasmPluginKind is the kind of the profile registries for wasm plugins.
+const wasmPluginKind = "wasm"
+
+// RegisterWasmPlugins registers wasm plugins from the given configuration of the debuggable scheduler.
+// scheduler is the name of the debuggable scheduler, which is empty for the default one.
+// The wasm plugins registered from the configuration of the scheduler before are replaced.
+func RegisterWasmPlugins(scheduler string, versionedCfg *configv1.KubeSchedulerConfiguration) error {
 	cfg := config.KubeSchedulerConfiguration{}
 	if err := scheme.Scheme.Convert(versionedCfg, &cfg, nil); err != nil {
 		return xerrors.Errorf("convert configuration: %w", err)
 	}
 
-	registry, err := getWasmRegistryFromUnversionedConfig(&cfg)
+	registries, err := getWasmRegistryFromUnversionedConfig(&cfg)
 	if err != nil {
 		return err
 	}
 
-	SetOutOfTreeRegistries(registry)
+	setProfileRegistries(scheduler, wasmPluginKind, registries)
 
 	return nil
 }
 
 // getWasmRegistryFromUnversionedConfig registers wasm plugins from the given unversioned configuration.
-func getWasmRegistryFromUnversionedConfig(cfg *config.KubeSchedulerConfiguration) (runtime.Registry, error) {
-	registry := runtime.Registry{}
+// It returns the registries keyed by the scheduler name of the profiles.
+// A plugin is regarded as a wasm plugin when it isn't registered to the simulator and its PluginConfig can be decoded as the config of a wasm plugin.
+// It returns an error when a wasm plugin has guestURL in its PluginConfig but isn't enabled at any extension point,
+// which is likely to be a mistake in the configuration.
+func getWasmRegistryFromUnversionedConfig(cfg *config.KubeSchedulerConfiguration) (map[string]runtime.Registry, error) {
+	registries := map[string]runtime.Registry{}
+	intree := InTreeRegistries()
 
 	for _, profile := range cfg.Profiles {
-		wasmplugins := sets.New[string]()
+		registry := runtime.Registry{}
+		registries[profile.SchedulerName] = registry
+		enabled := enabledPluginNames(profile.Plugins)
+
 		// look for the wasm plugin in the plugin config.
-		for _, config := range profile.PluginConfig {
-			if err := runtime.DecodeInto(config.Args, &wasm.PluginConfig{}); err != nil {
+		for _, pc := range profile.PluginConfig {
+			if _, ok := intree[pc.Name]; ok {
+				continue
+			}
+			if _, ok := outOfTreeRegistries[pc.Name]; ok {
+				continue
+			}
+			wasmCfg := &wasm.PluginConfig{}
+			if err := runtime.DecodeInto(pc.Args, wasmCfg); err != nil {
 				// not wasm plugin.
 				continue
 			}
+			if bridge.DecodePluginConfig(pc.Args) != nil {
+				// bridge plugin, which is registered by RegisterBridgePlugins.
+				continue
+			}
 
-			wasmplugins.Insert(config.Name)
+			if !enabled.Has(pc.Name) {
+				if wasmCfg.GuestURL != "" {
+					return nil, xerrors.Errorf("wasm plugin %s is configured in profile %s, but isn't enabled at any extension point", pc.Name, profile.SchedulerName)
+				}
+				continue
+			}
+			if err := registry.Register(pc.Name, wasm.PluginFactory(pc.Name)); err != nil {
+				return nil, xerrors.Errorf("register plugin %s: %w", pc.Name, err)
+			}
 		}
+	}
 
-		// look for the wasm plugin in the enabled plugins.
-		// (assuming that the wasm plugin is specified as a multi-point plugin.)
-		for _, plugin := range profile.Plugins.MultiPoint.Enabled {
-			if wasmplugins.Has(plugin.Name) {
-				if err := registry.Register(plugin.Name, wasm.PluginFactory(plugin.Name)); err != nil {
-					return nil, xerrors.Errorf("register plugin %s: %w", plugin.Name, err)
-				}
+	return registries, nil
+}
+
+// enabledPluginNames returns the names of the plugins enabled at any extension point, including multiPoint.
+func enabledPluginNames(plugins *config.Plugins) sets.Set[string] {
+	ret := sets.New[string]()
+	if plugins == nil {
+		return ret
+	}
+	for _, ps := range []config.PluginSet{
+		plugins.MultiPoint, plugins.PreEnqueue, plugins.QueueSort, plugins.PreFilter, plugins.Filter, plugins.PostFilter,
+		plugins.PreScore, plugins.Score, plugins.Reserve, plugins.Permit, plugins.PreBind, plugins.Bind, plugins.PostBind,
+	} {
+		for _, p := range ps.Enabled {
+			ret.Insert(p.Name)
+		}
+	}
+	return ret
+}
+
+// LoadWasmPlugin loads the wasm plugin with cfg in the same way as the scheduler does, and discards it.
+// It's for checking that the guest can be loaded before the scheduler is restarted with it.
+func LoadWasmPlugin(ctx context.Context, pluginName string, cfg wasm.PluginConfig) error {
+	// The handle is used only when the guest is called at the extension points.
+	p, err := wasm.NewFromConfig(ctx, pluginName, cfg, nil)
+	if err != nil {
+		return xerrors.Errorf("load wasm plugin %s: %w", pluginName, err)
+	}
+	if c, ok := p.(io.Closer); ok {
+		_ = c.Close()
+	}
+	return nil
+}
+
+// SetWasmGuestURL replaces guestURL of the wasm plugin with the name in all profiles where it's enabled,
+// and returns the new configs of the plugin. It returns no configs when no profile has the wasm plugin.
+func SetWasmGuestURL(cfg *configv1.KubeSchedulerConfiguration, pluginName, guestURL string) ([]wasm.PluginConfig, error) {
+	ret := []wasm.PluginConfig{}
+	for i := range cfg.Profiles {
+		profile := &cfg.Profiles[i]
+		if !isEnabled(profile.Plugins, pluginName) {
+			continue
+		}
+		for j := range profile.PluginConfig {
+			pc := &profile.PluginConfig[j]
+			if pc.Name != pluginName {
+				continue
+			}
+			wasmCfg, err := setGuestURL(&pc.Args, guestURL)
+			if err != nil {
+				return nil, xerrors.Errorf("set guestURL of %s in profile %s: %w", pluginName, profile.SchedulerName, err)
+			}
+			if wasmCfg != nil {
+				ret = append(ret, *wasmCfg)
 			}
 		}
 	}
+	return ret, nil
+}
 
-	return registry, nil
+// setGuestURL replaces guestURL in the args, keeping the other fields as they are.
+// It returns nil without changing the args when they're for a bridge plugin.
+func setGuestURL(args *apiruntime.RawExtension, guestURL string) (*wasm.PluginConfig, error) {
+	raw := args.Raw
+	if len(raw) == 0 && args.Object != nil {
+		b, err := json.Marshal(args.Object)
+		if err != nil {
+			return nil, xerrors.Errorf("encode args: %w", err)
+		}
+		raw = b
+	}
+	m := map[string]interface{}{}
+	if len(raw) != 0 {
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, xerrors.Errorf("decode args: %w", err)
+		}
+	}
+	if _, ok := m["endpoint"]; ok {
+		return nil, nil
+	}
+	m["guestURL"] = guestURL
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("encode args: %w", err)
+	}
+	wasmCfg := &wasm.PluginConfig{}
+	if err := json.Unmarshal(b, wasmCfg); err != nil {
+		return nil, xerrors.Errorf("decode args as the config of a wasm plugin: %w", err)
+	}
+	*args = apiruntime.RawExtension{Raw: b}
+	return wasmCfg, nil
 }
@@ -1,25 +1,40 @@
 package config
 
 import (
+	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	configv1 "k8s.io/kube-scheduler/config/v1"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/utils/ptr"
+	wasm "sigs.k8s.io/kube-scheduler-wasm-extension/scheduler/plugin"
+	wasmtest "sigs.k8s.io/kube-scheduler-wasm-extension/scheduler/test"
 )
 
 func TestGetWasmRegistryFromUnversionedConfig(t *testing.T) {
 	t.Parallel()
 
+	wasmArgs := func(guestURL string) *runtime.Unknown {
+		return &runtime.Unknown{
+			ContentType: runtime.ContentTypeJSON,
+			Raw:         []byte(`{"guestURL":"` + guestURL + `"}`),
+		}
+	}
+
 	tests := []struct {
 		name     string
 		cfg      *config.KubeSchedulerConfiguration
-		expected int
+		expected map[string][]string
+		wantErr  bool
 	}{
 		{
 			name:     "no profiles",
 			cfg:      &config.KubeSchedulerConfiguration{},
-			expected: 0,
+			expected: map[string][]string{},
 		},
 		{
 			name: "no wasm plugins",
@@ -42,7 +57,7 @@ func TestGetWasmRegistryFromUnversionedConfig(t *testing.T) {
 					},
 				},
 			},
-			expected: 0,
+			expected: map[string][]string{},
 		},
 		{
 			name: "one wasm plugin",
@@ -70,7 +85,7 @@ func TestGetWasmRegistryFromUnversionedConfig(t *testing.T) {
 					},
 				},
 			},
-			expected: 1,
+			expected: map[string][]string{"": {"wasmPlugin"}},
 		},
 		{
 			name: "multiple wasm plugins",
@@ -103,7 +118,78 @@ func TestGetWasmRegistryFromUnversionedConfig(t *testing.T) {
 					},
 				},
 			},
-			expected: 2,
+			expected: map[string][]string{"": {"wasmPlugin1", "wasmPlugin2"}},
+		},
+		{
+			name: "bridge plugin isn't regarded as wasm plugin",
+			cfg: &config.KubeSchedulerConfiguration{
+				Profiles: []config.KubeSchedulerProfile{
+					{
+						PluginConfig: []config.PluginConfig{
+							{Name: "bridgePlugin", Args: &runtime.Unknown{
+								ContentType: runtime.ContentTypeJSON,
+								Raw:         []byte(`{"endpoint":"http://localhost:8080"}`),
+							}},
+						},
+						Plugins: &config.Plugins{
+							MultiPoint: config.PluginSet{
+								Enabled: []config.Plugin{
+									{Name: "bridgePlugin"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "wasm plugins enabled at extension points other than multiPoint",
+			cfg: &config.KubeSchedulerConfiguration{
+				Profiles: []config.KubeSchedulerProfile{
+					{
+						PluginConfig: []config.PluginConfig{
+							{Name: "wasmFilter", Args: wasmArgs("http://example.com/filter.wasm")},
+							{Name: "wasmScore", Args: wasmArgs("http://example.com/score.wasm")},
+						},
+						Plugins: &config.Plugins{
+							Filter: config.PluginSet{Enabled: []config.Plugin{{Name: "wasmFilter"}}},
+							Score:  config.PluginSet{Enabled: []config.Plugin{{Name: "wasmScore"}}},
+						},
+					},
+				},
+			},
+			expected: map[string][]string{"": {"wasmFilter", "wasmScore"}},
+		},
+		{
+			name: "wasm plugins in multiple profiles",
+			cfg: &config.KubeSchedulerConfiguration{
+				Profiles: []config.KubeSchedulerProfile{
+					{
+						SchedulerName: "scheduler1",
+						PluginConfig:  []config.PluginConfig{{Name: "wasmPlugin", Args: wasmArgs("http://example.com/plugin1.wasm")}},
+						Plugins:       &config.Plugins{MultiPoint: config.PluginSet{Enabled: []config.Plugin{{Name: "wasmPlugin"}}}},
+					},
+					{
+						SchedulerName: "scheduler2",
+						PluginConfig:  []config.PluginConfig{{Name: "wasmPlugin", Args: wasmArgs("http://example.com/plugin2.wasm")}},
+						Plugins:       &config.Plugins{Filter: config.PluginSet{Enabled: []config.Plugin{{Name: "wasmPlugin"}}}},
+					},
+				},
+			},
+			expected: map[string][]string{"scheduler1": {"wasmPlugin"}, "scheduler2": {"wasmPlugin"}},
+		},
+		{
+			name: "wasm plugin isn't enabled",
+			cfg: &config.KubeSchedulerConfiguration{
+				Profiles: []config.KubeSchedulerProfile{
+					{
+						PluginConfig: []config.PluginConfig{{Name: "wasmPlugin", Args: wasmArgs("http://example.com/plugin.wasm")}},
+						Plugins:      &config.Plugins{},
+					},
+				},
+			},
+			wantErr: true,
 		},
 	}
 
@@ -111,11 +197,135 @@ func TestGetWasmRegistryFromUnversionedConfig(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			registry, err := getWasmRegistryFromUnversionedConfig(tt.cfg)
+			registries, err := getWasmRegistryFromUnversionedConfig(tt.cfg)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
 			require.NoError(t, err, "check error")
-			if len(registry) != tt.expected {
-				t.Errorf("expected %d plugins, got %d", tt.expected, len(registry))
+			got := map[string][]string{}
+			for schedulerName, registry := range registries {
+				if len(registry) == 0 {
+					continue
+				}
+				got[schedulerName] = sets.List(sets.KeySet(registry))
 			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+//nolint:paralleltest // cannot use t.Parallel because RegisterWasmPlugins affects other test cases.
+func TestRegisterWasmPlugins(t *testing.T) {
+	cfgWithPlugins := func(names ...string) *configv1.KubeSchedulerConfiguration {
+		profile := configv1.KubeSchedulerProfile{SchedulerName: ptr.To("wasm-registry-test"), Plugins: &configv1.Plugins{}}
+		for _, name := range names {
+			profile.Plugins.Filter.Enabled = append(profile.Plugins.Filter.Enabled, configv1.Plugin{Name: name})
+			profile.PluginConfig = append(profile.PluginConfig, configv1.PluginConfig{
+				Name: name,
+				Args: runtime.RawExtension{Raw: []byte(`{"guestURL":"http://example.com/` + name + `.wasm"}`)},
+			})
+		}
+		return &configv1.KubeSchedulerConfiguration{Profiles: []configv1.KubeSchedulerProfile{profile}}
+	}
+	t.Cleanup(func() {
+		require.NoError(t, RegisterWasmPlugins("wasm-test", cfgWithPlugins()))
+	})
+
+	require.NoError(t, RegisterWasmPlugins("wasm-test", cfgWithPlugins("wasmPlugin1", "wasmPlugin2")))
+	assert.ElementsMatch(t, []string{"wasmPlugin1", "wasmPlugin2"}, sets.List(sets.KeySet(ProfileRegistry("wasm-registry-test"))))
+	assert.Contains(t, OutOfTreeRegistries(), "wasmPlugin1")
+
+	// The plugin removed from the configuration is unregistered.
+	require.NoError(t, RegisterWasmPlugins("wasm-test", cfgWithPlugins("wasmPlugin2")))
+	assert.ElementsMatch(t, []string{"wasmPlugin2"}, sets.List(sets.KeySet(ProfileRegistry("wasm-registry-test"))))
+	assert.NotContains(t, OutOfTreeRegistries(), "wasmPlugin1")
+
+	// The plugins of the profile removed from the configuration are unregistered.
+	renamed := cfgWithPlugins("wasmPlugin2")
+	renamed.Profiles[0].SchedulerName = ptr.To("wasm-registry-test-renamed")
+	require.NoError(t, RegisterWasmPlugins("wasm-test", renamed))
+	assert.Empty(t, ProfileRegistry("wasm-registry-test"))
+	assert.Contains(t, ProfileOutOfTreeRegistry("wasm-registry-test-renamed"), "wasmPlugin2")
+
+	// The plugins registered from the configuration of another scheduler are kept.
+	other := cfgWithPlugins("wasmPlugin3")
+	other.Profiles[0].SchedulerName = ptr.To("wasm-registry-test-other")
+	t.Cleanup(func() {
+		require.NoError(t, RegisterWasmPlugins("wasm-test-other", cfgWithPlugins()))
+	})
+	require.NoError(t, RegisterWasmPlugins("wasm-test-other", other))
+	require.NoError(t, RegisterWasmPlugins("wasm-test", cfgWithPlugins("wasmPlugin1")))
+	assert.Contains(t, ProfileRegistry("wasm-registry-test-other"), "wasmPlugin3")
+	assert.Contains(t, ProfileRegistry("wasm-registry-test"), "wasmPlugin1")
+}
+
+func TestSetWasmGuestURL(t *testing.T) {
+	t.Parallel()
+
+	cfg := &configv1.KubeSchedulerConfiguration{
+		Profiles: []configv1.KubeSchedulerProfile{
+			{
+				SchedulerName: ptr.To("wasm-scheduler"),
+				Plugins:       &configv1.Plugins{MultiPoint: configv1.PluginSet{Enabled: []configv1.Plugin{{Name: "wasmPlugin"}, {Name: "bridgePlugin"}}}},
+				PluginConfig: []configv1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{"guestURL":"file:///old.wasm","guestConfig":"{\"a\":1}"}`)}},
+					{Name: "bridgePlugin", Args: runtime.RawExtension{Raw: []byte(`{"endpoint":"http://localhost:8080"}`)}},
+				},
+			},
+			{
+				// The plugin isn't enabled in this profile.
+				SchedulerName: ptr.To("other-scheduler"),
+				PluginConfig: []configv1.PluginConfig{
+					{Name: "wasmPlugin", Args: runtime.RawExtension{Raw: []byte(`{"guestURL":"file:///old.wasm"}`)}},
+				},
+			},
+		},
+	}
+
+	got, err := SetWasmGuestURL(cfg, "wasmPlugin", "file:///new.wasm")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "file:///new.wasm", got[0].GuestURL)
+	assert.Equal(t, `{"a":1}`, got[0].GuestConfig)
+	assert.JSONEq(t, `{"guestURL":"file:///new.wasm","guestConfig":"{\"a\":1}"}`, string(cfg.Profiles[0].PluginConfig[0].Args.Raw))
+	assert.JSONEq(t, `{"guestURL":"file:///old.wasm"}`, string(cfg.Profiles[1].PluginConfig[0].Args.Raw))
+
+	got, err = SetWasmGuestURL(cfg, "bridgePlugin", "file:///new.wasm")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	assert.JSONEq(t, `{"endpoint":"http://localhost:8080"}`, string(cfg.Profiles[0].PluginConfig[1].Args.Raw))
+}
+
+func TestLoadWasmPlugin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		guestURL string
+		wantErr  bool
+	}{
+		{
+			name:     "guest exporting the plugin functions",
+			guestURL: wasmtest.URLErrorPanicOnFilter,
+		},
+		{
+			name:     "guest not exporting the plugin functions",
+			guestURL: wasmtest.URLErrorNotPlugin,
+			wantErr:  true,
+		},
+		{
+			name:     "guest not found",
+			guestURL: "file:///no/such/plugin.wasm",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := LoadWasmPlugin(context.Background(), "wasmPlugin", wasm.PluginConfig{GuestURL: tt.guestURL})
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
 		})
 	}
 }
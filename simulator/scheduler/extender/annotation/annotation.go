@@ -9,4 +9,6 @@ const (
 	ExtenderPreemptResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/extender-preempt-result"
 	// ExtenderBindResultAnnotationKey has the binding result of extender.
 	ExtenderBindResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/extender-bind-result"
+	// ExtenderDecisionsAnnotationKey has whether each extender is consulted for the Pod, and how the Nodes are passed to it.
+	ExtenderDecisionsAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/extender-decisions"
 )
@@ -0,0 +1,127 @@
+package extender
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+)
+
+// DefaultCallLogSize is the number of the calls CallLog keeps by default.
+const DefaultCallLogSize = 1000
+
+// Call is a request sent to an extender and what it got back.
+// It's recorded even when the request fails, e.g., by a timeout or a non-200 status.
+type Call struct {
+	// Extender is the URL prefix of the extender.
+	Extender string `json:"extender"`
+	// Verb is the verb of the request, e.g., filterVerb in the extender config.
+	Verb      string `json:"verb"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	// Request is the body sent to the extender.
+	Request json.RawMessage `json:"request,omitempty"`
+	// StatusCode is 0 when no response is returned.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Response is the body returned from the extender when it's a valid JSON.
+	Response json.RawMessage `json:"response,omitempty"`
+	// RawResponse is the body returned from the extender when it isn't a valid JSON.
+	RawResponse string `json:"rawResponse,omitempty"`
+	// Error is the error of the call, including the one decoding the response.
+	Error string `json:"error,omitempty"`
+	// Fault is the type of the fault injected into the response to the scheduler.
+	// The call with it is recorded in addition to the call to the extender, which isn't sent for Timeout and ServerError.
+	Fault     string    `json:"fault,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	// Latency is the round-trip time of the request, e.g., "12.3ms".
+	Latency string `json:"latency"`
+}
+
+// CallLog keeps the latest calls to the extenders.
+// The oldest call is dropped when the number of the calls exceeds the size.
+type CallLog struct {
+	mu    sync.Mutex
+	size  int
+	calls []Call
+}
+
+// NewCallLog initializes CallLog keeping the latest size calls.
+func NewCallLog(size int) *CallLog {
+	return &CallLog{size: size, calls: make([]Call, 0, size)}
+}
+
+func (l *CallLog) add(c Call) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size <= 0 {
+		return
+	}
+	if len(l.calls) >= l.size {
+		l.calls = append(l.calls[:0], l.calls[len(l.calls)-l.size+1:]...)
+	}
+	l.calls = append(l.calls, c)
+}
+
+// record adds the call to the extender with the name.
+func (l *CallLog) record(extenderName, verb string, args interface{}, request []byte, statusCode int, response []byte, err error, start time.Time) {
+	l.add(newCall(extenderName, verb, args, request, statusCode, response, err, start))
+}
+
+func newCall(extenderName, verb string, args interface{}, request []byte, statusCode int, response []byte, err error, start time.Time) Call {
+	namespace, podName := podOf(args)
+	c := Call{
+		Extender:   extenderName,
+		Verb:       verb,
+		Namespace:  namespace,
+		Pod:        podName,
+		Request:    request,
+		StatusCode: statusCode,
+		StartTime:  start,
+		Latency:    time.Since(start).String(),
+	}
+	if json.Valid(response) {
+		c.Response = response
+	} else if len(response) != 0 {
+		c.RawResponse = string(response)
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+// List returns the calls for the Pod in the order they're sent.
+// The calls for all Pods are returned when podName is empty, or for all Pods in the namespace when only namespace is given.
+func (l *CallLog) List(namespace, podName string) []Call {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ret := []Call{}
+	for _, c := range l.calls {
+		if namespace != "" && c.Namespace != namespace {
+			continue
+		}
+		if podName != "" && c.Pod != podName {
+			continue
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// podOf returns the namespace and the name of the Pod the extender args is for.
+func podOf(args interface{}) (string, string) {
+	switch a := args.(type) {
+	case extenderv1.ExtenderArgs:
+		if a.Pod != nil {
+			return a.Pod.Namespace, a.Pod.Name
+		}
+	case extenderv1.ExtenderPreemptionArgs:
+		if a.Pod != nil {
+			return a.Pod.Namespace, a.Pod.Name
+		}
+	case extenderv1.ExtenderBindingArgs:
+		return a.PodNamespace, a.PodName
+	}
+	return "", ""
+}
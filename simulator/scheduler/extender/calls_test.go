@@ -0,0 +1,113 @@
+package extender
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+)
+
+func TestExtender_send_RecordsCalls(t *testing.T) {
+	t.Parallel()
+	args := extenderv1.ExtenderArgs{
+		Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
+	}
+	tests := []struct {
+		name            string
+		doFn            func(req *http.Request) (*http.Response, error)
+		wantStatusCode  int
+		wantResponse    json.RawMessage
+		wantRawResponse string
+		wantErr         bool
+	}{
+		{
+			name: "record the response of the successful call",
+			doFn: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[{"Host":"node1","Score":1}]`))}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   json.RawMessage(`[{"Host":"node1","Score":1}]`),
+		},
+		{
+			name: "record the body of the non-200 response",
+			doFn: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader("internal error"))}, nil
+			},
+			wantStatusCode:  http.StatusInternalServerError,
+			wantRawResponse: "internal error",
+			wantErr:         true,
+		},
+		{
+			name: "record the malformed response",
+			doFn: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"Host":"node1"}`))}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   json.RawMessage(`{"Host":"node1"}`),
+			wantErr:        true,
+		},
+		{
+			name: "record the error of the request",
+			doFn: func(_ *http.Request) (*http.Response, error) {
+				return nil, xerrors.New("timeout")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			calls := NewCallLog(DefaultCallLogSize)
+			e := &extender{
+				extenderURL: "http://example.com/extender",
+				client:      &MockHTTPClient{DoFunc: tt.doFn},
+				calls:       calls,
+			}
+			var result extenderv1.HostPriorityList
+			err := e.send("prioritize", args, &result)
+			require.Equal(t, tt.wantErr, err != nil)
+
+			got := calls.List("default", "pod1")
+			require.Len(t, got, 1)
+			assert.Equal(t, "http://example.com/extender", got[0].Extender)
+			assert.Equal(t, "prioritize", got[0].Verb)
+			assert.Equal(t, tt.wantStatusCode, got[0].StatusCode)
+			assert.Equal(t, tt.wantResponse, got[0].Response)
+			assert.Equal(t, tt.wantRawResponse, got[0].RawResponse)
+			assert.Equal(t, tt.wantErr, got[0].Error != "")
+			assert.NotEmpty(t, got[0].Latency)
+
+			var req extenderv1.ExtenderArgs
+			require.NoError(t, json.Unmarshal(got[0].Request, &req))
+			assert.Equal(t, "pod1", req.Pod.Name)
+		})
+	}
+}
+
+func TestCallLog_List(t *testing.T) {
+	t.Parallel()
+	l := NewCallLog(3)
+	l.add(Call{Namespace: "default", Pod: "pod1", Verb: "filter"})
+	l.add(Call{Namespace: "default", Pod: "pod2", Verb: "filter"})
+	l.add(Call{Namespace: "default", Pod: "pod1", Verb: "prioritize"})
+	l.add(Call{Namespace: "other", Pod: "pod1", Verb: "bind"})
+
+	// The oldest call is dropped.
+	assert.Equal(t, []Call{
+		{Namespace: "default", Pod: "pod2", Verb: "filter"},
+		{Namespace: "default", Pod: "pod1", Verb: "prioritize"},
+		{Namespace: "other", Pod: "pod1", Verb: "bind"},
+	}, l.List("", ""))
+	assert.Equal(t, []Call{{Namespace: "default", Pod: "pod1", Verb: "prioritize"}}, l.List("default", "pod1"))
+	assert.Len(t, l.List("default", ""), 2)
+	assert.Empty(t, l.List("default", "pod3"))
+}
@@ -0,0 +1,73 @@
+package extender
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/resultstore"
+)
+
+// recordDecisions records whether the scheduler consults each extender for the Pod.
+// The extender with id is the one called with the Nodes by mode, which is empty when it's called without them.
+//
+// The scheduler doesn't send any request for the extenders not interested in the Pod,
+// so they're told from the managedResources in the extender configs here.
+func (s *Service) recordDecisions(id int, pod *v1.Pod, mode resultstore.CallMode) {
+	if pod == nil {
+		return
+	}
+	for i := range s.configs {
+		cfg := &s.configs[i]
+		d := resultstore.Decision{Interested: isInterested(cfg, pod)}
+		if !d.Interested {
+			d.Reason = fmt.Sprintf("the Pod requests none of the managed resources: %s", strings.Join(managedResourceNames(cfg), ", "))
+		}
+		if i == id {
+			d.CallMode = mode
+		}
+		s.store.AddDecision(pod.Namespace, pod.Name, d, cfg.URLPrefix)
+	}
+}
+
+// isInterested returns whether the extender is interested in the Pod in the same way as the scheduler does.
+// https://github.com/kubernetes/kubernetes/blob/v1.32.5/pkg/scheduler/extender.go#L428
+func isInterested(cfg *configv1.Extender, pod *v1.Pod) bool {
+	if len(cfg.ManagedResources) == 0 {
+		return true
+	}
+	return hasManagedResources(cfg, pod.Spec.Containers) || hasManagedResources(cfg, pod.Spec.InitContainers)
+}
+
+func hasManagedResources(cfg *configv1.Extender, containers []v1.Container) bool {
+	for _, r := range cfg.ManagedResources {
+		name := v1.ResourceName(r.Name)
+		for i := range containers {
+			if _, ok := containers[i].Resources.Requests[name]; ok {
+				return true
+			}
+			if _, ok := containers[i].Resources.Limits[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func managedResourceNames(cfg *configv1.Extender) []string {
+	names := make([]string, 0, len(cfg.ManagedResources))
+	for _, r := range cfg.ManagedResources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// callModeOf returns how the Nodes are passed in the args.
+func callModeOf(nodeNames *[]string) resultstore.CallMode {
+	if nodeNames != nil {
+		return resultstore.CallModeNodeNames
+	}
+	return resultstore.CallModeNodes
+}
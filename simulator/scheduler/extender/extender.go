@@ -5,6 +5,7 @@ package extender
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -12,10 +13,13 @@ import (
 	"golang.org/x/xerrors"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
 )
 
 const (
@@ -49,6 +53,9 @@ type extender struct {
 
 	// https://github.com/kubernetes/kubernetes/blob/fc04e732bb3e7198d2fa44efa5457c7c6f8c0f5b/pkg/scheduler/extender.go#L51
 	managedResources sets.Set[string]
+
+	// calls records the requests sent to the extender. It's nil when they aren't recorded.
+	calls *CallLog
 }
 
 // makeTransport makes http.Transport from the extender config.
@@ -83,7 +90,8 @@ func makeTransport(config *configv1.Extender) (http.RoundTripper, error) {
 }
 
 // newExtender creates an Extender object.
-func newExtender(config *configv1.Extender) (Extender, error) {
+// The requests are recorded in calls unless it's nil.
+func newExtender(config *configv1.Extender, calls *CallLog) (Extender, error) {
 	if config.HTTPTimeout.Duration.Nanoseconds() == 0 {
 		config.HTTPTimeout.Duration = DefaultExtenderTimeout
 	}
@@ -110,6 +118,7 @@ func newExtender(config *configv1.Extender) (Extender, error) {
 		client:           client,
 		nodeCacheCapable: config.NodeCacheCapable,
 		managedResources: managedResources,
+		calls:            calls,
 	}, nil
 }
 
@@ -172,7 +181,7 @@ func (e *extender) Bind(args extenderv1.ExtenderBindingArgs) (*extenderv1.Extend
 }
 
 // Send is Helper function to send messages to the extender.
-func (e *extender) send(action string, args interface{}, result interface{}) error {
+func (e *extender) send(action string, args interface{}, result interface{}) (err error) {
 	out, err := json.Marshal(args)
 	if err != nil {
 		return xerrors.Errorf("json Marshal: %w", err)
@@ -186,26 +195,56 @@ func (e *extender) send(action string, args interface{}, result interface{}) err
 
 	req.Header.Set("Content-Type", "application/json")
 
+	var (
+		statusCode int
+		body       []byte
+	)
+	if e.calls != nil {
+		start := time.Now()
+		defer func() {
+			e.calls.record(e.extenderURL, action, args, out, statusCode, body, err, start)
+		}()
+	}
+
 	resp, err := e.client.Do(req)
 	if err != nil {
 		return xerrors.Errorf("client Do: %w", err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
+	// The body is read even for the failed requests, so that it's recorded.
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("read response body: %w", err)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return xerrors.Errorf("failed %v with extender at URL %v, code %v", action, url, resp.StatusCode)
 	}
-	return json.NewDecoder(resp.Body).Decode(result)
+	if err = json.Unmarshal(body, result); err != nil {
+		return xerrors.Errorf("decode response: %w", err)
+	}
+	return nil
 }
 
 // createExtenders creates Extender that represents actual extender's endpoint based on the config set by user.
-func createExtenders(configs []configv1.Extender) ([]Extender, error) {
+// The extenders whose URL prefix has the MockExtender in mocks are replaced with the mock ones.
+// The requests are recorded in calls unless it's nil.
+func createExtenders(configs []configv1.Extender, mocks []v1alpha1.MockExtender, client clientset.Interface, calls *CallLog) ([]Extender, error) {
 	if len(configs) == 0 {
 		return nil, nil
 	}
 	extenders := make([]Extender, len(configs))
 	for i := range configs {
-		e, err := newExtender(&configs[i])
+		if mock := findMockExtender(mocks, configs[i].URLPrefix); mock != nil {
+			e, err := newMockExtender(&configs[i], mock, client, calls)
+			if err != nil {
+				return nil, xerrors.Errorf("failed newMockExtender: %w", err)
+			}
+			extenders[i] = e
+			continue
+		}
+		e, err := newExtender(&configs[i], calls)
 		if err != nil {
 			return nil, xerrors.Errorf("failed newExtender: %w", err)
 		}
@@ -213,3 +252,12 @@ func createExtenders(configs []configv1.Extender) ([]Extender, error) {
 	}
 	return extenders, nil
 }
+
+func findMockExtender(mocks []v1alpha1.MockExtender, urlPrefix string) *v1alpha1.MockExtender {
+	for i := range mocks {
+		if mocks[i].URLPrefix == urlPrefix {
+			return &mocks[i]
+		}
+	}
+	return nil
+}
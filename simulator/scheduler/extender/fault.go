@@ -0,0 +1,214 @@
+package extender
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"k8s.io/klog/v2"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+)
+
+// FaultResponse is returned from Service as the error when the fault is injected.
+// The proxy server responds to the scheduler with it as is, instead of the result of the extender.
+type FaultResponse struct {
+	Fault       v1alpha1.ExtenderFaultType
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (r *FaultResponse) Error() string {
+	return fmt.Sprintf("injected %s fault with status %d", r.Fault, r.StatusCode)
+}
+
+// faultInjector decides the faults injected into the responses for the extenders.
+type faultInjector struct {
+	faults []v1alpha1.ExtenderFault
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	// sleep is replaced in the tests.
+	sleep func(time.Duration)
+}
+
+func newFaultInjector(faults []v1alpha1.ExtenderFault) *faultInjector {
+	return &faultInjector{
+		faults: faults,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec // The faults don't need a secure random number.
+		sleep:  time.Sleep,
+	}
+}
+
+// pick rolls the faults matching the request in order, and returns the first one hit.
+// It returns nil when no fault is injected.
+func (f *faultInjector) pick(urlPrefix, verb string) *v1alpha1.ExtenderFault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.faults {
+		fault := &f.faults[i]
+		if fault.URLPrefix != "" && fault.URLPrefix != urlPrefix {
+			continue
+		}
+		if len(fault.Verbs) != 0 && !slices.Contains(fault.Verbs, verb) {
+			continue
+		}
+		if f.rand.Float64() < fault.Rate {
+			return fault
+		}
+	}
+	return nil
+}
+
+// ValidateExtenderFaults validates the ExtenderFaults.
+func ValidateExtenderFaults(faults []v1alpha1.ExtenderFault) error {
+	for i := range faults {
+		if err := ValidateExtenderFault(&faults[i]); err != nil {
+			return xerrors.Errorf("extenderFaults[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateExtenderFault validates the ExtenderFault.
+func ValidateExtenderFault(fault *v1alpha1.ExtenderFault) error {
+	switch fault.Type {
+	case v1alpha1.ExtenderFaultTimeout, v1alpha1.ExtenderFaultServerError, v1alpha1.ExtenderFaultPartialNodes,
+		v1alpha1.ExtenderFaultSlow, v1alpha1.ExtenderFaultMalformedResponse:
+	default:
+		return xerrors.Errorf("unknown fault type %q", fault.Type)
+	}
+	if fault.Rate <= 0 || fault.Rate > 1 {
+		return xerrors.Errorf("rate must be greater than 0 and at most 1, but got %v", fault.Rate)
+	}
+	for _, v := range fault.Verbs {
+		switch v {
+		case filterVerb, prioritizeVerb, preemptVerb, bindVerb:
+		default:
+			return xerrors.Errorf("unknown verb %q", v)
+		}
+	}
+	if fault.StatusCode != 0 && (fault.StatusCode < 400 || fault.StatusCode > 599) {
+		return xerrors.Errorf("statusCode must be 4xx or 5xx, but got %d", fault.StatusCode)
+	}
+	if fault.Delay.Duration < 0 {
+		return xerrors.New("delay must not be negative")
+	}
+	return nil
+}
+
+// injectBeforeCall injects the fault picked for the request before calling the extender.
+// It returns the FaultResponse for the faults responding without calling the extender,
+// or the fault to be injected into the result of the extender.
+func (s *Service) injectBeforeCall(id int, verb string, args interface{}) (*v1alpha1.ExtenderFault, error) {
+	if s.faults == nil {
+		return nil, nil
+	}
+	cfg := &s.configs[id]
+	fault := s.faults.pick(cfg.URLPrefix, verb)
+	if fault == nil {
+		return nil, nil
+	}
+	start := time.Now()
+	switch fault.Type {
+	case v1alpha1.ExtenderFaultSlow:
+		s.faults.sleep(fault.Delay.Duration)
+	case v1alpha1.ExtenderFaultTimeout:
+		delay := fault.Delay.Duration
+		if delay == 0 {
+			delay = httpTimeout(cfg) + time.Second
+		}
+		s.faults.sleep(delay)
+		return nil, s.faultResponse(id, verb, args, fault, http.StatusGatewayTimeout, nil, start)
+	case v1alpha1.ExtenderFaultServerError:
+		statusCode := fault.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusInternalServerError
+		}
+		return nil, s.faultResponse(id, verb, args, fault, statusCode, []byte(fmt.Sprintf("injected %s fault", fault.Type)), start)
+	}
+	return fault, nil
+}
+
+// injectMalformedResponse responds with the truncated JSON of the result.
+func (s *Service) injectMalformedResponse(id int, verb string, args interface{}, fault *v1alpha1.ExtenderFault, result interface{}) error {
+	start := time.Now()
+	b, err := json.Marshal(result)
+	if err != nil {
+		return xerrors.Errorf("encode the result to inject %s: %w", fault.Type, err)
+	}
+	return s.faultResponse(id, verb, args, fault, http.StatusOK, b[:len(b)/2], start)
+}
+
+// faultResponse records the fault in the CallLog, and returns it as FaultResponse.
+func (s *Service) faultResponse(id int, verb string, args interface{}, fault *v1alpha1.ExtenderFault, statusCode int, body []byte, start time.Time) *FaultResponse {
+	cfg := &s.configs[id]
+	klog.Infof("inject %s fault into the %s response of extender %s", fault.Type, verb, cfg.URLPrefix)
+	s.recordFault(cfg, verb, args, fault, statusCode, body, start)
+
+	contentType := "text/plain"
+	if statusCode == http.StatusOK {
+		contentType = "application/json"
+	}
+	return &FaultResponse{Fault: fault.Type, StatusCode: statusCode, ContentType: contentType, Body: body}
+}
+
+// recordPartialNodes records the result whose Nodes are dropped by PartialNodes.
+func (s *Service) recordPartialNodes(id int, verb string, args interface{}, fault *v1alpha1.ExtenderFault, result interface{}) {
+	cfg := &s.configs[id]
+	klog.Infof("inject %s fault into the %s response of extender %s", fault.Type, verb, cfg.URLPrefix)
+	b, _ := json.Marshal(result)
+	s.recordFault(cfg, verb, args, fault, http.StatusOK, b, time.Now())
+}
+
+// recordFault records the response with the fault in the CallLog.
+// It's recorded in addition to the call to the extender, if any.
+func (s *Service) recordFault(cfg *configv1.Extender, verb string, args interface{}, fault *v1alpha1.ExtenderFault, statusCode int, response []byte, start time.Time) {
+	if s.calls == nil {
+		return
+	}
+	request, _ := json.Marshal(args)
+	c := newCall(cfg.URLPrefix, configuredVerb(cfg, verb), args, request, statusCode, response, nil, start)
+	c.Fault = string(fault.Type)
+	s.calls.add(c)
+}
+
+// dropLatterHalfNodes drops the latter half of the Nodes in the filter result.
+func dropLatterHalfNodes(result *extenderv1.ExtenderFilterResult) {
+	if result.Nodes != nil {
+		result.Nodes.Items = result.Nodes.Items[:len(result.Nodes.Items)/2]
+	}
+	if result.NodeNames != nil {
+		names := (*result.NodeNames)[:len(*result.NodeNames)/2]
+		result.NodeNames = &names
+	}
+}
+
+func configuredVerb(cfg *configv1.Extender, verb string) string {
+	switch verb {
+	case filterVerb:
+		return cfg.FilterVerb
+	case prioritizeVerb:
+		return cfg.PrioritizeVerb
+	case preemptVerb:
+		return cfg.PreemptVerb
+	case bindVerb:
+		return cfg.BindVerb
+	}
+	return verb
+}
+
+func httpTimeout(cfg *configv1.Extender) time.Duration {
+	if cfg.HTTPTimeout.Duration == 0 {
+		return DefaultExtenderTimeout
+	}
+	return cfg.HTTPTimeout.Duration
+}
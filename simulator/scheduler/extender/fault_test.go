@@ -0,0 +1,178 @@
+package extender
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/mock_extender"
+)
+
+func TestService_Prioritize_Faults(t *testing.T) {
+	t.Parallel()
+	hosts := extenderv1.HostPriorityList{{Host: "node1", Score: 1}, {Host: "node2", Score: 2}}
+	tests := []struct {
+		name                     string
+		fault                    v1alpha1.ExtenderFault
+		prepareMockExtenderSetFn func(m *mock_extender.MockExtender)
+		prepareMockStoreSetFn    func(m *mock_extender.MockStore)
+		wantResult               *extenderv1.HostPriorityList
+		wantFault                *FaultResponse
+		wantSlept                time.Duration
+	}{
+		{
+			name:  "respond with 504 after the http timeout without calling the extender",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultTimeout, Rate: 1},
+			prepareMockExtenderSetFn: func(_ *mock_extender.MockExtender) {
+			},
+			prepareMockStoreSetFn: func(_ *mock_extender.MockStore) {
+			},
+			wantFault: &FaultResponse{Fault: v1alpha1.ExtenderFaultTimeout, StatusCode: http.StatusGatewayTimeout, ContentType: "text/plain"},
+			wantSlept: 3 * time.Second,
+		},
+		{
+			name:  "respond with the status code without calling the extender",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultServerError, Rate: 1, StatusCode: http.StatusServiceUnavailable},
+			prepareMockExtenderSetFn: func(_ *mock_extender.MockExtender) {
+			},
+			prepareMockStoreSetFn: func(_ *mock_extender.MockStore) {
+			},
+			wantFault: &FaultResponse{Fault: v1alpha1.ExtenderFaultServerError, StatusCode: http.StatusServiceUnavailable, ContentType: "text/plain", Body: []byte("injected ServerError fault")},
+		},
+		{
+			name:  "drop the latter half of the Nodes",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultPartialNodes, Rate: 1},
+			prepareMockExtenderSetFn: func(m *mock_extender.MockExtender) {
+				result := append(extenderv1.HostPriorityList{}, hosts...)
+				m.EXPECT().Prioritize(extenderv1.ExtenderArgs{}).Return(&result, nil)
+				m.EXPECT().Name().Return("ext1")
+			},
+			prepareMockStoreSetFn: func(m *mock_extender.MockStore) {
+				m.EXPECT().AddPrioritizeResult(extenderv1.ExtenderArgs{}, hosts[:1], "ext1")
+			},
+			wantResult: &extenderv1.HostPriorityList{{Host: "node1", Score: 1}},
+		},
+		{
+			name:  "delay the response",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultSlow, Rate: 1, Delay: metav1.Duration{Duration: time.Second}},
+			prepareMockExtenderSetFn: func(m *mock_extender.MockExtender) {
+				result := append(extenderv1.HostPriorityList{}, hosts...)
+				m.EXPECT().Prioritize(extenderv1.ExtenderArgs{}).Return(&result, nil)
+				m.EXPECT().Name().Return("ext1")
+			},
+			prepareMockStoreSetFn: func(m *mock_extender.MockStore) {
+				m.EXPECT().AddPrioritizeResult(extenderv1.ExtenderArgs{}, hosts, "ext1")
+			},
+			wantResult: &hosts,
+			wantSlept:  time.Second,
+		},
+		{
+			name:  "respond with the truncated JSON",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultMalformedResponse, Rate: 1},
+			prepareMockExtenderSetFn: func(m *mock_extender.MockExtender) {
+				result := append(extenderv1.HostPriorityList{}, hosts...)
+				m.EXPECT().Prioritize(extenderv1.ExtenderArgs{}).Return(&result, nil)
+			},
+			prepareMockStoreSetFn: func(_ *mock_extender.MockStore) {
+			},
+			wantFault: &FaultResponse{Fault: v1alpha1.ExtenderFaultMalformedResponse, StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte(`[{"Host":"node1","Score":1}`)},
+		},
+		{
+			name:  "ignore the fault for other verbs",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultServerError, Rate: 1, Verbs: []string{"filter"}},
+			prepareMockExtenderSetFn: func(m *mock_extender.MockExtender) {
+				result := append(extenderv1.HostPriorityList{}, hosts...)
+				m.EXPECT().Prioritize(extenderv1.ExtenderArgs{}).Return(&result, nil)
+				m.EXPECT().Name().Return("ext1")
+			},
+			prepareMockStoreSetFn: func(m *mock_extender.MockStore) {
+				m.EXPECT().AddPrioritizeResult(extenderv1.ExtenderArgs{}, hosts, "ext1")
+			},
+			wantResult: &hosts,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mStore := mock_extender.NewMockStore(ctrl)
+			mExtender := mock_extender.NewMockExtender(ctrl)
+			tt.prepareMockStoreSetFn(mStore)
+			tt.prepareMockExtenderSetFn(mExtender)
+
+			var slept time.Duration
+			faults := newFaultInjector([]v1alpha1.ExtenderFault{tt.fault})
+			faults.sleep = func(d time.Duration) { slept += d }
+			s := &Service{
+				extenders: []Extender{mExtender},
+				store:     mStore,
+				configs:   []configv1.Extender{{URLPrefix: "http://extender/", PrioritizeVerb: "prioritize", HTTPTimeout: metav1.Duration{Duration: 2 * time.Second}}},
+				calls:     NewCallLog(DefaultCallLogSize),
+				faults:    faults,
+			}
+			got, err := s.Prioritize(0, extenderv1.ExtenderArgs{})
+			assert.Equal(t, tt.wantSlept, slept)
+			if tt.wantFault != nil {
+				var fault *FaultResponse
+				require.ErrorAs(t, err, &fault)
+				assert.Equal(t, tt.wantFault, fault)
+
+				calls := s.Calls("", "")
+				require.Len(t, calls, 1)
+				assert.Equal(t, string(tt.fault.Type), calls[0].Fault)
+				assert.Equal(t, tt.wantFault.StatusCode, calls[0].StatusCode)
+				assert.True(t, json.Valid(calls[0].Request))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, got)
+		})
+	}
+}
+
+func TestValidateExtenderFault(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		fault   v1alpha1.ExtenderFault
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			fault: v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultServerError, Rate: 0.5, Verbs: []string{"bind"}, StatusCode: 503},
+		},
+		{
+			name:    "unknown type",
+			fault:   v1alpha1.ExtenderFault{Type: "Unknown", Rate: 1},
+			wantErr: true,
+		},
+		{
+			name:    "zero rate",
+			fault:   v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultSlow},
+			wantErr: true,
+		},
+		{
+			name:    "success status code",
+			fault:   v1alpha1.ExtenderFault{Type: v1alpha1.ExtenderFaultServerError, Rate: 1, StatusCode: 200},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateExtenderFault(&tt.fault)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	gomock "go.uber.org/mock/gomock"
 	v1 "k8s.io/api/core/v1"
 	v10 "k8s.io/kube-scheduler/extender/v1"
+	resultstore "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/resultstore"
 )
 
 // MockStore is a mock of Store interface.
@@ -53,6 +54,18 @@ func (mr *MockStoreMockRecorder) AddBindResult(args, result, hostName any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBindResult", reflect.TypeOf((*MockStore)(nil).AddBindResult), args, result, hostName)
 }
 
+// AddDecision mocks base method.
+func (m *MockStore) AddDecision(namespace, podName string, decision resultstore.Decision, hostName string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddDecision", namespace, podName, decision, hostName)
+}
+
+// AddDecision indicates an expected call of AddDecision.
+func (mr *MockStoreMockRecorder) AddDecision(namespace, podName, decision, hostName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDecision", reflect.TypeOf((*MockStore)(nil).AddDecision), namespace, podName, decision, hostName)
+}
+
 // AddFilterResult mocks base method.
 func (m *MockStore) AddFilterResult(args v10.ExtenderArgs, result v10.ExtenderFilterResult, hostName string) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,361 @@
+package extender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+)
+
+const (
+	filterVerb     = "filter"
+	prioritizeVerb = "prioritize"
+	preemptVerb    = "preempt"
+	bindVerb       = "bind"
+)
+
+// mockExtender answers the requests by the rules in v1alpha1.MockExtender instead of sending them to the extender server.
+// It's configured with the same KubeSchedulerConfiguration as the extender server it replaces.
+type mockExtender struct {
+	config configv1.Extender
+	client clientset.Interface
+	// calls records the requests answered by the extender. It's nil when they aren't recorded.
+	calls *CallLog
+
+	latency    time.Duration
+	filter     []mockFilterRule
+	prioritize []mockPrioritizeRule
+	failures   []mockFailureRule
+}
+
+type mockFilterRule struct {
+	pod, node    labels.Selector
+	reason       string
+	unresolvable bool
+}
+
+type mockPrioritizeRule struct {
+	pod, node labels.Selector
+	score     int64
+}
+
+type mockFailureRule struct {
+	// verbs is empty when the rule matches all the verbs.
+	verbs map[string]bool
+	pod   labels.Selector
+	err   string
+}
+
+// newMockExtender creates the Extender answering by the rules in mock.
+// client is used to get the labels of the Nodes given by their names, and to bind Pods.
+func newMockExtender(config *configv1.Extender, mock *v1alpha1.MockExtender, client clientset.Interface, calls *CallLog) (Extender, error) {
+	if err := ValidateMockExtender(mock); err != nil {
+		return nil, xerrors.Errorf("validate mock extender %s: %w", mock.URLPrefix, err)
+	}
+	e := &mockExtender{
+		config:  *config,
+		client:  client,
+		calls:   calls,
+		latency: mock.Latency.Duration,
+	}
+	for _, r := range mock.Filter {
+		pod, _ := selector(r.PodSelector)
+		node, _ := selector(r.NodeSelector)
+		e.filter = append(e.filter, mockFilterRule{pod: pod, node: node, reason: r.Reason, unresolvable: r.Unresolvable})
+	}
+	for _, r := range mock.Prioritize {
+		pod, _ := selector(r.PodSelector)
+		node, _ := selector(r.NodeSelector)
+		e.prioritize = append(e.prioritize, mockPrioritizeRule{pod: pod, node: node, score: r.Score})
+	}
+	for _, r := range mock.Failures {
+		pod, _ := selector(r.PodSelector)
+		verbs := map[string]bool{}
+		for _, v := range r.Verbs {
+			verbs[v] = true
+		}
+		e.failures = append(e.failures, mockFailureRule{verbs: verbs, pod: pod, err: r.Error})
+	}
+	return e, nil
+}
+
+// ValidateMockExtenders validates the MockExtenders.
+// The URL prefixes must be unique because they decide which extenders are replaced.
+func ValidateMockExtenders(mocks []v1alpha1.MockExtender) error {
+	urlPrefixes := map[string]bool{}
+	for i := range mocks {
+		if err := ValidateMockExtender(&mocks[i]); err != nil {
+			return xerrors.Errorf("mockExtenders[%d]: %w", i, err)
+		}
+		if urlPrefixes[mocks[i].URLPrefix] {
+			return xerrors.Errorf("mock extender for %s is defined twice", mocks[i].URLPrefix)
+		}
+		urlPrefixes[mocks[i].URLPrefix] = true
+	}
+	return nil
+}
+
+// ValidateMockExtender validates the rules of the MockExtender.
+func ValidateMockExtender(mock *v1alpha1.MockExtender) error {
+	if mock.URLPrefix == "" {
+		return xerrors.New("urlPrefix is required")
+	}
+	for i, r := range mock.Filter {
+		if _, err := selector(r.PodSelector); err != nil {
+			return xerrors.Errorf("podSelector of filter[%d]: %w", i, err)
+		}
+		if _, err := selector(r.NodeSelector); err != nil {
+			return xerrors.Errorf("nodeSelector of filter[%d]: %w", i, err)
+		}
+	}
+	for i, r := range mock.Prioritize {
+		if _, err := selector(r.PodSelector); err != nil {
+			return xerrors.Errorf("podSelector of prioritize[%d]: %w", i, err)
+		}
+		if _, err := selector(r.NodeSelector); err != nil {
+			return xerrors.Errorf("nodeSelector of prioritize[%d]: %w", i, err)
+		}
+		if r.Score < extenderv1.MinExtenderPriority || r.Score > extenderv1.MaxExtenderPriority {
+			return xerrors.Errorf("score of prioritize[%d] must be between %d and %d", i, extenderv1.MinExtenderPriority, extenderv1.MaxExtenderPriority)
+		}
+	}
+	for i, r := range mock.Failures {
+		if _, err := selector(r.PodSelector); err != nil {
+			return xerrors.Errorf("podSelector of failures[%d]: %w", i, err)
+		}
+		for _, v := range r.Verbs {
+			switch v {
+			case filterVerb, prioritizeVerb, preemptVerb, bindVerb:
+			default:
+				return xerrors.Errorf("unknown verb %q in failures[%d]", v, i)
+			}
+		}
+	}
+	return nil
+}
+
+// selector converts the LabelSelector. The nil selector matches everything.
+func selector(s *metav1.LabelSelector) (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(s)
+}
+
+// Name returns the URL prefix of the extender it replaces.
+func (e *mockExtender) Name() string {
+	return e.config.URLPrefix
+}
+
+// Filter rejects the Nodes matching the filter rules for the Pod.
+// The result has the Nodes or their names in the same way as the request.
+func (e *mockExtender) Filter(args extenderv1.ExtenderArgs) (*extenderv1.ExtenderFilterResult, error) {
+	var result *extenderv1.ExtenderFilterResult
+	err := e.answer(e.config.FilterVerb, filterVerb, args.Pod, args, func() (interface{}, error) {
+		nodes, err := e.nodes(args)
+		if err != nil {
+			return nil, err
+		}
+		result = &extenderv1.ExtenderFilterResult{
+			FailedNodes:                extenderv1.FailedNodesMap{},
+			FailedAndUnresolvableNodes: extenderv1.FailedNodesMap{},
+		}
+		passed := make([]v1.Node, 0, len(nodes))
+		for _, n := range nodes {
+			r := e.matchFilterRule(args.Pod, &n)
+			switch {
+			case r == nil:
+				passed = append(passed, n)
+			case r.unresolvable:
+				result.FailedAndUnresolvableNodes[n.Name] = r.reason
+			default:
+				result.FailedNodes[n.Name] = r.reason
+			}
+		}
+		if args.Nodes != nil {
+			result.Nodes = &v1.NodeList{Items: passed}
+		} else {
+			names := make([]string, 0, len(passed))
+			for _, n := range passed {
+				names = append(names, n.Name)
+			}
+			result.NodeNames = &names
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("filter by mock extender: %w", err)
+	}
+	return result, nil
+}
+
+// Prioritize scores the Nodes by the first prioritize rule they match for the Pod.
+func (e *mockExtender) Prioritize(args extenderv1.ExtenderArgs) (*extenderv1.HostPriorityList, error) {
+	var result extenderv1.HostPriorityList
+	err := e.answer(e.config.PrioritizeVerb, prioritizeVerb, args.Pod, args, func() (interface{}, error) {
+		nodes, err := e.nodes(args)
+		if err != nil {
+			return nil, err
+		}
+		result = make(extenderv1.HostPriorityList, 0, len(nodes))
+		for _, n := range nodes {
+			result = append(result, extenderv1.HostPriority{Host: n.Name, Score: e.score(args.Pod, &n)})
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("prioritize by mock extender: %w", err)
+	}
+	for i := range result {
+		// Scale the score in the same way as extender.Prioritize does.
+		result[i].Score = result[i].Score * e.config.Weight * (framework.MaxNodeScore / extenderv1.MaxExtenderPriority)
+	}
+	return &result, nil
+}
+
+// Preempt accepts all the victims chosen by the scheduler.
+func (e *mockExtender) Preempt(args extenderv1.ExtenderPreemptionArgs) (*extenderv1.ExtenderPreemptionResult, error) {
+	var result *extenderv1.ExtenderPreemptionResult
+	err := e.answer(e.config.PreemptVerb, preemptVerb, args.Pod, args, func() (interface{}, error) {
+		result = &extenderv1.ExtenderPreemptionResult{NodeNameToMetaVictims: args.NodeNameToMetaVictims}
+		if args.NodeNameToVictims != nil {
+			result.NodeNameToMetaVictims = make(map[string]*extenderv1.MetaVictims, len(args.NodeNameToVictims))
+			for node, victims := range args.NodeNameToVictims {
+				mv := &extenderv1.MetaVictims{NumPDBViolations: victims.NumPDBViolations}
+				for _, p := range victims.Pods {
+					mv.Pods = append(mv.Pods, &extenderv1.MetaPod{UID: string(p.UID)})
+				}
+				result.NodeNameToMetaVictims[node] = mv
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("preempt by mock extender: %w", err)
+	}
+	return result, nil
+}
+
+// Bind binds the Pod to the Node as the scheduler does.
+func (e *mockExtender) Bind(args extenderv1.ExtenderBindingArgs) (*extenderv1.ExtenderBindingResult, error) {
+	pod, err := e.client.CoreV1().Pods(args.PodNamespace).Get(context.Background(), args.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("get pod %s/%s: %w", args.PodNamespace, args.PodName, err)
+	}
+	var result *extenderv1.ExtenderBindingResult
+	err = e.answer(e.config.BindVerb, bindVerb, pod, args, func() (interface{}, error) {
+		result = &extenderv1.ExtenderBindingResult{}
+		binding := &v1.Binding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: args.PodNamespace, Name: args.PodName, UID: args.PodUID},
+			Target:     v1.ObjectReference{Kind: "Node", Name: args.Node},
+		}
+		if err := e.client.CoreV1().Pods(args.PodNamespace).Bind(context.Background(), binding, metav1.CreateOptions{}); err != nil {
+			result.Error = err.Error()
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("bind by mock extender: %w", err)
+	}
+	return result, nil
+}
+
+// answer waits for the latency, and fails if a failure rule matches, or calls fn to answer the request.
+// The request and the answer are recorded as if they're sent to the extender server.
+func (e *mockExtender) answer(action, verb string, pod *v1.Pod, args interface{}, fn func() (interface{}, error)) (err error) {
+	start := time.Now()
+	var (
+		statusCode int
+		response   []byte
+	)
+	if e.calls != nil {
+		request, _ := json.Marshal(args)
+		defer func() {
+			e.calls.record(e.config.URLPrefix, action, args, request, statusCode, response, err, start)
+		}()
+	}
+
+	time.Sleep(e.latency)
+
+	if msg, failed := e.failure(verb, pod); failed {
+		statusCode = http.StatusInternalServerError
+		response = []byte(msg)
+		return xerrors.Errorf("failed %v with mock extender %v: %s", action, e.config.URLPrefix, msg)
+	}
+	result, err := fn()
+	if err != nil {
+		return err
+	}
+	statusCode = http.StatusOK
+	response, _ = json.Marshal(result)
+	return nil
+}
+
+// failure returns the error message of the first failure rule matching the request.
+func (e *mockExtender) failure(verb string, pod *v1.Pod) (string, bool) {
+	for _, r := range e.failures {
+		if len(r.verbs) != 0 && !r.verbs[verb] {
+			continue
+		}
+		if r.pod.Matches(podLabels(pod)) {
+			return r.err, true
+		}
+	}
+	return "", false
+}
+
+func (e *mockExtender) matchFilterRule(pod *v1.Pod, node *v1.Node) *mockFilterRule {
+	for i, r := range e.filter {
+		if r.pod.Matches(podLabels(pod)) && r.node.Matches(labels.Set(node.Labels)) {
+			return &e.filter[i]
+		}
+	}
+	return nil
+}
+
+func (e *mockExtender) score(pod *v1.Pod, node *v1.Node) int64 {
+	for _, r := range e.prioritize {
+		if r.pod.Matches(podLabels(pod)) && r.node.Matches(labels.Set(node.Labels)) {
+			return r.score
+		}
+	}
+	return 0
+}
+
+// nodes returns the Nodes in the request.
+// They're got from the cluster when only their names are given, i.e., the extender is nodeCacheCapable.
+func (e *mockExtender) nodes(args extenderv1.ExtenderArgs) ([]v1.Node, error) {
+	if args.Nodes != nil {
+		return args.Nodes.Items, nil
+	}
+	if args.NodeNames == nil {
+		return nil, nil
+	}
+	nodes := make([]v1.Node, 0, len(*args.NodeNames))
+	for _, name := range *args.NodeNames {
+		n, err := e.client.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, xerrors.Errorf("get node %s: %w", name, err)
+		}
+		nodes = append(nodes, *n)
+	}
+	return nodes, nil
+}
+
+func podLabels(pod *v1.Pod) labels.Set {
+	if pod == nil {
+		return labels.Set{}
+	}
+	return labels.Set(pod.Labels)
+}
@@ -0,0 +1,144 @@
+package extender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+)
+
+func TestMockExtender(t *testing.T) {
+	t.Parallel()
+
+	gpuNode := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-gpu", Labels: map[string]string{"gpu": "true"}}}
+	cpuNode := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-cpu"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", Labels: map[string]string{"app": "ml"}}}
+	otherPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default", Labels: map[string]string{"app": "broken"}}}
+	client := fake.NewSimpleClientset(&gpuNode, &cpuNode, pod, otherPod)
+
+	calls := NewCallLog(DefaultCallLogSize)
+	e, err := newMockExtender(&configv1.Extender{
+		URLPrefix:      "http://extender/",
+		FilterVerb:     "filter",
+		PrioritizeVerb: "prioritize",
+		BindVerb:       "bind",
+		Weight:         2,
+	}, &v1alpha1.MockExtender{
+		URLPrefix: "http://extender/",
+		Filter: []v1alpha1.MockExtenderFilterRule{{
+			PodSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ml"}},
+			NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+			Reason:       "no GPU",
+		}},
+		Prioritize: []v1alpha1.MockExtenderPrioritizeRule{
+			{NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}}, Score: 10},
+			{Score: 1},
+		},
+		Failures: []v1alpha1.MockExtenderFailureRule{{
+			Verbs:       []string{"filter"},
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "broken"}},
+			Error:       "injected",
+		}},
+	}, client, calls)
+	require.NoError(t, err)
+
+	t.Run("filter the Nodes", func(t *testing.T) {
+		t.Parallel()
+		got, err := e.Filter(extenderv1.ExtenderArgs{Pod: pod, Nodes: &v1.NodeList{Items: []v1.Node{gpuNode, cpuNode}}})
+		require.NoError(t, err)
+		assert.Equal(t, []v1.Node{gpuNode}, got.Nodes.Items)
+		assert.Equal(t, extenderv1.FailedNodesMap{"node-cpu": "no GPU"}, got.FailedNodes)
+	})
+	t.Run("filter the Nodes given by the names", func(t *testing.T) {
+		t.Parallel()
+		got, err := e.Filter(extenderv1.ExtenderArgs{Pod: pod, NodeNames: &[]string{"node-gpu", "node-cpu"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"node-gpu"}, *got.NodeNames)
+		assert.Nil(t, got.Nodes)
+	})
+	t.Run("fail by the failure rule", func(t *testing.T) {
+		t.Parallel()
+		_, err := e.Filter(extenderv1.ExtenderArgs{Pod: otherPod, Nodes: &v1.NodeList{Items: []v1.Node{gpuNode}}})
+		require.ErrorContains(t, err, "injected")
+
+		got := calls.List("default", "pod2")
+		require.Len(t, got, 1)
+		assert.Equal(t, 500, got[0].StatusCode)
+		assert.Equal(t, "injected", got[0].RawResponse)
+	})
+	t.Run("score by the first rule", func(t *testing.T) {
+		t.Parallel()
+		got, err := e.Prioritize(extenderv1.ExtenderArgs{Pod: pod, Nodes: &v1.NodeList{Items: []v1.Node{gpuNode, cpuNode}}})
+		require.NoError(t, err)
+		// The scores are scaled by the weight 2 and MaxNodeScore / MaxExtenderPriority.
+		assert.Equal(t, extenderv1.HostPriorityList{{Host: "node-gpu", Score: 200}, {Host: "node-cpu", Score: 20}}, *got)
+	})
+	t.Run("bind the Pod", func(t *testing.T) {
+		t.Parallel()
+		got, err := e.Bind(extenderv1.ExtenderBindingArgs{PodName: "pod1", PodNamespace: "default", Node: "node-gpu"})
+		require.NoError(t, err)
+		assert.Empty(t, got.Error)
+
+		var binding *v1.Binding
+		for _, a := range client.Actions() {
+			if c, ok := a.(k8stesting.CreateAction); ok && c.GetSubresource() == "binding" {
+				binding, _ = c.GetObject().(*v1.Binding)
+			}
+		}
+		require.NotNil(t, binding)
+		assert.Equal(t, "node-gpu", binding.Target.Name)
+	})
+}
+
+func TestValidateMockExtender(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		mock    v1alpha1.MockExtender
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			mock: v1alpha1.MockExtender{URLPrefix: "http://extender/", Prioritize: []v1alpha1.MockExtenderPrioritizeRule{{Score: 10}}},
+		},
+		{
+			name:    "no urlPrefix",
+			mock:    v1alpha1.MockExtender{},
+			wantErr: true,
+		},
+		{
+			name:    "score out of range",
+			mock:    v1alpha1.MockExtender{URLPrefix: "http://extender/", Prioritize: []v1alpha1.MockExtenderPrioritizeRule{{Score: 11}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown verb",
+			mock:    v1alpha1.MockExtender{URLPrefix: "http://extender/", Failures: []v1alpha1.MockExtenderFailureRule{{Verbs: []string{"score"}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateMockExtender(&tt.mock)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestValidateMockExtenders(t *testing.T) {
+	t.Parallel()
+	mock := v1alpha1.MockExtender{URLPrefix: "http://extender/"}
+	assert.NoError(t, ValidateMockExtenders([]v1alpha1.MockExtender{mock, {URLPrefix: "http://other/"}}))
+	assert.Error(t, ValidateMockExtenders([]v1alpha1.MockExtender{mock, mock}))
+	assert.Error(t, ValidateMockExtenders([]v1alpha1.MockExtender{mock, {}}))
+}
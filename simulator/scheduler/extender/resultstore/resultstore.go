@@ -19,6 +19,28 @@ type Store interface {
 	AddPrioritizeResult(args extenderv1.ExtenderArgs, result extenderv1.HostPriorityList, hostName string)
 	AddPreemptResult(args extenderv1.ExtenderPreemptionArgs, result extenderv1.ExtenderPreemptionResult, hostName string)
 	AddBindResult(args extenderv1.ExtenderBindingArgs, result extenderv1.ExtenderBindingResult, hostName string)
+	AddDecision(namespace, podName string, decision Decision, hostName string)
+}
+
+// CallMode is how the Nodes are passed to the extender.
+type CallMode string
+
+const (
+	// CallModeNodeNames means that only the names of the Nodes are passed, because the extender is nodeCacheCapable.
+	CallModeNodeNames CallMode = "NodeNames"
+	// CallModeNodes means that the whole Node objects are passed.
+	CallModeNodes CallMode = "Nodes"
+)
+
+// Decision tells whether the scheduler consults the extender for the Pod.
+type Decision struct {
+	// Interested is false when the scheduler skips the extender for the Pod,
+	// because the Pod requests none of the managedResources of the extender.
+	Interested bool `json:"interested"`
+	// Reason tells why the extender is skipped.
+	Reason string `json:"reason,omitempty"`
+	// CallMode is empty when the extender isn't called with the Nodes for the Pod.
+	CallMode CallMode `json:"callMode,omitempty"`
 }
 
 // store has results of all extenders.
@@ -41,6 +63,9 @@ type result struct {
 	preempt map[string]extenderv1.ExtenderPreemptionResult
 
 	bind map[string]extenderv1.ExtenderBindingResult
+
+	// decisions is nil until any decision is stored.
+	decisions map[string]Decision
 }
 
 func New() Store {
@@ -98,6 +123,11 @@ func (s *store) GetStoredResult(pod *v1.Pod) map[string]string {
 		return nil
 	}
 
+	if err := s.addDecisionsToMap(annotation, k); err != nil {
+		klog.Errorf("failed to add extender decisions to the pod: %+v", err)
+		return nil
+	}
+
 	return annotation
 }
 
@@ -137,6 +167,18 @@ func (s *store) addBindResultToMap(anno map[string]string, k key) error {
 	return nil
 }
 
+func (s *store) addDecisionsToMap(anno map[string]string, k key) error {
+	if len(s.results[k].decisions) == 0 {
+		return nil
+	}
+	results, err := json.Marshal(s.results[k].decisions)
+	if err != nil {
+		return xerrors.Errorf("encode extender decisions to json: %w", err)
+	}
+	anno[annotation.ExtenderDecisionsAnnotationKey] = string(results)
+	return nil
+}
+
 // AddFilterResult stores the filtering result.
 func (s *store) AddFilterResult(args extenderv1.ExtenderArgs, result extenderv1.ExtenderFilterResult, hostName string) {
 	s.mu.Lock()
@@ -185,6 +227,25 @@ func (s *store) AddBindResult(args extenderv1.ExtenderBindingArgs, result extend
 	s.results[k].bind[hostName] = result
 }
 
+// AddDecision stores the decision for the extender.
+// The CallMode stored earlier is kept when the decision doesn't have it, e.g., when the extender is called for binding.
+func (s *store) AddDecision(namespace, podName string, decision Decision, hostName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := newKey(namespace, podName)
+	if _, ok := s.results[k]; !ok {
+		s.results[k] = newData()
+	}
+	if s.results[k].decisions == nil {
+		s.results[k].decisions = map[string]Decision{}
+	}
+	if decision.CallMode == "" {
+		decision.CallMode = s.results[k].decisions[hostName].CallMode
+	}
+	s.results[k].decisions[hostName] = decision
+}
+
 // DeleteData deletes the data corresponding to the specified Pod.
 func (s *store) DeleteData(pod v1.Pod) {
 	s.mu.Lock()
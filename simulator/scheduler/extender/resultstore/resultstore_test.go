@@ -997,6 +997,58 @@ func TestStore_AddBindResult(t *testing.T) {
 	}
 }
 
+func TestStore_AddDecision(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		decision      Decision
+		prepareResult map[key]*result
+		wantDecisions map[string]Decision
+	}{
+		{
+			name:          "success to add the decision",
+			decision:      Decision{Interested: false, Reason: "the Pod requests none of the managed resources: example.com/gpu"},
+			prepareResult: map[key]*result{},
+			wantDecisions: map[string]Decision{
+				"extenderserver": {Interested: false, Reason: "the Pod requests none of the managed resources: example.com/gpu"},
+			},
+		},
+		{
+			name:     "keep the call mode stored earlier",
+			decision: Decision{Interested: true},
+			prepareResult: map[key]*result{
+				"default/pod1": {decisions: map[string]Decision{"extenderserver": {Interested: true, CallMode: CallModeNodeNames}}},
+			},
+			wantDecisions: map[string]Decision{
+				"extenderserver": {Interested: true, CallMode: CallModeNodeNames},
+			},
+		},
+		{
+			name:     "overwrite the call mode",
+			decision: Decision{Interested: true, CallMode: CallModeNodes},
+			prepareResult: map[key]*result{
+				"default/pod1": {decisions: map[string]Decision{"extenderserver": {Interested: true, CallMode: CallModeNodeNames}}},
+			},
+			wantDecisions: map[string]Decision{
+				"extenderserver": {Interested: true, CallMode: CallModeNodes},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := &store{
+				mu:      new(sync.Mutex),
+				results: tt.prepareResult,
+			}
+			s.AddDecision("default", "pod1", tt.decision, "extenderserver")
+
+			assert.Equal(t, tt.wantDecisions, s.results["default/pod1"].decisions)
+		})
+	}
+}
+
 func TestStore_DeleteData(t *testing.T) {
 	t.Parallel()
 	podName := "pod1"
@@ -3,6 +3,7 @@ package extender
 //go:generate mockgen -package=mock_$GOPACKAGE -source=./resultstore/resultstore.go -destination=./mock_$GOPACKAGE/resultstore.go
 
 import (
+	"net/url"
 	"strconv"
 
 	"golang.org/x/xerrors"
@@ -10,6 +11,7 @@ import (
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/resultstore"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
 )
@@ -19,80 +21,197 @@ type Service struct {
 	client    clientset.Interface
 	extenders []Extender
 	store     resultstore.Store
+	// configs are the extender configs set by user, in the same order as extenders.
+	configs []configv1.Extender
+	calls   *CallLog
+	mocks   []v1alpha1.MockExtender
+	// faults is nil when no fault is injected.
+	faults *faultInjector
+}
+
+// Option configures Service.
+type Option func(*Service)
+
+// WithCallLog makes Service record the calls to the extenders in l instead of its own CallLog.
+// It's for keeping the calls across the Services, e.g., when the scheduler restarts.
+func WithCallLog(l *CallLog) Option {
+	return func(s *Service) {
+		s.calls = l
+	}
 }
 
 const ResultStoreKey = "ExtenderResultStoreKey"
 
+// WithMockExtenders makes Service answer the requests for the extenders with the URL prefixes of mocks by their rules.
+func WithMockExtenders(mocks []v1alpha1.MockExtender) Option {
+	return func(s *Service) {
+		s.mocks = mocks
+	}
+}
+
+// WithExtenderFaults makes Service inject the faults into the responses for the extenders.
+func WithExtenderFaults(faults []v1alpha1.ExtenderFault) Option {
+	return func(s *Service) {
+		if len(faults) != 0 {
+			s.faults = newFaultInjector(faults)
+		}
+	}
+}
+
 // New initializes Service.
 // `extenderCfgs` expect to receive an untouched config file(set by user).
-func New(client clientset.Interface, extenderCfgs []configv1.Extender, storeReflector storereflector.Reflector) (*Service, error) {
-	extenders, err := createExtenders(extenderCfgs)
+// The calls to the extenders are recorded in the CallLog of DefaultCallLogSize unless WithCallLog is given.
+func New(client clientset.Interface, extenderCfgs []configv1.Extender, storeReflector storereflector.Reflector, opts ...Option) (*Service, error) {
+	s := &Service{
+		client:  client,
+		configs: extenderCfgs,
+		calls:   NewCallLog(DefaultCallLogSize),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := ValidateMockExtenders(s.mocks); err != nil {
+		return nil, xerrors.Errorf("validate mock extenders: %w", err)
+	}
+	if s.faults != nil {
+		if err := ValidateExtenderFaults(s.faults.faults); err != nil {
+			return nil, xerrors.Errorf("validate extender faults: %w", err)
+		}
+	}
+	extenders, err := createExtenders(extenderCfgs, s.mocks, client, s.calls)
 	if err != nil {
 		return nil, xerrors.Errorf("create HTTPExtenders: %w", err)
 	}
-	store := resultstore.New()
+	s.extenders = extenders
+	s.store = resultstore.New()
 	// Register the result store of Extenders to the sharedStore.
-	storeReflector.AddResultStore(store, ResultStoreKey)
-	return &Service{
-		client:    client,
-		extenders: extenders,
-		store:     store,
-	}, nil
+	storeReflector.AddResultStore(s.store, ResultStoreKey)
+	return s, nil
+}
+
+// Calls returns the calls to the extenders for the Pod in the order they're sent.
+// See CallLog.List for the empty namespace and podName.
+func (s *Service) Calls(namespace, podName string) []Call {
+	if s.calls == nil {
+		return []Call{}
+	}
+	return s.calls.List(namespace, podName)
 }
 
 // Filter returns the result of the specified filter extender
 // and store it.
+// The injected fault is returned as FaultResponse.
 func (s *Service) Filter(id int, args extenderv1.ExtenderArgs) (*extenderv1.ExtenderFilterResult, error) {
+	s.recordDecisions(id, args.Pod, callModeOf(args.NodeNames))
+	fault, err := s.injectBeforeCall(id, filterVerb, args)
+	if err != nil {
+		return nil, err
+	}
 	result, err := s.extenders[id].Filter(args)
 	if err != nil {
 		return nil, xerrors.Errorf("call filter of specified HTTPExtender: %w", err)
 	}
+	if fault != nil {
+		switch fault.Type {
+		case v1alpha1.ExtenderFaultPartialNodes:
+			dropLatterHalfNodes(result)
+			s.recordPartialNodes(id, filterVerb, args, fault, result)
+		case v1alpha1.ExtenderFaultMalformedResponse:
+			return nil, s.injectMalformedResponse(id, filterVerb, args, fault, result)
+		}
+	}
 	s.store.AddFilterResult(args, *result, s.extenders[id].Name())
 	return result, nil
 }
 
 // Prioritize returns the result of the specified prioritize extender
 // and store it.
+// The injected fault is returned as FaultResponse.
 func (s *Service) Prioritize(id int, args extenderv1.ExtenderArgs) (*extenderv1.HostPriorityList, error) {
+	s.recordDecisions(id, args.Pod, callModeOf(args.NodeNames))
+	fault, err := s.injectBeforeCall(id, prioritizeVerb, args)
+	if err != nil {
+		return nil, err
+	}
 	result, err := s.extenders[id].Prioritize(args)
 	if err != nil {
 		return nil, xerrors.Errorf("call prioritize of specified HTTPExtender: %w", err)
 	}
+	if fault != nil {
+		switch fault.Type {
+		case v1alpha1.ExtenderFaultPartialNodes:
+			*result = (*result)[:len(*result)/2]
+			s.recordPartialNodes(id, prioritizeVerb, args, fault, result)
+		case v1alpha1.ExtenderFaultMalformedResponse:
+			return nil, s.injectMalformedResponse(id, prioritizeVerb, args, fault, result)
+		}
+	}
 	s.store.AddPrioritizeResult(args, *result, s.extenders[id].Name())
 	return result, nil
 }
 
 // Preempt returns the result of the specified preempt extender
 // and store it.
+// The injected fault is returned as FaultResponse.
 func (s *Service) Preempt(id int, args extenderv1.ExtenderPreemptionArgs) (*extenderv1.ExtenderPreemptionResult, error) {
+	mode := resultstore.CallModeNodes
+	if args.NodeNameToMetaVictims != nil {
+		mode = resultstore.CallModeNodeNames
+	}
+	s.recordDecisions(id, args.Pod, mode)
+	fault, err := s.injectBeforeCall(id, preemptVerb, args)
+	if err != nil {
+		return nil, err
+	}
 	result, err := s.extenders[id].Preempt(args)
 	if err != nil {
 		return nil, xerrors.Errorf("call preempt of specified HTTPExtender: %w", err)
 	}
+	if fault != nil && fault.Type == v1alpha1.ExtenderFaultMalformedResponse {
+		return nil, s.injectMalformedResponse(id, preemptVerb, args, fault, result)
+	}
 	s.store.AddPreemptResult(args, *result, s.extenders[id].Name())
 	return result, nil
 }
 
 // Bind returns the result of the specified bind extender
 // and store it.
+// The injected fault is returned as FaultResponse.
 func (s *Service) Bind(id int, args extenderv1.ExtenderBindingArgs) (*extenderv1.ExtenderBindingResult, error) {
+	fault, err := s.injectBeforeCall(id, bindVerb, args)
+	if err != nil {
+		return nil, err
+	}
 	result, err := s.extenders[id].Bind(args)
 	if err != nil {
 		return nil, xerrors.Errorf("call bind of specified HTTPExtender: %w", err)
 	}
+	if fault != nil && fault.Type == v1alpha1.ExtenderFaultMalformedResponse {
+		return nil, s.injectMalformedResponse(id, bindVerb, args, fault, result)
+	}
 	s.store.AddBindResult(args, *result, s.extenders[id].Name())
 	return result, nil
 }
 
 // OverrideExtendersCfgToSimulator rewrites the scheduler config so that the extenders requests go through the simulator server.
 func OverrideExtendersCfgToSimulator(cfg *configv1.KubeSchedulerConfiguration, simulatorPort int) {
+	overrideExtendersCfg(cfg, "http://localhost:"+strconv.Itoa(simulatorPort)+"/api/v1/extender/")
+}
+
+// OverrideExtendersCfgToSimulatorForScheduler is the same as OverrideExtendersCfgToSimulator,
+// but the requests go to the endpoints for the scheduler with the name so that they're told apart from the other schedulers' ones.
+func OverrideExtendersCfgToSimulatorForScheduler(cfg *configv1.KubeSchedulerConfiguration, simulatorPort int, schedulerName string) {
+	overrideExtendersCfg(cfg, "http://localhost:"+strconv.Itoa(simulatorPort)+"/api/v1/schedulers/"+url.PathEscape(schedulerName)+"/extender/")
+}
+
+func overrideExtendersCfg(cfg *configv1.KubeSchedulerConfiguration, urlPrefix string) {
 	for i := range cfg.Extenders {
 		// i will be the extender's index. That index is specified by request param as `id`.
 		cfg.Extenders[i].EnableHTTPS = false
 		cfg.Extenders[i].TLSConfig = nil
 		// NOTE: We do not plan to launch the "HTTPS" simulator server with echo on our project.
 		// If you customize the server to use HTTPS with echo, you need to fix this line.
-		cfg.Extenders[i].URLPrefix = "http://localhost:" + strconv.Itoa(simulatorPort) + "/api/v1/extender/"
+		cfg.Extenders[i].URLPrefix = urlPrefix
 		if cfg.Extenders[i].FilterVerb != "" {
 			cfg.Extenders[i].FilterVerb = "filter/" + strconv.Itoa(i)
 		}
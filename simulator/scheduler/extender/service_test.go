@@ -7,11 +7,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/mock_extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender/resultstore"
 )
 
 func TestService_Filter(t *testing.T) {
@@ -76,6 +80,68 @@ func TestService_Filter(t *testing.T) {
 	}
 }
 
+func TestService_Filter_Decisions(t *testing.T) {
+	t.Parallel()
+	gpu := v1.ResourceName("example.com/gpu")
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		nodeNames     *[]string
+		wantDecisions map[string]resultstore.Decision
+	}{
+		{
+			name:      "skip the extender managing the resources the Pod doesn't request",
+			pod:       &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
+			nodeNames: &[]string{"node1"},
+			wantDecisions: map[string]resultstore.Decision{
+				"http://ext1/": {Interested: true, CallMode: resultstore.CallModeNodeNames},
+				"http://ext2/": {Interested: false, Reason: "the Pod requests none of the managed resources: example.com/gpu"},
+			},
+		},
+		{
+			name: "consult the extender managing the resources the Pod requests",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+				Spec: v1.PodSpec{InitContainers: []v1.Container{{
+					Resources: v1.ResourceRequirements{Limits: v1.ResourceList{gpu: resource.MustParse("1")}},
+				}}},
+			},
+			wantDecisions: map[string]resultstore.Decision{
+				"http://ext1/": {Interested: true, CallMode: resultstore.CallModeNodes},
+				"http://ext2/": {Interested: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mStore := mock_extender.NewMockStore(ctrl)
+			mExtender := mock_extender.NewMockExtender(ctrl)
+			args := extenderv1.ExtenderArgs{Pod: tt.pod, NodeNames: tt.nodeNames}
+			mExtender.EXPECT().Filter(args).Return(&extenderv1.ExtenderFilterResult{}, nil)
+			mExtender.EXPECT().Name().Return("http://ext1/")
+			mStore.EXPECT().AddFilterResult(args, gomock.Any(), "http://ext1/")
+			got := map[string]resultstore.Decision{}
+			mStore.EXPECT().AddDecision("default", "pod1", gomock.Any(), gomock.Any()).
+				Do(func(_, _ string, d resultstore.Decision, hostName string) { got[hostName] = d }).Times(2)
+
+			s := &Service{
+				extenders: []Extender{mExtender},
+				store:     mStore,
+				configs: []configv1.Extender{
+					{URLPrefix: "http://ext1/", FilterVerb: "filter"},
+					{URLPrefix: "http://ext2/", FilterVerb: "filter", ManagedResources: []configv1.ExtenderManagedResource{{Name: string(gpu)}}},
+				},
+			}
+			_, err := s.Filter(0, args)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDecisions, got)
+		})
+	}
+}
+
 func TestService_Prioritize(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -297,3 +363,13 @@ func TestService_OverrideExtendersCfgToSimulator(t *testing.T) {
 		assert.Equal(t, "bind/"+s, e.BindVerb)
 	}
 }
+
+func TestService_OverrideExtendersCfgToSimulatorForScheduler(t *testing.T) {
+	t.Parallel()
+	target := configv1.KubeSchedulerConfiguration{Extenders: []configv1.Extender{{URLPrefix: "http://example.com/", FilterVerb: "f"}}}
+
+	OverrideExtendersCfgToSimulatorForScheduler(&target, 80, "batch")
+
+	assert.Equal(t, "http://localhost:80/api/v1/schedulers/batch/extender/", target.Extenders[0].URLPrefix)
+	assert.Equal(t, "filter/0", target.Extenders[0].FilterVerb)
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"k8s.io/klog/v2"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+)
+
+// ErrConfigVersionNotFound is returned when the scheduler doesn't have the configuration with the given version.
+var ErrConfigVersionNotFound = errors.New("scheduler configuration version not found")
+
+// ConfigVersion is a scheduler configuration applied to a scheduler.
+type ConfigVersion struct {
+	// Version starts from 1, which is the initial configuration, and is incremented every time a configuration is applied.
+	Version   int       `json:"version"`
+	AppliedAt time.Time `json:"appliedAt"`
+	Comment   string    `json:"comment,omitempty"`
+	// Fingerprint identifies the configuration. The scheduling results are tagged with it.
+	// The same configuration applied multiple times has the same fingerprint.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Config is omitted when the versions are listed.
+	Config *configv1.KubeSchedulerConfiguration `json:"config,omitempty"`
+}
+
+// configHistory keeps all configurations applied to a scheduler.
+type configHistory struct {
+	mu       sync.RWMutex
+	versions []ConfigVersion
+}
+
+func newConfigHistory(initialCfg *configv1.KubeSchedulerConfiguration) *configHistory {
+	h := &configHistory{}
+	h.record(initialCfg, "initial configuration")
+	return h
+}
+
+func (h *configHistory) record(cfg *configv1.KubeSchedulerConfiguration, comment string) {
+	fingerprint, err := simulatorschedconfig.Fingerprint(cfg)
+	if err != nil {
+		// The fingerprint is just for finding the results, so the config is recorded anyway.
+		klog.Errorf("failed to get fingerprint of scheduler config: %+v", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.versions = append(h.versions, ConfigVersion{
+		Version:     len(h.versions) + 1,
+		AppliedAt:   time.Now(),
+		Comment:     comment,
+		Fingerprint: fingerprint,
+		Config:      cfg.DeepCopy(),
+	})
+}
+
+// list returns all versions without their configurations, in the order they were applied.
+func (h *configHistory) list() []ConfigVersion {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ret := make([]ConfigVersion, 0, len(h.versions))
+	for _, v := range h.versions {
+		v.Config = nil
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func (h *configHistory) get(version int) (*ConfigVersion, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if version < 1 || version > len(h.versions) {
+		return nil, xerrors.Errorf("get version %d: %w", version, ErrConfigVersionNotFound)
+	}
+	v := h.versions[version-1]
+	v.Config = v.Config.DeepCopy()
+	return &v, nil
+}
@@ -0,0 +1,351 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/cmd/kube-scheduler/app"
+	schedulerappconfig "k8s.io/kubernetes/cmd/kube-scheduler/app/config"
+	"k8s.io/kubernetes/cmd/kube-scheduler/app/options"
+	"k8s.io/kubernetes/pkg/scheduler"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/cyclestate"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+)
+
+// inProcessRuntime runs the debuggable scheduler in the simulator process.
+// It's restarted by stopping the running scheduler and building a new one with the new config,
+// so it doesn't need the Docker daemon.
+type inProcessRuntime struct {
+	mu     sync.Mutex
+	client clientset.Interface
+	// kubeConfig is used for the clients the scheduler creates by itself, e.g., the dynamic client for the custom resources.
+	kubeConfig *restclient.Config
+	// simulatorPort is used to direct the requests for extenders to the simulator server.
+	// The extenders aren't proxied if it's 0.
+	simulatorPort int
+	// schedulerName is the name of the additional scheduler, whose extenders are proxied by its own endpoints,
+	// and whose wasm and bridge plugins are registered apart from the ones of the other schedulers.
+	// It's empty for the default scheduler.
+	schedulerName string
+
+	// stop stops the running scheduler. It's nil when no scheduler is running.
+	stop            func()
+	extenderService *extender.Service
+	// inspector looks into the scheduling queue of the running scheduler.
+	inspector *queue.Inspector
+	// breakpoints are kept across the restarts.
+	breakpoints *breakpoint.Manager
+	// extenderCalls are kept across the restarts as well. It's nil when the extenders aren't proxied.
+	extenderCalls *extender.CallLog
+	// cycleState is nil when the CycleState isn't recorded.
+	cycleState *cyclestate.Registry
+	// extenderOpts configure the extender service while the extenders are proxied.
+	extenderOpts []extender.Option
+	// pluginExtenders are attached to the plugins by their names.
+	pluginExtenders map[string]plugin.PluginExtenderInitializer
+}
+
+// InProcessOptions configures the Runtime running the scheduler in the simulator process.
+type InProcessOptions struct {
+	// SimulatorPort is the port of the simulator server. When it isn't 0, the requests for extenders
+	// are directed to the simulator server so that their results are recorded, and the runtime serves them as ExtenderService.
+	SimulatorPort int
+	// SchedulerName is the name of the additional scheduler. The requests for its extenders are directed to
+	// the endpoints for the scheduler so that they're told apart from the ones of the default scheduler.
+	// It's empty for the default scheduler.
+	SchedulerName string
+	// RecordCycleState makes the scheduler record the CycleState entries of the in-tree plugins.
+	RecordCycleState bool
+	// PluginExtenders are attached to the plugins by their names. It can be nil.
+	PluginExtenders map[string]plugin.PluginExtenderInitializer
+	// ExtenderOpts configure the extender service proxying the extenders, e.g., to replace them with the mock ones.
+	ExtenderOpts []extender.Option
+}
+
+// NewInProcessRuntime initializes the Runtime to run the scheduler in the simulator process.
+// The scheduler talks to the kube-apiserver with client, and kubeConfig is used for the clients it creates by itself.
+func NewInProcessRuntime(client clientset.Interface, kubeConfig *restclient.Config, opts InProcessOptions) Runtime {
+	r := &inProcessRuntime{
+		client:          client,
+		kubeConfig:      kubeConfig,
+		simulatorPort:   opts.SimulatorPort,
+		schedulerName:   opts.SchedulerName,
+		inspector:       queue.NewInspector(),
+		breakpoints:     breakpoint.NewManager(),
+		extenderOpts:    opts.ExtenderOpts,
+		pluginExtenders: opts.PluginExtenders,
+	}
+	if opts.RecordCycleState {
+		r.cycleState = cyclestate.InTreeRegistry()
+	}
+	if opts.SimulatorPort != 0 {
+		r.extenderCalls = extender.NewCallLog(extender.DefaultCallLogSize)
+	}
+	return r
+}
+
+func (r *inProcessRuntime) Start(cfg *configv1.KubeSchedulerConfiguration) error {
+	return r.Restart(cfg)
+}
+
+func (r *inProcessRuntime) Restart(cfg *configv1.KubeSchedulerConfiguration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.shutdown()
+
+	versioned := cfg.DeepCopy()
+	// Register wasm plugins and bridge plugins to the out-of-tree registry.
+	// This _needs_ to happen before the scheduler configuration is converted.
+	// They're registered for this scheduler so that the ones of the other schedulers in the process are kept.
+	if err := simulatorschedconfig.RegisterWasmPlugins(r.schedulerName, versioned); err != nil {
+		return xerrors.Errorf("register wasm plugins: %w", err)
+	}
+	if err := simulatorschedconfig.RegisterBridgePlugins(r.schedulerName, versioned); err != nil {
+		return xerrors.Errorf("register bridge plugins: %w", err)
+	}
+
+	fingerprint, err := simulatorschedconfig.Fingerprint(cfg)
+	if err != nil {
+		return xerrors.Errorf("get fingerprint of scheduler config: %w", err)
+	}
+	// The results of the scheduler are reflected on Pods via its own store reflector.
+	sharedStore := storereflector.New(storereflector.WithConfigFingerprint(fingerprint))
+	var extenderService *extender.Service
+	if r.simulatorPort != 0 {
+		// Extender service must be initialized before the Extenders config is overridden for the simulator.
+		extenderService, err = extender.New(r.client, versioned.Extenders, sharedStore, append([]extender.Option{extender.WithCallLog(r.extenderCalls)}, r.extenderOpts...)...)
+		if err != nil {
+			return xerrors.Errorf("create extender service: %w", err)
+		}
+	}
+
+	versioned, err = ConvertConfigurationForSimulator(versioned)
+	if err != nil {
+		return xerrors.Errorf("convert scheduler config to apply: %w", err)
+	}
+	if r.simulatorPort != 0 {
+		if r.schedulerName != "" {
+			extender.OverrideExtendersCfgToSimulatorForScheduler(versioned, r.simulatorPort, r.schedulerName)
+		} else {
+			extender.OverrideExtendersCfgToSimulator(versioned, r.simulatorPort)
+		}
+	}
+	internalCfg, err := ConvertSchedulerConfigToInternalConfig(versioned)
+	if err != nil {
+		return xerrors.Errorf("convert scheduler config to internal one: %w", err)
+	}
+
+	wrapOpts := []plugin.Option{plugin.WithBreakpointsOption(r.breakpoints)}
+	if r.cycleState != nil {
+		wrapOpts = append(wrapOpts, plugin.WithCycleStateOption(r.cycleState))
+	}
+	registry, err := plugin.NewRegistry(sharedStore, internalCfg, r.pluginExtenders, wrapOpts...)
+	if err != nil {
+		return xerrors.Errorf("create plugin registry: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sharedStore.ResisterResultSavingToInformer(r.client, ctx.Done()); err != nil {
+		cancel()
+		return xerrors.Errorf("ResisterResultSavingToInformer of sharedStore: %w", err)
+	}
+
+	// The version is recorded on the events and the metrics as well as the scheduler loading the config file does.
+	internalCfg.TypeMeta.APIVersion = versioned.APIVersion
+	cc, sched, err := r.setup(ctx, internalCfg, withPluginOptions(registry)...)
+	if err != nil {
+		cancel()
+		return xerrors.Errorf("create scheduler: %w", err)
+	}
+
+	// Start the scheduler in the same way as app.Run, except for the leader election and the secure serving.
+	cc.EventBroadcaster.StartRecordingToSink(ctx.Done())
+	cc.InformerFactory.Start(ctx.Done())
+	cc.DynInformerFactory.Start(ctx.Done())
+	cc.InformerFactory.WaitForCacheSync(ctx.Done())
+	cc.DynInformerFactory.WaitForCacheSync(ctx.Done())
+	if err := sched.WaitForHandlersSync(ctx); err != nil {
+		klog.ErrorS(err, "waiting for handlers to sync")
+	}
+
+	// The inspector hooks the failure handler of the scheduler, so it's set before the scheduler runs.
+	r.inspector.SetScheduler(sched, internalCfg.PodInitialBackoffSeconds, internalCfg.PodMaxBackoffSeconds)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sched.Run(ctx)
+	}()
+
+	r.extenderService = extenderService
+	r.stop = func() {
+		cancel()
+		// Wait for the old scheduler to stop so that it doesn't schedule Pods along with the new one.
+		<-done
+		cc.EventBroadcaster.Shutdown()
+	}
+	return nil
+}
+
+// setup creates the scheduler with cfg in the same way as app.Setup.
+// app.Setup isn't used as it is because it creates the clients by itself,
+// while the scheduler shares the client of the simulator.
+func (r *inProcessRuntime) setup(ctx context.Context, cfg *config.KubeSchedulerConfiguration, outOfTreeRegistryOptions ...app.Option) (*schedulerappconfig.CompletedConfig, *scheduler.Scheduler, error) {
+	opts := options.NewOptions()
+	opts.ComponentConfig = cfg
+	// The scheduler in the simulator process doesn't serve the health checks and the metrics.
+	opts.SecureServing.BindPort = 0
+	if r.kubeConfig != nil {
+		opts.Master = r.kubeConfig.Host
+	}
+	if errs := opts.Validate(); len(errs) > 0 {
+		return nil, nil, utilerrors.NewAggregate(errs)
+	}
+
+	c := &schedulerappconfig.Config{}
+	if err := opts.ApplyTo(klog.FromContext(ctx), c); err != nil {
+		return nil, nil, xerrors.Errorf("apply options: %w", err)
+	}
+	dynClient, err := dynamic.NewForConfig(c.KubeConfig)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create dynamic client: %w", err)
+	}
+	c.Client = r.client
+	c.EventBroadcaster = events.NewEventBroadcasterAdapterWithContext(ctx, r.client)
+	c.InformerFactory = scheduler.NewInformerFactory(r.client, 0)
+	c.DynInformerFactory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, 0, v1.NamespaceAll, nil)
+	cc := c.Complete()
+
+	outOfTreeRegistry := make(runtime.Registry)
+	for _, option := range outOfTreeRegistryOptions {
+		if err := option(outOfTreeRegistry); err != nil {
+			return nil, nil, xerrors.Errorf("apply out-of-tree registry option: %w", err)
+		}
+	}
+
+	sched, err := scheduler.New(ctx,
+		cc.Client,
+		cc.InformerFactory,
+		cc.DynInformerFactory,
+		func(name string) events.EventRecorder {
+			return cc.EventBroadcaster.NewRecorder(name)
+		},
+		scheduler.WithComponentConfigVersion(cc.ComponentConfig.TypeMeta.APIVersion),
+		scheduler.WithKubeConfig(cc.KubeConfig),
+		scheduler.WithProfiles(cc.ComponentConfig.Profiles...),
+		scheduler.WithPercentageOfNodesToScore(cc.ComponentConfig.PercentageOfNodesToScore),
+		scheduler.WithFrameworkOutOfTreeRegistry(outOfTreeRegistry),
+		scheduler.WithPodMaxBackoffSeconds(cc.ComponentConfig.PodMaxBackoffSeconds),
+		scheduler.WithPodInitialBackoffSeconds(cc.ComponentConfig.PodInitialBackoffSeconds),
+		scheduler.WithPodMaxInUnschedulablePodsDuration(cc.PodMaxInUnschedulablePodsDuration),
+		scheduler.WithExtenders(cc.ComponentConfig.Extenders...),
+		scheduler.WithParallelism(cc.ComponentConfig.Parallelism),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cc, sched, nil
+}
+
+func withPluginOptions(registry map[string]runtime.PluginFactory) []app.Option {
+	opts := make([]app.Option, 0, len(registry))
+	for name, factory := range registry {
+		opts = append(opts, app.WithPlugin(name, factory))
+	}
+	return opts
+}
+
+func (r *inProcessRuntime) Shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown()
+}
+
+func (r *inProcessRuntime) shutdown() {
+	if r.stop == nil {
+		return
+	}
+	klog.Info("shutdown scheduler...")
+	r.stop()
+	r.stop = nil
+	r.extenderService = nil
+	r.inspector.SetScheduler(nil, 0, 0)
+}
+
+func (r *inProcessRuntime) SchedulingQueue(ctx context.Context) (*queue.Snapshot, error) {
+	return r.inspector.Snapshot(ctx)
+}
+
+func (r *inProcessRuntime) ExtenderCalls(_ context.Context, namespace, podName string) ([]extender.Call, error) {
+	if r.extenderCalls == nil {
+		return nil, ErrExtenderCallsNotRecorded
+	}
+	return r.extenderCalls.List(namespace, podName), nil
+}
+
+func (r *inProcessRuntime) Debugger() breakpoint.Debugger {
+	return r.breakpoints
+}
+
+// The methods below serve the requests for extenders directed to the simulator server
+// with the extender service of the running scheduler.
+
+func (r *inProcessRuntime) Filter(id int, args extenderv1.ExtenderArgs) (*extenderv1.ExtenderFilterResult, error) {
+	s, err := r.currentExtenderService()
+	if err != nil {
+		return nil, err
+	}
+	return s.Filter(id, args)
+}
+
+func (r *inProcessRuntime) Prioritize(id int, args extenderv1.ExtenderArgs) (*extenderv1.HostPriorityList, error) {
+	s, err := r.currentExtenderService()
+	if err != nil {
+		return nil, err
+	}
+	return s.Prioritize(id, args)
+}
+
+func (r *inProcessRuntime) Preempt(id int, args extenderv1.ExtenderPreemptionArgs) (*extenderv1.ExtenderPreemptionResult, error) {
+	s, err := r.currentExtenderService()
+	if err != nil {
+		return nil, err
+	}
+	return s.Preempt(id, args)
+}
+
+func (r *inProcessRuntime) Bind(id int, args extenderv1.ExtenderBindingArgs) (*extenderv1.ExtenderBindingResult, error) {
+	s, err := r.currentExtenderService()
+	if err != nil {
+		return nil, err
+	}
+	return s.Bind(id, args)
+}
+
+func (r *inProcessRuntime) currentExtenderService() (*extender.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.extenderService == nil {
+		return nil, xerrors.New("the scheduler isn't running with extenders")
+	}
+	return r.extenderService, nil
+}
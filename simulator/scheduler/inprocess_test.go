@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	schedConfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+)
+
+func TestInProcessRuntime_Restart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	// The fake clientset doesn't handle the binding subresource.
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "binding" {
+			return false, nil, nil
+		}
+		binding, ok := action.(k8stesting.CreateAction).GetObject().(*v1.Binding)
+		if !ok {
+			return false, nil, nil
+		}
+		obj, err := client.Tracker().Get(v1.SchemeGroupVersion.WithResource("pods"), binding.Namespace, binding.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*v1.Pod).DeepCopy()
+		pod.Spec.NodeName = binding.Target.Name
+		return true, nil, client.Tracker().Update(v1.SchemeGroupVersion.WithResource("pods"), pod, pod.Namespace)
+	})
+	rl := v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourcePods: resource.MustParse("110")}
+	_, err := client.CoreV1().Nodes().Create(ctx, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Capacity: rl, Allocatable: rl},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	cfg, err := schedConfig.DefaultSchedulerConfig()
+	require.NoError(t, err)
+	// The scheduler talks to the kube-apiserver only with the fake clientset.
+	rt := NewInProcessRuntime(client, &restclient.Config{Host: "http://127.0.0.1"}, InProcessOptions{})
+	require.NoError(t, rt.Start(cfg))
+	defer rt.Shutdown()
+
+	// The scheduler keeps working after restarted with another schedulerName.
+	cfg = cfg.DeepCopy()
+	cfg.Profiles[0].SchedulerName = ptr.To("another-scheduler")
+	require.NoError(t, rt.Restart(cfg))
+
+	for _, name := range []string{v1.DefaultSchedulerName, "another-scheduler"} {
+		_, err := client.CoreV1().Pods("default").Create(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-for-" + name, Namespace: "default", UID: types.UID("uid-" + name)},
+			Spec: v1.PodSpec{
+				SchedulerName: name,
+				Containers:    []v1.Container{{Name: "container", Image: "image"}},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		p, err := client.CoreV1().Pods("default").Get(ctx, "pod-for-another-scheduler", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return p.Spec.NodeName == "node-1", nil
+	})
+	require.NoError(t, err, "the pod for the restarted scheduler should be scheduled")
+
+	p, err := client.CoreV1().Pods("default").Get(ctx, "pod-for-"+v1.DefaultSchedulerName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, p.Spec.NodeName, "the pod for the old schedulerName should not be scheduled")
+}
@@ -15,16 +15,27 @@ const (
 	ScoreResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/score-result"
 	// FinalScoreResultAnnotationKey has the final score(= normalized and applied score plugin weight).
 	FinalScoreResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/finalscore-result"
+	// ScorePluginWeightAnnotationKey has the weights of the score plugins used to calculate the final score.
+	// It's recorded only when the Pod goes through the Score phase.
+	ScorePluginWeightAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/score-plugin-weight"
+	// CycleStateResultAnnotationKey has the CycleState entries written in PreFilter and PreScore.
+	// It's recorded only when the scheduler records the CycleState, and has the entries with the registered serializers.
+	CycleStateResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/cycle-state-result"
 	// ReserveResultAnnotationKey has the reserve result.
 	ReserveResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/reserve-result"
 	// PermitStatusResultAnnotationKey has the permit result.
 	PermitStatusResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/permit-result"
 	// PermitTimeoutResultAnnotationKey has the permit result.
 	PermitTimeoutResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/permit-result-timeout"
+	// GangResultAnnotationKey has the result of the Permit phase of the Pod in a group (gang),
+	// e.g., how long it waited for the other members and whether it was admitted.
+	GangResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/gang-result"
 	// PreBindResultAnnotationKey has the prebind result.
 	PreBindResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/prebind-result"
 	// BindResultAnnotationKey has the prebind result.
 	BindResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/bind-result"
 	// SelectedNodeAnnotationKey has the selected node name. It's filled when a Pod go through the Reserve phase.
 	SelectedNodeAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/selected-node"
+	// PluginExtenderResultAnnotationKey has what the debugging plugin extenders in the simulator config did, per plugin.
+	PluginExtenderResultAnnotationKey = "kube-scheduler-simulator.sigs.k8s.io/plugin-extender-result"
 )
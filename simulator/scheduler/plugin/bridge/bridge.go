@@ -0,0 +1,349 @@
+// Package bridge provides the plugin forwarding the extension points to an external process over HTTP,
+// so that the plugins can be written in any language without rebuilding the scheduler.
+// See protocol.go for the requests and the responses.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+)
+
+// DefaultTimeout is the timeout of each request to the plugin.
+const DefaultTimeout = 5 * time.Second
+
+// The extension points the plugin can implement.
+var extensionPoints = []string{
+	PreFilterPath, FilterPath, PreScorePath, ScorePath, NormalizeScorePath,
+	ReservePath, PermitPath, PreBindPath, PostBindPath,
+}
+
+// PluginConfig is the args of the bridge plugin in PluginConfig.
+type PluginConfig struct {
+	// Endpoint is the URL the requests are sent to, e.g., "http://localhost:8080/plugin".
+	// The plugin is regarded as a bridge plugin when it's set.
+	Endpoint string `json:"endpoint"`
+	// ExtensionPoints are the extension points implemented by the external process, e.g., "filter".
+	// The requests are sent only for them, and the others behave as if the plugin didn't implement them.
+	// UnreservePath is sent along with ReservePath.
+	ExtensionPoints []string `json:"extensionPoints"`
+	// Timeout is DefaultTimeout when it's zero.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// DecodePluginConfig decodes the args into PluginConfig.
+// It returns nil when the args aren't the config of a bridge plugin.
+func DecodePluginConfig(args runtime.Object) *PluginConfig {
+	cfg := &PluginConfig{}
+	if err := frameworkruntime.DecodeInto(args, cfg); err != nil || cfg.Endpoint == "" {
+		return nil
+	}
+	return cfg
+}
+
+// Validate validates the PluginConfig.
+func (c *PluginConfig) Validate() error {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return xerrors.Errorf("parse endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return xerrors.Errorf("endpoint must be http or https, but got %q", u.Scheme)
+	}
+	for _, p := range c.ExtensionPoints {
+		if !slices.Contains(extensionPoints, p) {
+			return xerrors.Errorf("unknown extension point %q", p)
+		}
+	}
+	if c.Timeout.Duration < 0 {
+		return xerrors.New("timeout must not be negative")
+	}
+	return nil
+}
+
+// Plugin forwards the extension points to the external process.
+type Plugin struct {
+	name            string
+	endpoint        string
+	extensionPoints sets.Set[string]
+	client          *http.Client
+}
+
+var (
+	_ framework.PreFilterPlugin = &Plugin{}
+	_ framework.FilterPlugin    = &Plugin{}
+	_ framework.PreScorePlugin  = &Plugin{}
+	_ framework.ScorePlugin     = &Plugin{}
+	_ framework.ReservePlugin   = &Plugin{}
+	_ framework.PermitPlugin    = &Plugin{}
+	_ framework.PreBindPlugin   = &Plugin{}
+	_ framework.PostBindPlugin  = &Plugin{}
+)
+
+// PluginFactory returns the factory of the bridge plugin with the name.
+func PluginFactory(name string) frameworkruntime.PluginFactory {
+	return func(_ context.Context, configuration runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+		cfg := DecodePluginConfig(configuration)
+		if cfg == nil {
+			return nil, xerrors.Errorf("%s needs endpoint in the args", name)
+		}
+		return New(name, cfg)
+	}
+}
+
+// New initializes the bridge plugin.
+func New(name string, cfg *PluginConfig) (*Plugin, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, xerrors.Errorf("validate the args of %s: %w", name, err)
+	}
+	timeout := cfg.Timeout.Duration
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &Plugin{
+		name:            name,
+		endpoint:        strings.TrimRight(cfg.Endpoint, "/"),
+		extensionPoints: sets.New(cfg.ExtensionPoints...),
+		client:          &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (p *Plugin) Name() string { return p.name }
+
+// cycleStateKey is the key of the CycleState entry having the ID of the scheduling cycle.
+const cycleStateKey framework.StateKey = "bridge/cycle"
+
+type cycleStateData string
+
+func (d cycleStateData) Clone() framework.StateData { return d }
+
+// cycleOf returns the ID of the scheduling cycle written in PreFilter.
+func cycleOf(state *framework.CycleState) string {
+	d, err := state.Read(cycleStateKey)
+	if err != nil {
+		return ""
+	}
+	c, _ := d.(cycleStateData)
+	return string(c)
+}
+
+func (p *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	// The entry is shared by all the bridge plugins in the cycle.
+	cycle := cycleOf(state)
+	if cycle == "" {
+		cycle = string(uuid.NewUUID())
+		state.Write(cycleStateKey, cycleStateData(cycle))
+	}
+	if !p.extensionPoints.Has(PreFilterPath) {
+		if !p.extensionPoints.Has(FilterPath) {
+			return nil, framework.NewStatus(framework.Skip)
+		}
+		return nil, nil
+	}
+	var resp PreFilterResponse
+	if err := p.send(ctx, PreFilterPath, PreFilterRequest{Cycle: cycle, Pod: pod}, &resp); err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	var result *framework.PreFilterResult
+	if resp.NodeNames != nil {
+		result = &framework.PreFilterResult{NodeNames: sets.New(resp.NodeNames...)}
+	}
+	return result, toStatus(resp.Status)
+}
+
+func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions { return nil }
+
+func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if !p.extensionPoints.Has(FilterPath) {
+		return nil
+	}
+	var resp StatusResponse
+	if err := p.send(ctx, FilterPath, FilterRequest{Cycle: cycleOf(state), Pod: pod, Node: nodeInfo.Node()}, &resp); err != nil {
+		return framework.AsStatus(err)
+	}
+	return toStatus(resp.Status)
+}
+
+func (p *Plugin) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo) *framework.Status {
+	if !p.extensionPoints.Has(PreScorePath) {
+		if !p.extensionPoints.Has(ScorePath) {
+			return framework.NewStatus(framework.Skip)
+		}
+		return nil
+	}
+	req := PreScoreRequest{Cycle: cycleOf(state), Pod: pod, Nodes: make([]*v1.Node, 0, len(nodes))}
+	for _, n := range nodes {
+		req.Nodes = append(req.Nodes, n.Node())
+	}
+	var resp StatusResponse
+	if err := p.send(ctx, PreScorePath, req, &resp); err != nil {
+		return framework.AsStatus(err)
+	}
+	return toStatus(resp.Status)
+}
+
+func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	if !p.extensionPoints.Has(ScorePath) {
+		return 0, nil
+	}
+	var resp ScoreResponse
+	if err := p.send(ctx, ScorePath, ScoreRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &resp); err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	return resp.Score, toStatus(resp.Status)
+}
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	if !p.extensionPoints.Has(NormalizeScorePath) {
+		return nil
+	}
+	return p
+}
+
+func (p *Plugin) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	req := NormalizeScoreRequest{Cycle: cycleOf(state), Pod: pod, Scores: make([]NodeScore, 0, len(scores))}
+	for _, s := range scores {
+		req.Scores = append(req.Scores, NodeScore{Name: s.Name, Score: s.Score})
+	}
+	var resp NormalizeScoreResponse
+	if err := p.send(ctx, NormalizeScorePath, req, &resp); err != nil {
+		return framework.AsStatus(err)
+	}
+	if s := toStatus(resp.Status); !s.IsSuccess() {
+		return s
+	}
+	if len(resp.Scores) != len(scores) {
+		return framework.AsStatus(xerrors.Errorf("%s returned %d scores for %d nodes", NormalizeScorePath, len(resp.Scores), len(scores)))
+	}
+	for i := range scores {
+		if resp.Scores[i].Name != scores[i].Name {
+			return framework.AsStatus(xerrors.Errorf("%s returned the score of %s for %s", NormalizeScorePath, resp.Scores[i].Name, scores[i].Name))
+		}
+		scores[i].Score = resp.Scores[i].Score
+	}
+	return nil
+}
+
+func (p *Plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	if !p.extensionPoints.Has(ReservePath) {
+		return nil
+	}
+	var resp StatusResponse
+	if err := p.send(ctx, ReservePath, NodeRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &resp); err != nil {
+		return framework.AsStatus(err)
+	}
+	return toStatus(resp.Status)
+}
+
+func (p *Plugin) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if !p.extensionPoints.Has(ReservePath) {
+		return
+	}
+	// Unreserve can't fail, so the response is ignored.
+	_ = p.send(ctx, UnreservePath, NodeRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &StatusResponse{})
+}
+
+func (p *Plugin) Permit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	if !p.extensionPoints.Has(PermitPath) {
+		return nil, 0
+	}
+	var resp PermitResponse
+	if err := p.send(ctx, PermitPath, NodeRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &resp); err != nil {
+		return framework.AsStatus(err), 0
+	}
+	var timeout time.Duration
+	if resp.Timeout != "" {
+		d, err := time.ParseDuration(resp.Timeout)
+		if err != nil {
+			return framework.AsStatus(xerrors.Errorf("parse timeout: %w", err)), 0
+		}
+		timeout = d
+	}
+	return toStatus(resp.Status), timeout
+}
+
+func (p *Plugin) PreBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	if !p.extensionPoints.Has(PreBindPath) {
+		return nil
+	}
+	var resp StatusResponse
+	if err := p.send(ctx, PreBindPath, NodeRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &resp); err != nil {
+		return framework.AsStatus(err)
+	}
+	return toStatus(resp.Status)
+}
+
+func (p *Plugin) PostBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if !p.extensionPoints.Has(PostBindPath) {
+		return
+	}
+	// PostBind can't fail, so the response is ignored.
+	_ = p.send(ctx, PostBindPath, NodeRequest{Cycle: cycleOf(state), Pod: pod, NodeName: nodeName}, &StatusResponse{})
+}
+
+// send sends the request to the extension point, and decodes the response into result.
+func (p *Plugin) send(ctx context.Context, extensionPoint string, args, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return xerrors.Errorf("encode %s request: %w", extensionPoint, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/"+extensionPoint, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("create %s request: %w", extensionPoint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("send %s request to %s: %w", extensionPoint, p.name, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("read %s response: %w", extensionPoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("%s returned status %d for %s: %s", p.name, resp.StatusCode, extensionPoint, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return xerrors.Errorf("decode %s response: %w", extensionPoint, err)
+	}
+	return nil
+}
+
+// toStatus converts Status in the response to framework.Status.
+// The unknown code is regarded as Error so that the broken response doesn't pass silently.
+func toStatus(s Status) *framework.Status {
+	switch s.Code {
+	case "", framework.Success.String():
+		return nil
+	case framework.Error.String():
+		return framework.NewStatus(framework.Error, s.Reasons...)
+	case framework.Unschedulable.String():
+		return framework.NewStatus(framework.Unschedulable, s.Reasons...)
+	case framework.UnschedulableAndUnresolvable.String():
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, s.Reasons...)
+	case framework.Wait.String():
+		return framework.NewStatus(framework.Wait, s.Reasons...)
+	case framework.Skip.String():
+		return framework.NewStatus(framework.Skip, s.Reasons...)
+	case framework.Pending.String():
+		return framework.NewStatus(framework.Pending, s.Reasons...)
+	}
+	return framework.NewStatus(framework.Error, append([]string{"unknown status code " + s.Code}, s.Reasons...)...)
+}
@@ -0,0 +1,173 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		paths  []string
+		cycles = sets.New[string]()
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Cycle    string      `json:"cycle"`
+			Node     *v1.Node    `json:"node"`
+			NodeName string      `json:"nodeName"`
+			Scores   []NodeScore `json:"scores"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		cycles.Insert(req.Cycle)
+		mu.Unlock()
+
+		var resp interface{}
+		switch r.URL.Path {
+		case "/plugin/" + PreFilterPath:
+			resp = PreFilterResponse{NodeNames: []string{"node1", "node2"}}
+		case "/plugin/" + FilterPath:
+			if req.Node.Name == "node2" {
+				resp = StatusResponse{Status: Status{Code: "Unschedulable", Reasons: []string{"no room"}}}
+			} else {
+				resp = StatusResponse{}
+			}
+		case "/plugin/" + ScorePath:
+			resp = ScoreResponse{Score: int64(len(req.NodeName))}
+		case "/plugin/" + NormalizeScorePath:
+			for i := range req.Scores {
+				req.Scores[i].Score *= 10
+			}
+			resp = NormalizeScoreResponse{Scores: req.Scores}
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New("bridge", &PluginConfig{
+		Endpoint:        server.URL + "/plugin/",
+		ExtensionPoints: []string{PreFilterPath, FilterPath, ScorePath, NormalizeScorePath},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}
+	node := func(name string) *framework.NodeInfo {
+		ni := framework.NewNodeInfo()
+		ni.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		return ni
+	}
+
+	result, s := p.PreFilter(ctx, state, pod)
+	require.True(t, s.IsSuccess())
+	assert.Equal(t, sets.New("node1", "node2"), result.NodeNames)
+
+	assert.True(t, p.Filter(ctx, state, pod, node("node1")).IsSuccess())
+	s = p.Filter(ctx, state, pod, node("node2"))
+	assert.Equal(t, framework.Unschedulable, s.Code())
+	assert.Equal(t, []string{"no room"}, s.Reasons())
+
+	// PreScore isn't implemented by the external process, but Score is.
+	assert.True(t, p.PreScore(ctx, state, pod, []*framework.NodeInfo{node("node1")}).IsSuccess())
+	score, s := p.Score(ctx, state, pod, "node1")
+	require.True(t, s.IsSuccess())
+	assert.Equal(t, int64(5), score)
+
+	require.NotNil(t, p.ScoreExtensions())
+	scores := framework.NodeScoreList{{Name: "node1", Score: 5}}
+	require.True(t, p.ScoreExtensions().NormalizeScore(ctx, state, pod, scores).IsSuccess())
+	assert.Equal(t, framework.NodeScoreList{{Name: "node1", Score: 50}}, scores)
+
+	// The extension points not implemented by the external process aren't sent.
+	assert.True(t, p.Reserve(ctx, state, pod, "node1").IsSuccess())
+	s, _ = p.Permit(ctx, state, pod, "node1")
+	assert.True(t, s.IsSuccess())
+
+	assert.Equal(t, []string{"/plugin/preFilter", "/plugin/filter", "/plugin/filter", "/plugin/score", "/plugin/normalizeScore"}, paths)
+	// All the requests in the cycle have the same cycle ID.
+	assert.Equal(t, 1, cycles.Len())
+	assert.NotContains(t, cycles, "")
+}
+
+func TestPlugin_Skip(t *testing.T) {
+	t.Parallel()
+
+	p, err := New("bridge", &PluginConfig{Endpoint: "http://localhost:0", ExtensionPoints: []string{ReservePath}})
+	require.NoError(t, err)
+
+	state := framework.NewCycleState()
+	pod := &v1.Pod{}
+	_, s := p.PreFilter(context.Background(), state, pod)
+	assert.Equal(t, framework.Skip, s.Code())
+	assert.Equal(t, framework.Skip, p.PreScore(context.Background(), state, pod, nil).Code())
+	assert.Nil(t, p.ScoreExtensions())
+}
+
+func TestPlugin_Error(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New("bridge", &PluginConfig{Endpoint: server.URL, ExtensionPoints: []string{FilterPath}})
+	require.NoError(t, err)
+
+	ni := framework.NewNodeInfo()
+	ni.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+	s := p.Filter(context.Background(), framework.NewCycleState(), &v1.Pod{}, ni)
+	assert.Equal(t, framework.Error, s.Code())
+	assert.Contains(t, s.Message(), "broken")
+}
+
+func TestPluginConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		cfg     PluginConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg:  PluginConfig{Endpoint: "https://plugin.example.com", ExtensionPoints: []string{PreFilterPath, FilterPath}},
+		},
+		{
+			name:    "unsupported scheme",
+			cfg:     PluginConfig{Endpoint: "unix:///tmp/plugin.sock"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown extension point",
+			cfg:     PluginConfig{Endpoint: "http://localhost", ExtensionPoints: []string{"bind"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.cfg.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package bridge
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// The paths of the extension points, which are appended to the endpoint of the plugin.
+// Each request is sent with POST and a JSON body.
+const (
+	PreFilterPath      = "preFilter"
+	FilterPath         = "filter"
+	PreScorePath       = "preScore"
+	ScorePath          = "score"
+	NormalizeScorePath = "normalizeScore"
+	ReservePath        = "reserve"
+	UnreservePath      = "unreserve"
+	PermitPath         = "permit"
+	PreBindPath        = "preBind"
+	PostBindPath       = "postBind"
+)
+
+// Status is the result of the extension point.
+type Status struct {
+	// Code is one of "Success", "Error", "Unschedulable", "UnschedulableAndUnresolvable", "Wait", "Skip" and "Pending".
+	// Empty means "Success".
+	Code    string   `json:"code,omitempty"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// PreFilterRequest is sent to PreFilterPath.
+type PreFilterRequest struct {
+	// Cycle identifies the scheduling cycle. The same value is sent at all the extension points in the cycle,
+	// so that the plugin can keep its own state for the cycle.
+	Cycle string  `json:"cycle"`
+	Pod   *v1.Pod `json:"pod"`
+}
+
+// PreFilterResponse is returned from PreFilterPath.
+type PreFilterResponse struct {
+	Status Status `json:"status"`
+	// NodeNames are the only Nodes to be evaluated by Filter. All Nodes are evaluated when it's nil.
+	NodeNames []string `json:"nodeNames,omitempty"`
+}
+
+// FilterRequest is sent to FilterPath.
+type FilterRequest struct {
+	Cycle string   `json:"cycle"`
+	Pod   *v1.Pod  `json:"pod"`
+	Node  *v1.Node `json:"node"`
+}
+
+// PreScoreRequest is sent to PreScorePath.
+type PreScoreRequest struct {
+	Cycle string     `json:"cycle"`
+	Pod   *v1.Pod    `json:"pod"`
+	Nodes []*v1.Node `json:"nodes"`
+}
+
+// ScoreRequest is sent to ScorePath.
+type ScoreRequest struct {
+	Cycle    string  `json:"cycle"`
+	Pod      *v1.Pod `json:"pod"`
+	NodeName string  `json:"nodeName"`
+}
+
+// ScoreResponse is returned from ScorePath.
+type ScoreResponse struct {
+	Status Status `json:"status"`
+	Score  int64  `json:"score"`
+}
+
+// NodeScore is the score of the Node.
+type NodeScore struct {
+	Name  string `json:"name"`
+	Score int64  `json:"score"`
+}
+
+// NormalizeScoreRequest is sent to NormalizeScorePath.
+type NormalizeScoreRequest struct {
+	Cycle  string      `json:"cycle"`
+	Pod    *v1.Pod     `json:"pod"`
+	Scores []NodeScore `json:"scores"`
+}
+
+// NormalizeScoreResponse is returned from NormalizeScorePath.
+// Scores must have the same Nodes in the same order as the request.
+type NormalizeScoreResponse struct {
+	Status Status      `json:"status"`
+	Scores []NodeScore `json:"scores"`
+}
+
+// NodeRequest is sent to ReservePath, UnreservePath, PermitPath, PreBindPath and PostBindPath.
+type NodeRequest struct {
+	Cycle    string  `json:"cycle"`
+	Pod      *v1.Pod `json:"pod"`
+	NodeName string  `json:"nodeName"`
+}
+
+// StatusResponse is returned from FilterPath, PreScorePath, ReservePath, PreBindPath.
+// The responses from UnreservePath and PostBindPath are ignored.
+type StatusResponse struct {
+	Status Status `json:"status"`
+}
+
+// PermitResponse is returned from PermitPath.
+type PermitResponse struct {
+	Status Status `json:"status"`
+	// Timeout is how long the Pod waits for the approval when the code is "Wait", e.g., "10s".
+	Timeout string `json:"timeout,omitempty"`
+}
@@ -0,0 +1,125 @@
+// Package cyclestate serializes the entries of framework.CycleState,
+// so that the internal state the plugins compute in PreFilter and PreScore can be recorded with the scheduling results.
+package cyclestate
+
+import (
+	"encoding/json"
+	"sort"
+
+	"golang.org/x/xerrors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Serializer converts a CycleState entry to a value which can be encoded in JSON.
+type Serializer func(data framework.StateData) (interface{}, error)
+
+// JSON is the Serializer for the entries whose types can be encoded in JSON as they are.
+func JSON(data framework.StateData) (interface{}, error) {
+	return data, nil
+}
+
+// Registry has the Serializers of the CycleState entries, grouped by the plugins writing them.
+type Registry struct {
+	// plugin name → state key → serializer
+	serializers map[string]map[framework.StateKey]Serializer
+}
+
+// NewRegistry initializes Registry without Serializers.
+func NewRegistry() *Registry {
+	return &Registry{serializers: map[string]map[framework.StateKey]Serializer{}}
+}
+
+// InTreeRegistry initializes Registry with the Serializers for the entries written by the in-tree plugins.
+// The in-tree plugins keep their state in unexported types, so all of them are serialized by Reflect.
+// DynamicResources isn't included because its state holds the allocator, which is too large to record.
+func InTreeRegistry() *Registry {
+	r := NewRegistry()
+	for pluginName, keys := range map[string][]framework.StateKey{
+		"NodeResourcesFit":                {"PreFilterNodeResourcesFit", "PreScoreNodeResourcesFit"},
+		"NodeResourcesBalancedAllocation": {"PreScoreNodeResourcesBalancedAllocation"},
+		"NodeAffinity":                    {"PreFilterNodeAffinity", "PreScoreNodeAffinity"},
+		"NodePorts":                       {"PreFilterNodePorts"},
+		"TaintToleration":                 {"PreScoreTaintToleration"},
+		"InterPodAffinity":                {"PreFilterInterPodAffinity", "PreScoreInterPodAffinity"},
+		"PodTopologySpread":               {"PreFilterPodTopologySpread", "PreScorePodTopologySpread"},
+		"VolumeRestrictions":              {"PreFilterVolumeRestrictions"},
+		"VolumeZone":                      {"PreFilterVolumeZone"},
+		"VolumeBinding":                   {"VolumeBinding"},
+	} {
+		for _, k := range keys {
+			r.Register(pluginName, k, Reflect)
+		}
+	}
+	return r
+}
+
+// Register registers the Serializer for the entry with the key written by the plugin.
+// It replaces the one already registered for the same plugin and key.
+func (r *Registry) Register(pluginName string, key framework.StateKey, s Serializer) {
+	if r.serializers[pluginName] == nil {
+		r.serializers[pluginName] = map[framework.StateKey]Serializer{}
+	}
+	r.serializers[pluginName][key] = s
+}
+
+// Has returns true if any entry of the plugin is registered.
+func (r *Registry) Has(pluginName string) bool {
+	return len(r.serializers[pluginName]) != 0
+}
+
+// Serialize encodes the entries of the plugin in state to JSON, keyed by their state keys.
+// The entries not written in state are omitted.
+// The entries failed to be serialized are returned along with the error, and the others are still encoded.
+func (r *Registry) Serialize(pluginName string, state *framework.CycleState) (map[string]string, error) {
+	keys := make([]string, 0, len(r.serializers[pluginName]))
+	for k := range r.serializers[pluginName] {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	ret := map[string]string{}
+	var errs []error
+	for _, k := range keys {
+		data, err := state.Read(framework.StateKey(k))
+		if err != nil {
+			// The plugin hasn't written the entry in this cycle.
+			continue
+		}
+		v, err := r.serializers[pluginName][framework.StateKey(k)](data)
+		if err != nil {
+			errs = append(errs, xerrors.Errorf("serialize %s: %w", k, err))
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			errs = append(errs, xerrors.Errorf("encode json of %s: %w", k, err))
+			continue
+		}
+		ret[k] = string(b)
+	}
+	return ret, utilerrors.NewAggregate(errs)
+}
+
+// SerializeAll encodes the entries of all the plugins in state to JSON, keyed by their state keys,
+// in the same way as Serialize.
+func (r *Registry) SerializeAll(state *framework.CycleState) (map[string]string, error) {
+	plugins := make([]string, 0, len(r.serializers))
+	for p := range r.serializers {
+		plugins = append(plugins, p)
+	}
+	sort.Strings(plugins)
+
+	ret := map[string]string{}
+	var errs []error
+	for _, p := range plugins {
+		entries, err := r.Serialize(p, state)
+		if err != nil {
+			errs = append(errs, xerrors.Errorf("serialize entries of %s: %w", p, err))
+		}
+		for k, e := range entries {
+			ret[k] = e
+		}
+	}
+	return ret, utilerrors.NewAggregate(errs)
+}
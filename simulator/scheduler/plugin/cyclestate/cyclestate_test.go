@@ -0,0 +1,91 @@
+package cyclestate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type topologyPair struct {
+	key   string
+	value string
+}
+
+type node struct {
+	name string
+	next *node
+}
+
+type state struct {
+	counts   map[topologyPair]int
+	names    sets.Set[string]
+	pod      *v1.Pod
+	weights  []float64
+	onUpdate func()
+	head     *node
+}
+
+func (s *state) Clone() framework.StateData { return s }
+
+type exported struct {
+	Count int `json:"count"`
+}
+
+func (e *exported) Clone() framework.StateData { return e }
+
+func TestReflect(t *testing.T) {
+	t.Parallel()
+
+	head := &node{name: "a"}
+	head.next = &node{name: "b", next: head}
+	data := &state{
+		counts:   map[topologyPair]int{{key: "zone", value: "a"}: 2},
+		names:    sets.New("b", "a"),
+		pod:      &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}},
+		weights:  []float64{1.5},
+		onUpdate: func() {},
+		head:     head,
+	}
+
+	got, err := Reflect(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"counts":  map[string]interface{}{"key=zone,value=a": int64(2)},
+		"names":   []interface{}{"a", "b"},
+		"pod":     "default/pod-1",
+		"weights": []interface{}{1.5},
+		"head": map[string]interface{}{
+			"name": "a",
+			"next": map[string]interface{}{"name": "b", "next": "<cycle>"},
+		},
+	}, got)
+}
+
+func TestRegistry_Serialize(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.Register("Sample", "PreFilterSample", JSON)
+	r.Register("Sample", "PreScoreSample", Reflect)
+	r.Register("Other", "PreFilterOther", JSON)
+
+	cs := framework.NewCycleState()
+	cs.Write("PreFilterSample", &exported{Count: 3})
+	cs.Write("PreFilterOther", &exported{Count: 4})
+
+	got, err := r.Serialize("Sample", cs)
+	require.NoError(t, err)
+	// PreScoreSample isn't written yet, and PreFilterOther is written by another plugin.
+	assert.Equal(t, map[string]string{"PreFilterSample": `{"count":3}`}, got)
+	assert.True(t, r.Has("Sample"))
+	assert.False(t, r.Has("Unknown"))
+
+	all, err := r.SerializeAll(cs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PreFilterSample": `{"count":3}`, "PreFilterOther": `{"count":4}`}, all)
+}
@@ -0,0 +1,186 @@
+package cyclestate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// maxDepth is how deep Reflect follows the nested values. The deeper ones are replaced with "...".
+const maxDepth = 10
+
+var (
+	podType      = reflect.TypeOf(v1.Pod{})
+	nodeType     = reflect.TypeOf(v1.Node{})
+	podInfoType  = reflect.TypeOf(framework.PodInfo{})
+	nodeInfoType = reflect.TypeOf(framework.NodeInfo{})
+)
+
+// Reflect is the Serializer which reads all fields of the entry including the unexported ones.
+// It's for the entries of the types defined in other packages, e.g., the in-tree plugins.
+//
+// The fields are named as they're in Go.
+// The sets (maps to struct{}) are converted to the sorted lists, and the maps keyed by structs are keyed by "field=value,...".
+// Pods, Nodes, PodInfos and NodeInfos are converted to their names, in "<namespace>/<name>" for Pods,
+// because the state refers to the objects in the snapshot and recording them all makes the result too large.
+// Funcs, channels and the values from the sync packages are omitted.
+func Reflect(data framework.StateData) (interface{}, error) {
+	return reflectValue(reflect.ValueOf(data), 0, map[uintptr]bool{}), nil
+}
+
+//nolint:cyclop,exhaustive // the other kinds are omitted.
+func reflectValue(v reflect.Value, depth int, visiting map[uintptr]bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxDepth {
+		return "..."
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		// The pointers on the current path are tracked to stop following the cyclic references.
+		p := v.Pointer()
+		if visiting[p] {
+			return "<cycle>"
+		}
+		visiting[p] = true
+		defer delete(visiting, p)
+		return reflectValue(v.Elem(), depth+1, visiting)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return reflectValue(v.Elem(), depth+1, visiting)
+	case reflect.Struct:
+		return reflectStruct(v, depth, visiting)
+	case reflect.Map:
+		return reflectMap(v, depth, visiting)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		ret := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ret = append(ret, reflectValue(v.Index(i), depth+1, visiting))
+		}
+		return ret
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			// JSON has no representation of them.
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return f
+	case reflect.String:
+		return v.String()
+	}
+	return nil
+}
+
+func reflectStruct(v reflect.Value, depth int, visiting map[uintptr]bool) interface{} {
+	switch v.Type() {
+	case podType:
+		return objectName(v.FieldByName("ObjectMeta"), true)
+	case nodeType:
+		return objectName(v.FieldByName("ObjectMeta"), false)
+	case podInfoType:
+		return reflectValue(v.FieldByName("Pod"), depth, visiting)
+	case nodeInfoType:
+		return reflectValue(v.FieldByName("node"), depth, visiting)
+	}
+
+	ret := map[string]interface{}{}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if omitted(f.Type) {
+			continue
+		}
+		val := reflectValue(v.Field(i), depth+1, visiting)
+		if f.Anonymous {
+			// The fields of the embedded struct are promoted like encoding/json does.
+			if m, ok := val.(map[string]interface{}); ok {
+				for k, fv := range m {
+					ret[k] = fv
+				}
+				continue
+			}
+		}
+		ret[f.Name] = val
+	}
+	return ret
+}
+
+func reflectMap(v reflect.Value, depth int, visiting map[uintptr]bool) interface{} {
+	if v.IsNil() {
+		return nil
+	}
+	keys := v.MapKeys()
+	if elem := v.Type().Elem(); elem.Kind() == reflect.Struct && elem.NumField() == 0 {
+		// sets.Set
+		ret := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			ret = append(ret, reflectValue(k, depth+1, visiting))
+		}
+		sort.Slice(ret, func(i, j int) bool { return fmt.Sprint(ret[i]) < fmt.Sprint(ret[j]) })
+		return ret
+	}
+	ret := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		ret[mapKey(k)] = reflectValue(v.MapIndex(k), depth+1, visiting)
+	}
+	return ret
+}
+
+// mapKey converts the map key to a string. The structs are converted to "field=value,...".
+func mapKey(k reflect.Value) string {
+	for k.Kind() == reflect.Ptr || k.Kind() == reflect.Interface {
+		if k.IsNil() {
+			return "<nil>"
+		}
+		k = k.Elem()
+	}
+	if k.Kind() != reflect.Struct {
+		return fmt.Sprint(reflectValue(k, 0, map[uintptr]bool{}))
+	}
+	fields := make([]string, 0, k.NumField())
+	for i := 0; i < k.NumField(); i++ {
+		fields = append(fields, k.Type().Field(i).Name+"="+mapKey(k.Field(i)))
+	}
+	return strings.Join(fields, ",")
+}
+
+func objectName(meta reflect.Value, namespaced bool) string {
+	name := meta.FieldByName("Name").String()
+	if !namespaced {
+		return name
+	}
+	return meta.FieldByName("Namespace").String() + "/" + name
+}
+
+// omitted returns true for the types which don't have the state, or can't be read safely.
+func omitted(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return t.PkgPath() == "sync" || t.PkgPath() == "sync/atomic"
+}
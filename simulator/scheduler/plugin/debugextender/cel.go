@@ -0,0 +1,181 @@
+package debugextender
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/cel/library"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// celCostLimit bounds the cost of evaluating an expression, so that a heavy expression doesn't stall the scheduling.
+const celCostLimit = 1000000
+
+// celEnvs are the CEL environments for CELFilter and CELScore.
+// Other than the standard ones, the functions for the strings, the lists, the regular expressions and the quantities are available,
+// e.g., `quantity(node.status.allocatable.memory).isGreaterThan(quantity("8Gi"))`.
+var celEnvs = sync.OnceValues(func() (map[*cel.Type]*cel.Env, error) {
+	base, err := cel.NewEnv(
+		cel.Variable("pod", cel.DynType),
+		cel.Variable("node", cel.DynType),
+		ext.Strings(),
+		library.Lists(),
+		library.Regex(),
+		library.Quantity(),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("create CEL environment: %w", err)
+	}
+	score, err := base.Extend(cel.Variable("score", cel.IntType))
+	if err != nil {
+		return nil, xerrors.Errorf("create CEL environment for score: %w", err)
+	}
+	return map[*cel.Type]*cel.Env{cel.BoolType: base, cel.IntType: score}, nil
+})
+
+// compileCEL compiles the expression resulting in the type, cel.BoolType or cel.IntType.
+// The expression resulting in dyn, e.g., `node.spec.unschedulable`, is checked at the evaluation.
+func compileCEL(expression string, want *cel.Type) (cel.Program, error) {
+	if expression == "" {
+		return nil, xerrors.New("expression is required")
+	}
+	envs, err := celEnvs()
+	if err != nil {
+		return nil, err
+	}
+	env := envs[want]
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, xerrors.Errorf("compile expression: %w", issues.Err())
+	}
+	if !ast.OutputType().IsExactType(want) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, xerrors.Errorf("expression must be %s, but got %s", want, ast.OutputType())
+	}
+	prg, err := env.Program(ast, cel.CostLimit(celCostLimit))
+	if err != nil {
+		return nil, xerrors.Errorf("create program of expression: %w", err)
+	}
+	return prg, nil
+}
+
+// celVars makes the variables of the expression from the Pod and the Node.
+func celVars(pod *v1.Pod, node *v1.Node) (map[string]interface{}, error) {
+	p, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return nil, xerrors.Errorf("convert Pod: %w", err)
+	}
+	n, err := runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+	if err != nil {
+		return nil, xerrors.Errorf("convert Node: %w", err)
+	}
+	return map[string]interface{}{"pod": p, "node": n}, nil
+}
+
+// celFilter rejects the Nodes for which the expression is false before the plugin runs.
+// The plugin isn't run for the rejected Nodes, so they don't have the result of the plugin in the filter result.
+type celFilter struct {
+	base
+	program cel.Program
+	reason  string
+}
+
+func (e *celFilter) BeforeFilter(_ context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if nodeInfo.Node() == nil || !e.matchPod(pod) || !e.matchNodeInfo(nodeInfo) {
+		return nil
+	}
+	pass, err := e.eval(pod, nodeInfo.Node())
+	if err != nil {
+		// The plugin decides as usual when the expression can't be evaluated.
+		klog.ErrorS(err, "failed to evaluate the expression of CELFilter", "plugin", e.plugin, "pod", klog.KObj(pod), "node", nodeInfo.Node().Name)
+		return nil
+	}
+	if pass {
+		return nil
+	}
+	e.record(state, pod, func(r *Result) {
+		if r.Filter == nil {
+			r.Filter = map[string]string{}
+		}
+		r.Filter[nodeInfo.Node().Name] = e.reason
+	})
+	return framework.NewStatus(framework.Unschedulable, e.reason)
+}
+
+func (e *celFilter) AfterFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo, filterResult *framework.Status) *framework.Status {
+	return filterResult
+}
+
+func (e *celFilter) eval(pod *v1.Pod, node *v1.Node) (bool, error) {
+	vars, err := celVars(pod, node)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := e.program.Eval(vars)
+	if err != nil {
+		return false, xerrors.Errorf("evaluate expression: %w", err)
+	}
+	pass, ok := out.Value().(bool)
+	if !ok {
+		return false, xerrors.Errorf("expression must be bool, but got %v", out.Type())
+	}
+	return pass, nil
+}
+
+// celScore replaces the score the plugin gives with the value of the expression.
+// The score is still normalized by NormalizeScore of the plugin, if any.
+type celScore struct {
+	base
+	program cel.Program
+}
+
+func (e *celScore) BeforeScore(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) (int64, *framework.Status) {
+	return 0, nil
+}
+
+func (e *celScore) AfterScore(_ context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string, score int64, scoreResult *framework.Status) (int64, *framework.Status) {
+	if !scoreResult.IsSuccess() || !e.matchPod(pod) {
+		return score, scoreResult
+	}
+	nodeInfo := e.nodeInfo(nodeName)
+	if nodeInfo == nil || nodeInfo.Node() == nil || !e.matchNodeInfo(nodeInfo) {
+		return score, scoreResult
+	}
+	overridden, err := e.eval(pod, nodeInfo.Node(), score)
+	if err != nil {
+		klog.ErrorS(err, "failed to evaluate the expression of CELScore", "plugin", e.plugin, "pod", klog.KObj(pod), "node", nodeName)
+		return score, scoreResult
+	}
+	e.record(state, pod, func(r *Result) {
+		if r.Score == nil {
+			r.Score = map[string]int64{}
+		}
+		r.Score[nodeName] = overridden
+	})
+	return overridden, scoreResult
+}
+
+func (e *celScore) eval(pod *v1.Pod, node *v1.Node, score int64) (int64, error) {
+	vars, err := celVars(pod, node)
+	if err != nil {
+		return 0, err
+	}
+	vars["score"] = score
+	out, _, err := e.program.Eval(vars)
+	if err != nil {
+		return 0, xerrors.Errorf("evaluate expression: %w", err)
+	}
+	ret, ok := out.Value().(int64)
+	if !ok {
+		return 0, xerrors.Errorf("expression must be int, but got %v", out.Type())
+	}
+	if ret < framework.MinNodeScore || ret > framework.MaxNodeScore {
+		return 0, xerrors.Errorf("expression must be between %d and %d, but got %d", framework.MinNodeScore, framework.MaxNodeScore, ret)
+	}
+	return ret, nil
+}
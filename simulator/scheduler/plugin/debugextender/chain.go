@@ -0,0 +1,344 @@
+package debugextender
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+)
+
+// Chain makes a PluginExtenderInitializer running the extenders of the initializers in order.
+// At each extension point, the Before hooks stop at the first one returning non-success status,
+// and each After hook is given the result of the previous one.
+func Chain(initializers ...plugin.PluginExtenderInitializer) plugin.PluginExtenderInitializer {
+	return func(handle plugin.SimulatorHandle) plugin.PluginExtenders {
+		list := make([]plugin.PluginExtenders, 0, len(initializers))
+		for _, i := range initializers {
+			list = append(list, i(handle))
+		}
+		return chain(list)
+	}
+}
+
+// Merge merges the PluginExtenderInitializers by the plugin names.
+// The initializers for the same plugin are chained in the order of the maps.
+func Merge(maps ...map[string]plugin.PluginExtenderInitializer) map[string]plugin.PluginExtenderInitializer {
+	byPlugin := map[string][]plugin.PluginExtenderInitializer{}
+	for _, m := range maps {
+		for name, i := range m {
+			byPlugin[name] = append(byPlugin[name], i)
+		}
+	}
+	ret := make(map[string]plugin.PluginExtenderInitializer, len(byPlugin))
+	for name, initializers := range byPlugin {
+		if len(initializers) == 1 {
+			ret[name] = initializers[0]
+			continue
+		}
+		ret[name] = Chain(initializers...)
+	}
+	return ret
+}
+
+//nolint:cyclop // sets each extension point implemented by any of the extenders.
+func chain(list []plugin.PluginExtenders) plugin.PluginExtenders {
+	if len(list) == 1 {
+		return list[0]
+	}
+	c := &extenderChain{list: list}
+	ret := plugin.PluginExtenders{}
+	for _, e := range list {
+		if e.PreFilterPluginExtender != nil {
+			ret.PreFilterPluginExtender = c
+		}
+		if e.FilterPluginExtender != nil {
+			ret.FilterPluginExtender = c
+		}
+		if e.PostFilterPluginExtender != nil {
+			ret.PostFilterPluginExtender = c
+		}
+		if e.PreScorePluginExtender != nil {
+			ret.PreScorePluginExtender = c
+		}
+		if e.ScorePluginExtender != nil {
+			ret.ScorePluginExtender = c
+		}
+		if e.NormalizeScorePluginExtender != nil {
+			ret.NormalizeScorePluginExtender = c
+		}
+		if e.PermitPluginExtender != nil {
+			ret.PermitPluginExtender = c
+		}
+		if e.ReservePluginExtender != nil {
+			ret.ReservePluginExtender = c
+		}
+		if e.PreBindPluginExtender != nil {
+			ret.PreBindPluginExtender = c
+		}
+		if e.BindPluginExtender != nil {
+			ret.BindPluginExtender = c
+		}
+		if e.PostBindPluginExtender != nil {
+			ret.PostBindPluginExtender = c
+		}
+	}
+	return ret
+}
+
+// extenderChain runs the extenders in the list which implement the extension point.
+type extenderChain struct {
+	list []plugin.PluginExtenders
+}
+
+func (c *extenderChain) BeforePreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	for _, e := range c.list {
+		if e.PreFilterPluginExtender == nil {
+			continue
+		}
+		if r, s := e.PreFilterPluginExtender.BeforePreFilter(ctx, state, pod); !s.IsSuccess() {
+			return r, s
+		}
+	}
+	return nil, nil
+}
+
+func (c *extenderChain) AfterPreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, preFilterResult *framework.PreFilterResult, preFilterStatus *framework.Status) (*framework.PreFilterResult, *framework.Status) {
+	for _, e := range c.list {
+		if e.PreFilterPluginExtender != nil {
+			preFilterResult, preFilterStatus = e.PreFilterPluginExtender.AfterPreFilter(ctx, state, pod, preFilterResult, preFilterStatus)
+		}
+	}
+	return preFilterResult, preFilterStatus
+}
+
+func (c *extenderChain) BeforeFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	for _, e := range c.list {
+		if e.FilterPluginExtender == nil {
+			continue
+		}
+		if s := e.FilterPluginExtender.BeforeFilter(ctx, state, pod, nodeInfo); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo, filterResult *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.FilterPluginExtender != nil {
+			filterResult = e.FilterPluginExtender.AfterFilter(ctx, state, pod, nodeInfo, filterResult)
+		}
+	}
+	return filterResult
+}
+
+func (c *extenderChain) BeforePostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusReader) (*framework.PostFilterResult, *framework.Status) {
+	for _, e := range c.list {
+		if e.PostFilterPluginExtender == nil {
+			continue
+		}
+		if r, s := e.PostFilterPluginExtender.BeforePostFilter(ctx, state, pod, filteredNodeStatusMap); !s.IsSuccess() {
+			return r, s
+		}
+	}
+	return nil, nil
+}
+
+func (c *extenderChain) AfterPostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusReader, postFilterResult *framework.PostFilterResult, status *framework.Status) (*framework.PostFilterResult, *framework.Status) {
+	for _, e := range c.list {
+		if e.PostFilterPluginExtender != nil {
+			postFilterResult, status = e.PostFilterPluginExtender.AfterPostFilter(ctx, state, pod, filteredNodeStatusMap, postFilterResult, status)
+		}
+	}
+	return postFilterResult, status
+}
+
+func (c *extenderChain) BeforePreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo) *framework.Status {
+	for _, e := range c.list {
+		if e.PreScorePluginExtender == nil {
+			continue
+		}
+		if s := e.PreScorePluginExtender.BeforePreScore(ctx, state, pod, nodes); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterPreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo, preScoreStatus *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.PreScorePluginExtender != nil {
+			preScoreStatus = e.PreScorePluginExtender.AfterPreScore(ctx, state, pod, nodes, preScoreStatus)
+		}
+	}
+	return preScoreStatus
+}
+
+func (c *extenderChain) BeforeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	for _, e := range c.list {
+		if e.ScorePluginExtender == nil {
+			continue
+		}
+		if score, s := e.ScorePluginExtender.BeforeScore(ctx, state, pod, nodeName); !s.IsSuccess() {
+			return score, s
+		}
+	}
+	return 0, nil
+}
+
+func (c *extenderChain) AfterScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string, score int64, scoreResult *framework.Status) (int64, *framework.Status) {
+	for _, e := range c.list {
+		if e.ScorePluginExtender != nil {
+			score, scoreResult = e.ScorePluginExtender.AfterScore(ctx, state, pod, nodeName, score, scoreResult)
+		}
+	}
+	return score, scoreResult
+}
+
+func (c *extenderChain) BeforeNormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	for _, e := range c.list {
+		if e.NormalizeScorePluginExtender == nil {
+			continue
+		}
+		if s := e.NormalizeScorePluginExtender.BeforeNormalizeScore(ctx, state, pod, scores); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterNormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList, normalizeScoreResult *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.NormalizeScorePluginExtender != nil {
+			normalizeScoreResult = e.NormalizeScorePluginExtender.AfterNormalizeScore(ctx, state, pod, scores, normalizeScoreResult)
+		}
+	}
+	return normalizeScoreResult
+}
+
+func (c *extenderChain) BeforeReserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	for _, e := range c.list {
+		if e.ReservePluginExtender == nil {
+			continue
+		}
+		if s := e.ReservePluginExtender.BeforeReserve(ctx, state, pod, nodename); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterReserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string, reserveStatus *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.ReservePluginExtender != nil {
+			reserveStatus = e.ReservePluginExtender.AfterReserve(ctx, state, pod, nodename, reserveStatus)
+		}
+	}
+	return reserveStatus
+}
+
+func (c *extenderChain) BeforeUnreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	for _, e := range c.list {
+		if e.ReservePluginExtender == nil {
+			continue
+		}
+		if s := e.ReservePluginExtender.BeforeUnreserve(ctx, state, pod, nodename); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterUnreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) {
+	for _, e := range c.list {
+		if e.ReservePluginExtender != nil {
+			e.ReservePluginExtender.AfterUnreserve(ctx, state, pod, nodename)
+		}
+	}
+}
+
+func (c *extenderChain) BeforePermit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	for _, e := range c.list {
+		if e.PermitPluginExtender == nil {
+			continue
+		}
+		if s, timeout := e.PermitPluginExtender.BeforePermit(ctx, state, pod, nodeName); !s.IsSuccess() {
+			return s, timeout
+		}
+	}
+	return nil, 0
+}
+
+func (c *extenderChain) AfterPermit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string, permitResult *framework.Status, timeout time.Duration) (*framework.Status, time.Duration) {
+	for _, e := range c.list {
+		if e.PermitPluginExtender != nil {
+			permitResult, timeout = e.PermitPluginExtender.AfterPermit(ctx, state, pod, nodeName, permitResult, timeout)
+		}
+	}
+	return permitResult, timeout
+}
+
+func (c *extenderChain) BeforePreBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	for _, e := range c.list {
+		if e.PreBindPluginExtender == nil {
+			continue
+		}
+		if s := e.PreBindPluginExtender.BeforePreBind(ctx, state, pod, nodename); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterPreBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string, bindResult *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.PreBindPluginExtender != nil {
+			bindResult = e.PreBindPluginExtender.AfterPreBind(ctx, state, pod, nodename, bindResult)
+		}
+	}
+	return bindResult
+}
+
+func (c *extenderChain) BeforeBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	for _, e := range c.list {
+		if e.BindPluginExtender == nil {
+			continue
+		}
+		if s := e.BindPluginExtender.BeforeBind(ctx, state, pod, nodename); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string, bindResult *framework.Status) *framework.Status {
+	for _, e := range c.list {
+		if e.BindPluginExtender != nil {
+			bindResult = e.BindPluginExtender.AfterBind(ctx, state, pod, nodename, bindResult)
+		}
+	}
+	return bindResult
+}
+
+func (c *extenderChain) BeforePostBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	for _, e := range c.list {
+		if e.PostBindPluginExtender == nil {
+			continue
+		}
+		if s := e.PostBindPluginExtender.BeforePostBind(ctx, state, pod, nodename); !s.IsSuccess() {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *extenderChain) AfterPostBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) {
+	for _, e := range c.list {
+		if e.PostBindPluginExtender != nil {
+			e.PostBindPluginExtender.AfterPostBind(ctx, state, pod, nodename)
+		}
+	}
+}
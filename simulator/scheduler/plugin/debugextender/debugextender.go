@@ -0,0 +1,240 @@
+// Package debugextender is the library of the plugin extenders for debugging.
+// They're attached to the plugins by pluginExtenders in the simulator config, instead of being compiled in.
+package debugextender
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+)
+
+// Result is what the extenders did to a plugin in the scheduling of a Pod.
+// The Results are recorded on the Pod's PluginExtenderResultAnnotationKey annotation by the plugin names.
+type Result struct {
+	// Filter has the reasons FailFilter and CELFilter rejected the Nodes with.
+	Filter map[string]string `json:"filter,omitempty"`
+	// Score has the scores OverrideScore and CELScore gave to the Nodes instead of the plugin.
+	Score map[string]int64 `json:"score,omitempty"`
+	// SampledNodes are the Nodes SampleNodes kept after PreFilter.
+	SampledNodes []string `json:"sampledNodes,omitempty"`
+	// NodeInfo has the NodeInfo the plugin got in Filter.
+	NodeInfo map[string]*breakpoint.NodeInfo `json:"nodeInfo,omitempty"`
+}
+
+const resultsStateKey framework.StateKey = "kube-scheduler-simulator.sigs.k8s.io/plugin-extender-result"
+
+// results keeps the Results of all the plugins in the CycleState of the scheduling.
+type results struct {
+	mu      sync.Mutex
+	plugins map[string]*Result
+}
+
+// Clone returns itself so that what the extenders do in the preemption, which runs Filter with the cloned CycleState, is recorded as well.
+func (r *results) Clone() framework.StateData {
+	return r
+}
+
+// resultsMu guards the creation of results shared by the extenders running in parallel.
+var resultsMu sync.Mutex
+
+func resultsOf(state *framework.CycleState) *results {
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	if d, err := state.Read(resultsStateKey); err == nil {
+		if r, ok := d.(*results); ok {
+			return r
+		}
+	}
+	r := &results{plugins: map[string]*Result{}}
+	state.Write(resultsStateKey, r)
+	return r
+}
+
+// New creates the PluginExtenderInitializers from the configs by the plugin names.
+// The extenders for the same plugin are chained in the order of the configs.
+func New(configs []v1alpha1.PluginExtender) (map[string]plugin.PluginExtenderInitializer, error) {
+	byPlugin := map[string][]v1alpha1.PluginExtender{}
+	for i := range configs {
+		if err := Validate(&configs[i]); err != nil {
+			return nil, xerrors.Errorf("pluginExtenders[%d]: %w", i, err)
+		}
+		byPlugin[configs[i].Plugin] = append(byPlugin[configs[i].Plugin], configs[i])
+	}
+
+	ret := make(map[string]plugin.PluginExtenderInitializer, len(byPlugin))
+	for name, cfgs := range byPlugin {
+		cfgs := cfgs
+		ret[name] = func(handle plugin.SimulatorHandle) plugin.PluginExtenders {
+			list := make([]plugin.PluginExtenders, 0, len(cfgs))
+			for i := range cfgs {
+				list = append(list, newExtenders(&cfgs[i], handle))
+			}
+			return chain(list)
+		}
+	}
+	return ret, nil
+}
+
+// Validate validates the PluginExtender.
+func Validate(e *v1alpha1.PluginExtender) error {
+	if e.Plugin == "" {
+		return xerrors.New("plugin is required")
+	}
+	if _, err := selector(e.PodSelector); err != nil {
+		return xerrors.Errorf("podSelector: %w", err)
+	}
+	if _, err := selector(e.NodeSelector); err != nil {
+		return xerrors.Errorf("nodeSelector: %w", err)
+	}
+	switch e.Type {
+	case v1alpha1.PluginExtenderFailFilter, v1alpha1.PluginExtenderLog, v1alpha1.PluginExtenderNodeInfoSnapshot:
+	case v1alpha1.PluginExtenderOverrideScore:
+		if e.Score < framework.MinNodeScore || e.Score > framework.MaxNodeScore {
+			return xerrors.Errorf("score must be between %d and %d", framework.MinNodeScore, framework.MaxNodeScore)
+		}
+	case v1alpha1.PluginExtenderSampleNodes:
+		if e.Nodes <= 0 {
+			return xerrors.Errorf("nodes must be positive, but got %d", e.Nodes)
+		}
+	case v1alpha1.PluginExtenderCELFilter:
+		if _, err := compileCEL(e.Expression, cel.BoolType); err != nil {
+			return err
+		}
+	case v1alpha1.PluginExtenderCELScore:
+		if _, err := compileCEL(e.Expression, cel.IntType); err != nil {
+			return err
+		}
+	default:
+		return xerrors.Errorf("unknown type %q", e.Type)
+	}
+	return nil
+}
+
+func selector(s *metav1.LabelSelector) (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(s)
+}
+
+func newExtenders(cfg *v1alpha1.PluginExtender, handle plugin.SimulatorHandle) plugin.PluginExtenders {
+	// The selectors are validated in New.
+	pod, _ := selector(cfg.PodSelector)
+	node, _ := selector(cfg.NodeSelector)
+	b := base{plugin: cfg.Plugin, handle: handle, pod: pod, node: node}
+
+	switch cfg.Type {
+	case v1alpha1.PluginExtenderFailFilter:
+		return plugin.PluginExtenders{FilterPluginExtender: &failFilter{base: b, reason: reasonOf(cfg)}}
+	case v1alpha1.PluginExtenderCELFilter:
+		// The expression is validated in New.
+		program, _ := compileCEL(cfg.Expression, cel.BoolType)
+		return plugin.PluginExtenders{FilterPluginExtender: &celFilter{base: b, program: program, reason: reasonOf(cfg)}}
+	case v1alpha1.PluginExtenderCELScore:
+		program, _ := compileCEL(cfg.Expression, cel.IntType)
+		return plugin.PluginExtenders{ScorePluginExtender: &celScore{base: b, program: program}}
+	case v1alpha1.PluginExtenderOverrideScore:
+		return plugin.PluginExtenders{ScorePluginExtender: &overrideScore{base: b, score: cfg.Score}}
+	case v1alpha1.PluginExtenderSampleNodes:
+		return plugin.PluginExtenders{PreFilterPluginExtender: &sampleNodes{base: b, nodes: cfg.Nodes}}
+	case v1alpha1.PluginExtenderNodeInfoSnapshot:
+		return plugin.PluginExtenders{FilterPluginExtender: &nodeInfoSnapshot{base: b}}
+	case v1alpha1.PluginExtenderLog:
+		l := &logger{base: b}
+		return plugin.PluginExtenders{
+			PreFilterPluginExtender:      l,
+			FilterPluginExtender:         l,
+			PostFilterPluginExtender:     l,
+			PreScorePluginExtender:       l,
+			ScorePluginExtender:          l,
+			NormalizeScorePluginExtender: l,
+			PermitPluginExtender:         l,
+			ReservePluginExtender:        l,
+			PreBindPluginExtender:        l,
+			BindPluginExtender:           l,
+			PostBindPluginExtender:       l,
+		}
+	}
+	return plugin.PluginExtenders{}
+}
+
+func reasonOf(cfg *v1alpha1.PluginExtender) string {
+	if cfg.Reason != "" {
+		return cfg.Reason
+	}
+	return "rejected by the " + string(cfg.Type) + " plugin extender"
+}
+
+// base is embedded in the extenders to match the Pods and the Nodes, and to record the Results.
+type base struct {
+	plugin    string
+	handle    plugin.SimulatorHandle
+	pod, node labels.Selector
+}
+
+func (b *base) matchPod(pod *v1.Pod) bool {
+	return b.pod.Matches(labels.Set(pod.Labels))
+}
+
+func (b *base) matchNodeInfo(nodeInfo *framework.NodeInfo) bool {
+	if b.node.Empty() {
+		return true
+	}
+	return nodeInfo.Node() != nil && b.node.Matches(labels.Set(nodeInfo.Node().Labels))
+}
+
+// matchNode gets the labels of the Node from the snapshot of the scheduling.
+// The Node doesn't match nodeSelector when it can't be got.
+func (b *base) matchNode(nodeName string) bool {
+	if b.node.Empty() {
+		return true
+	}
+	nodeInfo := b.nodeInfo(nodeName)
+	return nodeInfo != nil && b.matchNodeInfo(nodeInfo)
+}
+
+// nodeInfo gets the NodeInfo from the snapshot of the scheduling. It returns nil when it can't be got.
+func (b *base) nodeInfo(nodeName string) *framework.NodeInfo {
+	h := b.handle.FrameworkHandle()
+	if h == nil {
+		return nil
+	}
+	nodeInfo, err := h.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil
+	}
+	return nodeInfo
+}
+
+// record updates the Result of the plugin by fn, and adds the Results of all the plugins to the Pod.
+func (b *base) record(state *framework.CycleState, pod *v1.Pod, fn func(r *Result)) {
+	rs := resultsOf(state)
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	r, ok := rs.plugins[b.plugin]
+	if !ok {
+		r = &Result{}
+		rs.plugins[b.plugin] = r
+	}
+	fn(r)
+
+	j, err := json.Marshal(rs.plugins)
+	if err != nil {
+		klog.ErrorS(err, "failed to encode the results of the plugin extenders", "pod", klog.KObj(pod))
+		return
+	}
+	b.handle.AddCustomResult(pod.Namespace, pod.Name, annotation.PluginExtenderResultAnnotationKey, string(j))
+}
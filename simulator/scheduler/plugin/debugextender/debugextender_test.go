@@ -0,0 +1,431 @@
+package debugextender
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/backend/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/resultstore"
+)
+
+type fakeSimulatorHandle struct {
+	handle framework.Handle
+
+	mu      sync.Mutex
+	results map[string]string
+}
+
+func (h *fakeSimulatorHandle) AddCustomResult(_, _, annotationKey, result string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.results == nil {
+		h.results = map[string]string{}
+	}
+	h.results[annotationKey] = result
+}
+
+func (h *fakeSimulatorHandle) FrameworkHandle() framework.Handle {
+	return h.handle
+}
+
+// result decodes the Results recorded on the Pod.
+func (h *fakeSimulatorHandle) result(t *testing.T) map[string]*Result {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ret := map[string]*Result{}
+	require.NoError(t, json.Unmarshal([]byte(h.results[annotation.PluginExtenderResultAnnotationKey]), &ret))
+	return ret
+}
+
+// fakeFrameworkHandle only serves the snapshot.
+type fakeFrameworkHandle struct {
+	framework.Handle
+	snapshot *cache.Snapshot
+}
+
+func (h *fakeFrameworkHandle) SnapshotSharedLister() framework.SharedLister {
+	return h.snapshot
+}
+
+var (
+	gpuNode = &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-gpu", Labels: map[string]string{"gpu": "true"}}}
+	cpuNode = &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-cpu"}}
+	mlPod   = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", UID: "uid1", Labels: map[string]string{"app": "ml"}}}
+	webPod  = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default", UID: "uid2", Labels: map[string]string{"app": "web"}}}
+)
+
+func nodeInfo(node *v1.Node) *framework.NodeInfo {
+	n := framework.NewNodeInfo()
+	n.SetNode(node)
+	return n
+}
+
+func newExtendersFor(t *testing.T, pluginName string, configs []v1alpha1.PluginExtender, nodes ...*v1.Node) (plugin.PluginExtenders, *fakeSimulatorHandle) {
+	t.Helper()
+	initializers, err := New(configs)
+	require.NoError(t, err)
+	h := &fakeSimulatorHandle{handle: &fakeFrameworkHandle{snapshot: cache.NewSnapshot(nil, nodes)}}
+	return initializers[pluginName](h), h
+}
+
+// fakeNonNormalizingScorePlugin gives the same score to all Nodes and doesn't have NormalizeScore like ImageLocality.
+type fakeNonNormalizingScorePlugin struct{ score int64 }
+
+func (pl *fakeNonNormalizingScorePlugin) Name() string { return "ImageLocality" }
+
+func (pl *fakeNonNormalizingScorePlugin) Score(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) (int64, *framework.Status) {
+	return pl.score, nil
+}
+
+func (pl *fakeNonNormalizingScorePlugin) ScoreExtensions() framework.ScoreExtensions { return nil }
+
+// snapshotHandle gives the snapshot to the extenders in the wrapped plugin.
+type snapshotHandle struct {
+	plugin.SimulatorHandle
+	handle framework.Handle
+}
+
+func (h *snapshotHandle) FrameworkHandle() framework.Handle {
+	return h.handle
+}
+
+// finalScoresOf runs Score of the wrapped non-normalizing plugin with the extenders on the Nodes,
+// and returns the final scores recorded on the Pod.
+func finalScoresOf(t *testing.T, configs []v1alpha1.PluginExtender, pod *v1.Pod, nodes ...*v1.Node) map[string]map[string]string {
+	t.Helper()
+	initializers, err := New(configs)
+	require.NoError(t, err)
+	store := resultstore.New(map[string]int32{"ImageLocality": 1})
+	pl, ok := plugin.NewWrappedPlugin(store, &fakeNonNormalizingScorePlugin{score: 10}, plugin.WithExtendersOption(func(h plugin.SimulatorHandle) plugin.PluginExtenders {
+		return initializers["ImageLocality"](&snapshotHandle{SimulatorHandle: h, handle: &fakeFrameworkHandle{snapshot: cache.NewSnapshot(nil, nodes)}})
+	})).(framework.ScorePlugin)
+	require.True(t, ok)
+	require.Nil(t, pl.ScoreExtensions())
+
+	state := framework.NewCycleState()
+	for _, n := range nodes {
+		_, s := pl.Score(context.Background(), state, pod, n.Name)
+		require.True(t, s.IsSuccess())
+	}
+	ret := map[string]map[string]string{}
+	require.NoError(t, json.Unmarshal([]byte(store.GetStoredResult(pod)[annotation.FinalScoreResultAnnotationKey]), &ret))
+	return ret
+}
+
+func TestFailFilterAndNodeInfoSnapshot(t *testing.T) {
+	t.Parallel()
+	extenders, h := newExtendersFor(t, "NodeResourcesFit", []v1alpha1.PluginExtender{
+		{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderNodeInfoSnapshot},
+		{
+			Plugin:       "NodeResourcesFit",
+			Type:         v1alpha1.PluginExtenderFailFilter,
+			PodSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ml"}},
+			NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+			Reason:       "no GPU",
+		},
+	})
+	require.NotNil(t, extenders.FilterPluginExtender)
+	assert.Nil(t, extenders.ScorePluginExtender)
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	e := extenders.FilterPluginExtender
+	for _, n := range []*v1.Node{gpuNode, cpuNode} {
+		require.True(t, e.BeforeFilter(ctx, state, mlPod, nodeInfo(n)).IsSuccess())
+	}
+	assert.True(t, e.AfterFilter(ctx, state, mlPod, nodeInfo(gpuNode), nil).IsSuccess())
+	s := e.AfterFilter(ctx, state, mlPod, nodeInfo(cpuNode), nil)
+	assert.Equal(t, framework.Unschedulable, s.Code())
+	assert.Equal(t, "no GPU", s.Message())
+	// The status of the plugin is kept when it rejects the Node by itself.
+	s = e.AfterFilter(ctx, state, mlPod, nodeInfo(cpuNode), framework.NewStatus(framework.UnschedulableAndUnresolvable, "too many pods"))
+	assert.Equal(t, "too many pods", s.Message())
+	// Other Pods pass.
+	assert.True(t, e.AfterFilter(ctx, framework.NewCycleState(), webPod, nodeInfo(cpuNode), nil).IsSuccess())
+
+	got := h.result(t)["NodeResourcesFit"]
+	require.NotNil(t, got)
+	assert.Equal(t, map[string]string{"node-cpu": "no GPU"}, got.Filter)
+	assert.Len(t, got.NodeInfo, 2)
+	assert.Equal(t, "node-gpu", got.NodeInfo["node-gpu"].Name)
+}
+
+func TestOverrideScore(t *testing.T) {
+	t.Parallel()
+	extenders, h := newExtendersFor(t, "ImageLocality", []v1alpha1.PluginExtender{{
+		Plugin:       "ImageLocality",
+		Type:         v1alpha1.PluginExtenderOverrideScore,
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+		Score:        100,
+	}}, gpuNode, cpuNode)
+	require.NotNil(t, extenders.ScorePluginExtender)
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	e := extenders.ScorePluginExtender
+	score, s := e.AfterScore(ctx, state, mlPod, "node-gpu", 10, nil)
+	assert.True(t, s.IsSuccess())
+	assert.Equal(t, int64(100), score)
+	score, _ = e.AfterScore(ctx, state, mlPod, "node-cpu", 10, nil)
+	assert.Equal(t, int64(10), score)
+	// The Node not in the snapshot doesn't match nodeSelector.
+	score, _ = e.AfterScore(ctx, state, mlPod, "node-unknown", 10, nil)
+	assert.Equal(t, int64(10), score)
+
+	assert.Equal(t, map[string]int64{"node-gpu": 100}, h.result(t)["ImageLocality"].Score)
+}
+
+func TestOverrideScore_NonNormalizingPlugin(t *testing.T) {
+	t.Parallel()
+	got := finalScoresOf(t, []v1alpha1.PluginExtender{{
+		Plugin:       "ImageLocality",
+		Type:         v1alpha1.PluginExtenderOverrideScore,
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+		Score:        100,
+	}}, mlPod, gpuNode, cpuNode)
+
+	// The overridden score is recorded as the final score since the plugin doesn't normalize the scores.
+	assert.Equal(t, map[string]map[string]string{
+		"node-gpu": {"ImageLocality": "100"},
+		"node-cpu": {"ImageLocality": "10"},
+	}, got)
+}
+
+func TestSampleNodes(t *testing.T) {
+	t.Parallel()
+	nodes := []*v1.Node{gpuNode, cpuNode}
+	for _, name := range []string{"node-a", "node-b", "node-c"} {
+		nodes = append(nodes, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	extenders, h := newExtendersFor(t, "NodeAffinity", []v1alpha1.PluginExtender{{
+		Plugin: "NodeAffinity",
+		Type:   v1alpha1.PluginExtenderSampleNodes,
+		Nodes:  2,
+	}}, nodes...)
+	require.NotNil(t, extenders.PreFilterPluginExtender)
+	ctx := context.Background()
+	e := extenders.PreFilterPluginExtender
+
+	t.Run("sample all the Nodes", func(t *testing.T) {
+		t.Parallel()
+		got, s := e.AfterPreFilter(ctx, framework.NewCycleState(), mlPod, nil, nil)
+		assert.True(t, s.IsSuccess())
+		require.NotNil(t, got)
+		assert.Len(t, got.NodeNames, 2)
+		again, _ := e.AfterPreFilter(ctx, framework.NewCycleState(), mlPod, nil, nil)
+		assert.Equal(t, got.NodeNames, again.NodeNames, "the same Nodes should be sampled for the same Pod")
+		assert.Len(t, h.result(t)["NodeAffinity"].SampledNodes, 2)
+	})
+	t.Run("sample the Nodes in the result of the plugin", func(t *testing.T) {
+		t.Parallel()
+		candidates := sets.New("node-a", "node-b", "node-c")
+		got, _ := e.AfterPreFilter(ctx, framework.NewCycleState(), webPod, &framework.PreFilterResult{NodeNames: candidates.Clone()}, nil)
+		require.NotNil(t, got)
+		assert.Len(t, got.NodeNames, 2)
+		assert.True(t, candidates.IsSuperset(got.NodeNames))
+	})
+	t.Run("keep the result when the plugin is skipped", func(t *testing.T) {
+		t.Parallel()
+		got, s := e.AfterPreFilter(ctx, framework.NewCycleState(), mlPod, nil, framework.NewStatus(framework.Skip))
+		assert.Nil(t, got)
+		assert.True(t, s.IsSkip())
+	})
+}
+
+func TestCELFilter(t *testing.T) {
+	t.Parallel()
+	extenders, h := newExtendersFor(t, "NodeResourcesFit", []v1alpha1.PluginExtender{{
+		Plugin:     "NodeResourcesFit",
+		Type:       v1alpha1.PluginExtenderCELFilter,
+		Expression: `pod.metadata.labels["app"] != "ml" || (has(node.metadata.labels) && "gpu" in node.metadata.labels)`,
+		Reason:     "no GPU",
+	}})
+	require.NotNil(t, extenders.FilterPluginExtender)
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	e := extenders.FilterPluginExtender
+	assert.True(t, e.BeforeFilter(ctx, state, mlPod, nodeInfo(gpuNode)).IsSuccess())
+	s := e.BeforeFilter(ctx, state, mlPod, nodeInfo(cpuNode))
+	assert.Equal(t, framework.Unschedulable, s.Code())
+	assert.Equal(t, "no GPU", s.Message())
+	assert.True(t, e.BeforeFilter(ctx, framework.NewCycleState(), webPod, nodeInfo(cpuNode)).IsSuccess())
+
+	assert.Equal(t, map[string]string{"node-cpu": "no GPU"}, h.result(t)["NodeResourcesFit"].Filter)
+}
+
+func TestCELScore(t *testing.T) {
+	t.Parallel()
+	nodeWithMemory := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-mem"},
+		Status:     v1.NodeStatus{Allocatable: v1.ResourceList{v1.ResourceMemory: resource.MustParse("16Gi")}},
+	}
+	extenders, h := newExtendersFor(t, "NodeResourcesFit", []v1alpha1.PluginExtender{{
+		Plugin:     "NodeResourcesFit",
+		Type:       v1alpha1.PluginExtenderCELScore,
+		Expression: `has(node.status.allocatable) && quantity(node.status.allocatable.memory).isGreaterThan(quantity("8Gi")) ? 100 : score / 2`,
+	}}, nodeWithMemory, cpuNode)
+	require.NotNil(t, extenders.ScorePluginExtender)
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	e := extenders.ScorePluginExtender
+	score, s := e.AfterScore(ctx, state, mlPod, "node-mem", 10, nil)
+	assert.True(t, s.IsSuccess())
+	assert.Equal(t, int64(100), score)
+	score, _ = e.AfterScore(ctx, state, mlPod, "node-cpu", 10, nil)
+	assert.Equal(t, int64(5), score)
+	// The score is kept when the Node isn't in the snapshot.
+	score, _ = e.AfterScore(ctx, state, mlPod, "node-unknown", 10, nil)
+	assert.Equal(t, int64(10), score)
+
+	assert.Equal(t, map[string]int64{"node-mem": 100, "node-cpu": 5}, h.result(t)["NodeResourcesFit"].Score)
+}
+
+func TestCELScore_OutOfRange(t *testing.T) {
+	t.Parallel()
+	extenders, _ := newExtendersFor(t, "NodeResourcesFit", []v1alpha1.PluginExtender{{
+		Plugin:     "NodeResourcesFit",
+		Type:       v1alpha1.PluginExtenderCELScore,
+		Expression: `score * 100`,
+	}}, cpuNode)
+
+	// The score of the plugin is kept when the expression is out of the range.
+	score, s := extenders.ScorePluginExtender.AfterScore(context.Background(), framework.NewCycleState(), mlPod, "node-cpu", 10, nil)
+	assert.True(t, s.IsSuccess())
+	assert.Equal(t, int64(10), score)
+}
+
+func TestCELScore_NonNormalizingPlugin(t *testing.T) {
+	t.Parallel()
+	got := finalScoresOf(t, []v1alpha1.PluginExtender{{
+		Plugin:     "ImageLocality",
+		Type:       v1alpha1.PluginExtenderCELScore,
+		Expression: `has(node.metadata.labels) && node.metadata.labels.gpu == "true" ? score * 3 : score / 2`,
+	}}, mlPod, gpuNode, cpuNode)
+
+	// The score given by the expression is recorded as the final score since the plugin doesn't normalize the scores.
+	assert.Equal(t, map[string]map[string]string{
+		"node-gpu": {"ImageLocality": "30"},
+		"node-cpu": {"ImageLocality": "5"},
+	}, got)
+}
+
+type rejectFilter struct{ reason string }
+
+func (e *rejectFilter) BeforeFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo) *framework.Status {
+	return framework.NewStatus(framework.Unschedulable, e.reason)
+}
+
+func (e *rejectFilter) AfterFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo, _ *framework.Status) *framework.Status {
+	return framework.NewStatus(framework.Unschedulable, e.reason)
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+	debugExtenders, err := New([]v1alpha1.PluginExtender{{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderFailFilter, Reason: "injected"}})
+	require.NoError(t, err)
+	userExtenders := map[string]plugin.PluginExtenderInitializer{
+		"NodeResourcesFit": func(_ plugin.SimulatorHandle) plugin.PluginExtenders {
+			return plugin.PluginExtenders{FilterPluginExtender: &rejectFilter{reason: "user"}}
+		},
+		"NodeAffinity": func(_ plugin.SimulatorHandle) plugin.PluginExtenders {
+			return plugin.PluginExtenders{FilterPluginExtender: &rejectFilter{reason: "user"}}
+		},
+	}
+
+	merged := Merge(userExtenders, debugExtenders)
+	require.Len(t, merged, 2)
+
+	e := merged["NodeResourcesFit"](&fakeSimulatorHandle{}).FilterPluginExtender
+	require.NotNil(t, e)
+	ctx := context.Background()
+	// The Before hooks stop at the user's extender.
+	assert.Equal(t, "user", e.BeforeFilter(ctx, framework.NewCycleState(), mlPod, nodeInfo(cpuNode)).Message())
+	// The After hook of FailFilter is given the rejection of the user's extender, and keeps it.
+	assert.Equal(t, "user", e.AfterFilter(ctx, framework.NewCycleState(), mlPod, nodeInfo(cpuNode), nil).Message())
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		extender v1alpha1.PluginExtender
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderOverrideScore, Score: 100},
+		},
+		{
+			name:     "no plugin",
+			extender: v1alpha1.PluginExtender{Type: v1alpha1.PluginExtenderLog},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown type",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: "Unknown"},
+			wantErr:  true,
+		},
+		{
+			name:     "score out of range",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderOverrideScore, Score: 101},
+			wantErr:  true,
+		},
+		{
+			name:     "no nodes to sample",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderSampleNodes},
+			wantErr:  true,
+		},
+		{
+			name:     "valid CEL expression",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderCELScore, Expression: `node.metadata.name == "node1" ? 100 : score`},
+		},
+		{
+			name:     "CEL expression of the wrong type",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderCELFilter, Expression: `score`},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid CEL expression",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderCELFilter, Expression: `node.metadata.name ==`},
+			wantErr:  true,
+		},
+		{
+			name:     "no CEL expression",
+			extender: v1alpha1.PluginExtender{Plugin: "NodeResourcesFit", Type: v1alpha1.PluginExtenderCELFilter},
+			wantErr:  true,
+		},
+		{
+			name: "invalid selector",
+			extender: v1alpha1.PluginExtender{
+				Plugin:      "NodeResourcesFit",
+				Type:        v1alpha1.PluginExtenderLog,
+				PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := Validate(&tt.extender)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package debugextender
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+)
+
+// failFilter rejects the Nodes the plugin passes.
+// The plugin still runs, so that its own result is recorded in the filter result as well.
+type failFilter struct {
+	base
+	reason string
+}
+
+func (e *failFilter) BeforeFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (e *failFilter) AfterFilter(_ context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo, filterResult *framework.Status) *framework.Status {
+	if !filterResult.IsSuccess() || !e.matchPod(pod) || !e.matchNodeInfo(nodeInfo) {
+		return filterResult
+	}
+	e.record(state, pod, func(r *Result) {
+		if r.Filter == nil {
+			r.Filter = map[string]string{}
+		}
+		r.Filter[nodeInfo.Node().Name] = e.reason
+	})
+	return framework.NewStatus(framework.Unschedulable, e.reason)
+}
+
+// overrideScore replaces the score the plugin gives.
+// The score is still normalized by NormalizeScore of the plugin, if any.
+type overrideScore struct {
+	base
+	score int64
+}
+
+func (e *overrideScore) BeforeScore(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) (int64, *framework.Status) {
+	return 0, nil
+}
+
+func (e *overrideScore) AfterScore(_ context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string, score int64, scoreResult *framework.Status) (int64, *framework.Status) {
+	if !scoreResult.IsSuccess() || !e.matchPod(pod) || !e.matchNode(nodeName) {
+		return score, scoreResult
+	}
+	e.record(state, pod, func(r *Result) {
+		if r.Score == nil {
+			r.Score = map[string]int64{}
+		}
+		r.Score[nodeName] = e.score
+	})
+	return e.score, scoreResult
+}
+
+// sampleNodes narrows down the Nodes in the PreFilterResult of the plugin,
+// or all the Nodes when the plugin doesn't narrow down them.
+// The Nodes are picked by the hash of the Pod's UID and their names, so that the same Nodes are picked on the retries.
+type sampleNodes struct {
+	base
+	nodes int
+}
+
+func (e *sampleNodes) BeforePreFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	return nil, nil
+}
+
+func (e *sampleNodes) AfterPreFilter(_ context.Context, state *framework.CycleState, pod *v1.Pod, preFilterResult *framework.PreFilterResult, preFilterStatus *framework.Status) (*framework.PreFilterResult, *framework.Status) {
+	// The PreFilterResult is ignored by the scheduler when the status isn't success, e.g., Skip.
+	if !preFilterStatus.IsSuccess() || !e.matchPod(pod) {
+		return preFilterResult, preFilterStatus
+	}
+
+	var candidates []string
+	if !preFilterResult.AllNodes() {
+		candidates = preFilterResult.NodeNames.UnsortedList()
+	} else {
+		h := e.handle.FrameworkHandle()
+		if h == nil {
+			return preFilterResult, preFilterStatus
+		}
+		nodeInfos, err := h.SnapshotSharedLister().NodeInfos().List()
+		if err != nil {
+			klog.ErrorS(err, "failed to list the Nodes to sample", "plugin", e.plugin, "pod", klog.KObj(pod))
+			return preFilterResult, preFilterStatus
+		}
+		for _, n := range nodeInfos {
+			candidates = append(candidates, n.Node().Name)
+		}
+	}
+	if len(candidates) <= e.nodes {
+		return preFilterResult, preFilterStatus
+	}
+
+	sampled := pickNodes(string(pod.UID), candidates, e.nodes)
+	e.record(state, pod, func(r *Result) {
+		r.SampledNodes = sampled
+	})
+	return &framework.PreFilterResult{NodeNames: sets.New(sampled...)}, preFilterStatus
+}
+
+// pickNodes picks n Nodes in the order of the hash of the seed and their names, and returns them sorted by the names.
+func pickNodes(seed string, nodeNames []string, n int) []string {
+	hashes := make(map[string]uint64, len(nodeNames))
+	for _, name := range nodeNames {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(seed + "/" + name))
+		hashes[name] = h.Sum64()
+	}
+	sort.Slice(nodeNames, func(i, j int) bool {
+		if hashes[nodeNames[i]] != hashes[nodeNames[j]] {
+			return hashes[nodeNames[i]] < hashes[nodeNames[j]]
+		}
+		return nodeNames[i] < nodeNames[j]
+	})
+	ret := append([]string{}, nodeNames[:n]...)
+	sort.Strings(ret)
+	return ret
+}
+
+// nodeInfoSnapshot records the NodeInfo given to Filter of the plugin.
+type nodeInfoSnapshot struct {
+	base
+}
+
+func (e *nodeInfoSnapshot) BeforeFilter(_ context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if !e.matchPod(pod) || !e.matchNodeInfo(nodeInfo) {
+		return nil
+	}
+	snapshot := breakpoint.NewNodeInfo(nodeInfo)
+	if snapshot == nil {
+		return nil
+	}
+	e.record(state, pod, func(r *Result) {
+		if r.NodeInfo == nil {
+			r.NodeInfo = map[string]*breakpoint.NodeInfo{}
+		}
+		r.NodeInfo[snapshot.Name] = snapshot
+	})
+	return nil
+}
+
+func (e *nodeInfoSnapshot) AfterFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo, filterResult *framework.Status) *framework.Status {
+	return filterResult
+}
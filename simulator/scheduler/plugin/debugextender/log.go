@@ -0,0 +1,168 @@
+package debugextender
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// logger logs the inputs and the outputs of the plugin at all the extension points.
+// nodeSelector is applied to the extension points for a Node.
+type logger struct {
+	base
+}
+
+func (e *logger) log(point string, pod *v1.Pod, status *framework.Status, keysAndValues ...interface{}) {
+	klog.InfoS("Plugin extender log", append([]interface{}{"plugin", e.plugin, "extensionPoint", point, "pod", klog.KObj(pod), "status", statusString(status)}, keysAndValues...)...)
+}
+
+func statusString(s *framework.Status) string {
+	if s.IsSuccess() {
+		return framework.Success.String()
+	}
+	return s.Code().String() + ": " + s.Message()
+}
+
+func (e *logger) BeforePreFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	return nil, nil
+}
+
+func (e *logger) AfterPreFilter(_ context.Context, _ *framework.CycleState, pod *v1.Pod, preFilterResult *framework.PreFilterResult, preFilterStatus *framework.Status) (*framework.PreFilterResult, *framework.Status) {
+	if e.matchPod(pod) {
+		var nodes interface{} = "all"
+		if !preFilterResult.AllNodes() {
+			names := preFilterResult.NodeNames.UnsortedList()
+			sort.Strings(names)
+			nodes = names
+		}
+		e.log("PreFilter", pod, preFilterStatus, "nodes", nodes)
+	}
+	return preFilterResult, preFilterStatus
+}
+
+func (e *logger) BeforeFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterFilter(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo, filterResult *framework.Status) *framework.Status {
+	if e.matchPod(pod) && e.matchNodeInfo(nodeInfo) {
+		e.log("Filter", pod, filterResult, "node", nodeInfo.Node().Name, "pods", len(nodeInfo.Pods), "requested", nodeInfo.Requested, "allocatable", nodeInfo.Allocatable)
+	}
+	return filterResult
+}
+
+func (e *logger) BeforePostFilter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ framework.NodeToStatusReader) (*framework.PostFilterResult, *framework.Status) {
+	return nil, nil
+}
+
+func (e *logger) AfterPostFilter(_ context.Context, _ *framework.CycleState, pod *v1.Pod, _ framework.NodeToStatusReader, postFilterResult *framework.PostFilterResult, status *framework.Status) (*framework.PostFilterResult, *framework.Status) {
+	if e.matchPod(pod) {
+		var nominated string
+		if postFilterResult != nil && postFilterResult.NominatingInfo != nil {
+			nominated = postFilterResult.NominatedNodeName
+		}
+		e.log("PostFilter", pod, status, "nominatedNode", nominated)
+	}
+	return postFilterResult, status
+}
+
+func (e *logger) BeforePreScore(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ []*framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterPreScore(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo, preScoreStatus *framework.Status) *framework.Status {
+	if e.matchPod(pod) {
+		e.log("PreScore", pod, preScoreStatus, "nodes", len(nodes))
+	}
+	return preScoreStatus
+}
+
+func (e *logger) BeforeScore(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) (int64, *framework.Status) {
+	return 0, nil
+}
+
+func (e *logger) AfterScore(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string, score int64, scoreResult *framework.Status) (int64, *framework.Status) {
+	if e.matchPod(pod) && e.matchNode(nodeName) {
+		e.log("Score", pod, scoreResult, "node", nodeName, "score", score)
+	}
+	return score, scoreResult
+}
+
+func (e *logger) BeforeNormalizeScore(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ framework.NodeScoreList) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterNormalizeScore(_ context.Context, _ *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList, normalizeScoreResult *framework.Status) *framework.Status {
+	if e.matchPod(pod) {
+		e.log("NormalizeScore", pod, normalizeScoreResult, "scores", scores)
+	}
+	return normalizeScoreResult
+}
+
+func (e *logger) BeforeReserve(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterReserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodename string, reserveStatus *framework.Status) *framework.Status {
+	if e.matchPod(pod) && e.matchNode(nodename) {
+		e.log("Reserve", pod, reserveStatus, "node", nodename)
+	}
+	return reserveStatus
+}
+
+func (e *logger) BeforeUnreserve(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterUnreserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodename string) {
+	if e.matchPod(pod) && e.matchNode(nodename) {
+		e.log("Unreserve", pod, nil, "node", nodename)
+	}
+}
+
+func (e *logger) BeforePermit(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) (*framework.Status, time.Duration) {
+	return nil, 0
+}
+
+func (e *logger) AfterPermit(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string, permitResult *framework.Status, timeout time.Duration) (*framework.Status, time.Duration) {
+	if e.matchPod(pod) && e.matchNode(nodeName) {
+		e.log("Permit", pod, permitResult, "node", nodeName, "timeout", timeout)
+	}
+	return permitResult, timeout
+}
+
+func (e *logger) BeforePreBind(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterPreBind(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodename string, bindResult *framework.Status) *framework.Status {
+	if e.matchPod(pod) && e.matchNode(nodename) {
+		e.log("PreBind", pod, bindResult, "node", nodename)
+	}
+	return bindResult
+}
+
+func (e *logger) BeforeBind(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterBind(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodename string, bindResult *framework.Status) *framework.Status {
+	if e.matchPod(pod) && e.matchNode(nodename) {
+		e.log("Bind", pod, bindResult, "node", nodename)
+	}
+	return bindResult
+}
+
+func (e *logger) BeforePostBind(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ string) *framework.Status {
+	return nil
+}
+
+func (e *logger) AfterPostBind(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodename string) {
+	if e.matchPod(pod) && e.matchNode(nodename) {
+		e.log("PostBind", pod, nil, "node", nodename)
+	}
+}
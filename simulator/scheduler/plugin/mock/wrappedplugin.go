@@ -67,6 +67,18 @@ func (mr *MockStoreMockRecorder) AddCustomResult(namespace, podName, annotationK
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCustomResult", reflect.TypeOf((*MockStore)(nil).AddCustomResult), namespace, podName, annotationKey, result)
 }
 
+// AddCycleStateResult mocks base method.
+func (m *MockStore) AddCycleStateResult(namespace, podName, stateKey, entry string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddCycleStateResult", namespace, podName, stateKey, entry)
+}
+
+// AddCycleStateResult indicates an expected call of AddCycleStateResult.
+func (mr *MockStoreMockRecorder) AddCycleStateResult(namespace, podName, stateKey, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCycleStateResult", reflect.TypeOf((*MockStore)(nil).AddCycleStateResult), namespace, podName, stateKey, entry)
+}
+
 // AddFilterResult mocks base method.
 func (m *MockStore) AddFilterResult(namespace, podName, nodeName, pluginName, reason string) {
 	m.ctrl.T.Helper()
@@ -79,6 +91,18 @@ func (mr *MockStoreMockRecorder) AddFilterResult(namespace, podName, nodeName, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilterResult", reflect.TypeOf((*MockStore)(nil).AddFilterResult), namespace, podName, nodeName, pluginName, reason)
 }
 
+// AddGangPermitResult mocks base method.
+func (m *MockStore) AddGangPermitResult(namespace, podName, pluginName, group, status string, timeout time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddGangPermitResult", namespace, podName, pluginName, group, status, timeout)
+}
+
+// AddGangPermitResult indicates an expected call of AddGangPermitResult.
+func (mr *MockStoreMockRecorder) AddGangPermitResult(namespace, podName, pluginName, group, status, timeout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGangPermitResult", reflect.TypeOf((*MockStore)(nil).AddGangPermitResult), namespace, podName, pluginName, group, status, timeout)
+}
+
 // AddNormalizedScoreResult mocks base method.
 func (m *MockStore) AddNormalizedScoreResult(namespace, podName, nodeName, pluginName string, normalizedscore int64) {
 	m.ctrl.T.Helper()
@@ -187,6 +211,18 @@ func (mr *MockStoreMockRecorder) AddSelectedNode(namespace, podName, nodeName an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSelectedNode", reflect.TypeOf((*MockStore)(nil).AddSelectedNode), namespace, podName, nodeName)
 }
 
+// FinishGangWaiting mocks base method.
+func (m *MockStore) FinishGangWaiting(namespace, podName string, admitted bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FinishGangWaiting", namespace, podName, admitted)
+}
+
+// FinishGangWaiting indicates an expected call of FinishGangWaiting.
+func (mr *MockStoreMockRecorder) FinishGangWaiting(namespace, podName, admitted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishGangWaiting", reflect.TypeOf((*MockStore)(nil).FinishGangWaiting), namespace, podName, admitted)
+}
+
 // MockPreFilterPluginExtender is a mock of PreFilterPluginExtender interface.
 type MockPreFilterPluginExtender struct {
 	ctrl     *gomock.Controller
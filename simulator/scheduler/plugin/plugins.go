@@ -20,15 +20,70 @@ import (
 )
 
 // ResultStoreKey represents key name of plugins results on sharedstore.
+// The result store of each profile is added with the key suffixed with the scheduler name of the profile,
+// e.g., "PluginResultStoreKey/default-scheduler".
 const ResultStoreKey = "PluginResultStoreKey"
 
-func NewRegistry(sharedStore storereflector.Reflector, cfg *schedulerConfig.KubeSchedulerConfiguration, pluginExtenders map[string]PluginExtenderInitializer) (map[string]schedulerRuntime.PluginFactory, error) {
-	scorePluginWeight := getScorePluginWeight(cfg)
-	store := schedulingresultstore.New(scorePluginWeight)
-	// Add the resultStore to the sharedStore to store the results and share it.
-	sharedStore.AddResultStore(store, ResultStoreKey)
+// ResultStores are the result stores of the wrapped plugins, one for each profile.
+// The plugins in a profile record their results in the store of the profile,
+// so that the final scores are calculated with the weights in the profile.
+type ResultStores struct {
+	// stores are keyed by the scheduler name of the profile.
+	stores map[string]*schedulingresultstore.Store
+	// defaultProfile is the scheduler name of the first profile,
+	// whose store is used when the profile of the plugin is unknown.
+	defaultProfile string
+}
+
+// newResultStores creates the result store for each profile in cfg.
+func newResultStores(cfg *schedulerConfig.KubeSchedulerConfiguration) *ResultStores {
+	s := &ResultStores{stores: make(map[string]*schedulingresultstore.Store, len(cfg.Profiles))}
+	for i := range cfg.Profiles {
+		profile := &cfg.Profiles[i]
+		if i == 0 {
+			s.defaultProfile = profile.SchedulerName
+		}
+		s.stores[profile.SchedulerName] = schedulingresultstore.NewForProfile(profile.SchedulerName, getScorePluginWeight(profile))
+	}
+	if len(s.stores) == 0 {
+		s.stores[s.defaultProfile] = schedulingresultstore.New(map[string]int32{})
+	}
+	return s
+}
+
+// Get returns the result store of the profile with the scheduler name.
+// It returns the store of the first profile when no profile has the scheduler name.
+func (s *ResultStores) Get(profile string) *schedulingresultstore.Store {
+	if store, ok := s.stores[profile]; ok {
+		return store
+	}
+	return s.stores[s.defaultProfile]
+}
+
+// forHandle returns the result store of the profile the framework handle is for.
+func (s *ResultStores) forHandle(h framework.Handle) *schedulingresultstore.Store {
+	return s.Get(s.profileOf(h))
+}
+
+// profileOf returns the scheduler name of the profile the framework handle is for.
+func (s *ResultStores) profileOf(h framework.Handle) string {
+	// The handle given to the plugin factories is the framework of the profile.
+	if fwk, ok := h.(interface{ ProfileName() string }); ok {
+		return fwk.ProfileName()
+	}
+	return s.defaultProfile
+}
+
+// NewRegistry creates the registry of the wrapped plugins, and adds their result stores to the sharedStore.
+// opts are applied to all wrapped plugins, e.g., WithBreakpointsOption.
+func NewRegistry(sharedStore storereflector.Reflector, cfg *schedulerConfig.KubeSchedulerConfiguration, pluginExtenders map[string]PluginExtenderInitializer, opts ...Option) (map[string]schedulerRuntime.PluginFactory, error) {
+	stores := newResultStores(cfg)
+	// Add the resultStores to the sharedStore to store the results and share them.
+	for profile, store := range stores.stores {
+		sharedStore.AddResultStore(store, ResultStoreKey+"/"+profile)
+	}
 
-	ret, err := newPluginFactories(store, pluginExtenders)
+	ret, err := newPluginFactories(stores, pluginExtenders, opts...)
 	if err != nil {
 		return nil, xerrors.Errorf("New pluginFactories: %w", err)
 	}
@@ -36,9 +91,36 @@ func NewRegistry(sharedStore storereflector.Reflector, cfg *schedulerConfig.Kube
 	return ret, nil
 }
 
-func newPluginFactories(store *schedulingresultstore.Store, pluginExtenders map[string]PluginExtenderInitializer) (map[string]schedulerRuntime.PluginFactory, error) {
+// NewRegistryWithResultStore creates the registry of the wrapped plugins in the same way as NewRegistry,
+// but it returns the result stores instead of adding them to the sharedStore.
+// It's for running scheduling cycles outside the scheduler (e.g., dry-run), whose results must not be reflected on Pods.
+func NewRegistryWithResultStore(cfg *schedulerConfig.KubeSchedulerConfiguration, pluginExtenders map[string]PluginExtenderInitializer) (map[string]schedulerRuntime.PluginFactory, *ResultStores, error) {
+	stores := newResultStores(cfg)
+
+	ret, err := newPluginFactories(stores, pluginExtenders)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("New pluginFactories: %w", err)
+	}
+
+	return ret, stores, nil
+}
+
+func newPluginFactories(stores *ResultStores, pluginExtenders map[string]PluginExtenderInitializer, commonOpts ...Option) (map[string]schedulerRuntime.PluginFactory, error) {
 	intreeRegistries := config.InTreeRegistries()
-	outoftreeRegistries := config.OutOfTreeRegistries()
+	// The plugins configured in a profile, e.g., wasm plugins, are only registered for the profile.
+	outoftreeRegistries := make(map[string]schedulerRuntime.Registry, len(stores.stores))
+	for profile := range stores.stores {
+		outoftreeRegistries[profile] = config.ProfileOutOfTreeRegistry(profile)
+	}
+	outoftreeFactory := func(pluginname string, f framework.Handle) (schedulerRuntime.PluginFactory, error) {
+		profile := stores.profileOf(f)
+		r, ok := outoftreeRegistries[profile][pluginname]
+		if !ok {
+			return nil, xerrors.Errorf("registry for %s is not found in profile %s", pluginname, profile)
+		}
+		return r, nil
+	}
+
 	pls, err := config.RegisteredMultiPointPluginNames()
 	if err != nil {
 		return nil, xerrors.Errorf("failed to get registered plugin names: %w", err)
@@ -47,16 +129,17 @@ func newPluginFactories(store *schedulingresultstore.Store, pluginExtenders map[
 	for _, pluginname := range pls {
 		pluginname := pluginname
 
-		r, ok := intreeRegistries[pluginname]
-		if !ok {
-			// not found in intreeRegistries. search registry in outoftreeRegistries.
-			r, ok = outoftreeRegistries[pluginname]
-			if !ok {
-				return nil, xerrors.Errorf("registry for %s is not found", pluginname)
-			}
+		r, intree := intreeRegistries[pluginname]
+		if !intree {
 			// For out-of-tree plugins, we need to add original registry to registries.
 			// (For in-tree plugins, schedulers add original registry to registries internally.)
-			ret[pluginname] = r
+			ret[pluginname] = func(ctx context.Context, configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+				r, err := outoftreeFactory(pluginname, f)
+				if err != nil {
+					return nil, err
+				}
+				return r(ctx, configuration, f)
+			}
 		}
 
 		if _, ok := ret[pluginName(pluginname)]; ok {
@@ -65,18 +148,26 @@ func newPluginFactories(store *schedulingresultstore.Store, pluginExtenders map[
 		}
 
 		factory := func(ctx context.Context, configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
-			p, err := r(ctx, configuration, f)
+			original := r
+			if !intree {
+				var err error
+				original, err = outoftreeFactory(pluginname, f)
+				if err != nil {
+					return nil, err
+				}
+			}
+			p, err := original(ctx, configuration, f)
 			if err != nil {
 				return nil, xerrors.Errorf("create original plugin: %w", err)
 			}
 
-			opts := []Option{}
+			opts := append([]Option{withHandleOption(f)}, commonOpts...)
 			extender, ok := pluginExtenders[pluginname]
 			if ok {
 				opts = append(opts, WithExtendersOption(extender))
 			}
 
-			return NewWrappedPlugin(store, p, opts...), nil
+			return NewWrappedPlugin(stores.forHandle(f), p, opts...), nil
 		}
 		ret[pluginName(pluginname)] = factory
 	}
@@ -284,12 +375,14 @@ func mergePluginSet(defaultPluginSet, customPluginSet configv1.PluginSet) config
 	return configv1.PluginSet{Enabled: enabledPlugins, Disabled: disabled}
 }
 
-// getScorePluginWeight get weights of enabled score plugins in the scheduler configuration.
-// It only supports the scheduler configuration with one profile -- scheduler with multiple profiles isn't supported.
-func getScorePluginWeight(cfg *schedulerConfig.KubeSchedulerConfiguration) map[string]int32 {
+// getScorePluginWeight returns the weights of the score plugins enabled in the profile.
+func getScorePluginWeight(profile *schedulerConfig.KubeSchedulerProfile) map[string]int32 {
 	scorePluginWeight := make(map[string]int32)
-	enabledScorePlugins := cfg.Profiles[0].Plugins.Score.Enabled
-	enabledScorePlugins = append(enabledScorePlugins, cfg.Profiles[0].Plugins.MultiPoint.Enabled...)
+	if profile.Plugins == nil {
+		return scorePluginWeight
+	}
+	enabledScorePlugins := profile.Plugins.Score.Enabled
+	enabledScorePlugins = append(enabledScorePlugins, profile.Plugins.MultiPoint.Enabled...)
 	for _, p := range enabledScorePlugins {
 		if p.Weight != 0 {
 			scorePluginWeight[strings.TrimSuffix(p.Name, pluginSuffix)] = p.Weight
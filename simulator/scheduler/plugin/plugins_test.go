@@ -1,16 +1,24 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	schedulerConfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
 )
 
 func TestConvertForSimulator(t *testing.T) {
@@ -1200,10 +1208,106 @@ func TestGetScorePluginWeight(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := getScorePluginWeight(tt.cfg)
+			got := getScorePluginWeight(&tt.cfg.Profiles[0])
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("unexpected plugins map: (-want, +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestResultStores(t *testing.T) {
+	t.Parallel()
+
+	profileWithWeight := func(name string, weight int32) schedulerConfig.KubeSchedulerProfile {
+		return schedulerConfig.KubeSchedulerProfile{
+			SchedulerName: name,
+			Plugins: &schedulerConfig.Plugins{
+				Score: schedulerConfig.PluginSet{Enabled: []schedulerConfig.Plugin{{Name: "NodeResourcesFitWrapped", Weight: weight}}},
+			},
+		}
+	}
+	stores := newResultStores(&schedulerConfig.KubeSchedulerConfiguration{
+		Profiles: []schedulerConfig.KubeSchedulerProfile{profileWithWeight("profile1", 1), profileWithWeight("profile2", 5)},
+	})
+
+	tests := []struct {
+		name           string
+		profile        string
+		wantFinalScore string
+	}{
+		{
+			name:           "the weight in the first profile",
+			profile:        "profile1",
+			wantFinalScore: "10",
+		},
+		{
+			name:           "the weight in the second profile",
+			profile:        "profile2",
+			wantFinalScore: "50",
+		},
+		{
+			name:           "the first profile is used for unknown profiles",
+			profile:        "unknown",
+			wantFinalScore: "10",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-" + tt.profile, Namespace: "default"}}
+			store := stores.Get(tt.profile)
+			store.AddScoreResult(pod.Namespace, pod.Name, "node1", "NodeResourcesFit", 10)
+			defer store.DeleteData(*pod)
+
+			got := store.GetStoredResult(pod)
+			assert.JSONEq(t, `{"node1":{"NodeResourcesFit":"`+tt.wantFinalScore+`"}}`, got[annotation.FinalScoreResultAnnotationKey])
+		})
+	}
+}
+
+// profileHandle is the framework handle of the profile with the name.
+type profileHandle struct {
+	framework.Handle
+	name string
+}
+
+func (h *profileHandle) ProfileName() string { return h.name }
+
+//nolint:paralleltest // cannot use t.Parallel because RegisterBridgePlugins affects other test cases.
+func TestNewPluginFactories_profileRegistries(t *testing.T) {
+	bridgeProfile := func(name string) configv1.KubeSchedulerProfile {
+		return configv1.KubeSchedulerProfile{
+			SchedulerName: ptr.To(name),
+			Plugins:       &configv1.Plugins{Filter: configv1.PluginSet{Enabled: []configv1.Plugin{{Name: "profileBridge"}}}},
+			PluginConfig: []configv1.PluginConfig{{
+				Name: "profileBridge",
+				Args: runtime.RawExtension{Raw: []byte(`{"endpoint":"http://localhost:8080"}`)},
+			}},
+		}
+	}
+	t.Cleanup(func() {
+		require.NoError(t, config.RegisterBridgePlugins("", &configv1.KubeSchedulerConfiguration{}))
+	})
+	require.NoError(t, config.RegisterBridgePlugins("", &configv1.KubeSchedulerConfiguration{
+		Profiles: []configv1.KubeSchedulerProfile{bridgeProfile("profile1"), {SchedulerName: ptr.To("profile2")}},
+	}))
+
+	stores := newResultStores(&schedulerConfig.KubeSchedulerConfiguration{
+		Profiles: []schedulerConfig.KubeSchedulerProfile{{SchedulerName: "profile1"}, {SchedulerName: "profile2"}},
+	})
+	factories, err := newPluginFactories(stores, nil)
+	require.NoError(t, err)
+	args := &runtime.Unknown{Raw: []byte(`{"endpoint":"http://localhost:8080"}`), ContentType: runtime.ContentTypeJSON}
+
+	for _, name := range []string{"profileBridge", pluginName("profileBridge")} {
+		p, err := factories[name](context.Background(), args, &profileHandle{name: "profile1"})
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		// The plugin configured in profile1 isn't registered for profile2.
+		_, err = factories[name](context.Background(), args, &profileHandle{name: "profile2"})
+		assert.Error(t, err)
+	}
+}
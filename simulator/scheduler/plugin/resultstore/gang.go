@@ -0,0 +1,175 @@
+package resultstore
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+)
+
+// PodGroupLabels are the labels which coscheduling-style plugins use to put Pods into a group (gang).
+// The first one found on a Pod is used.
+var PodGroupLabels = []string{
+	// The label used by the Coscheduling plugin in kubernetes-sigs/scheduler-plugins.
+	"scheduling.x-k8s.io/pod-group",
+	// The label used by the older versions of the Coscheduling plugin.
+	"pod-group.scheduling.sigs.k8s.io",
+}
+
+// PodGroup returns the group of the Pod in the form of "<namespace>/<group name>".
+// It returns an empty string if the Pod doesn't belong to any group.
+func PodGroup(pod *v1.Pod) string {
+	for _, l := range PodGroupLabels {
+		if g := pod.Labels[l]; g != "" {
+			return pod.Namespace + "/" + g
+		}
+	}
+	return ""
+}
+
+// GangOutcome is how the Permit phase of a Pod in a group ended.
+type GangOutcome string
+
+const (
+	// GangOutcomeWaiting means the Pod is still waiting for the other members of the group.
+	GangOutcomeWaiting GangOutcome = "waiting"
+	// GangOutcomeAdmitted means the Pod was allowed to be bound.
+	GangOutcomeAdmitted GangOutcome = "admitted"
+	// GangOutcomeTimedOut means the Pod was rejected because it had waited until the timeout.
+	GangOutcomeTimedOut GangOutcome = "timedOut"
+	// GangOutcomeRejected means the Pod was rejected by the Permit plugin or before the timeout.
+	GangOutcomeRejected GangOutcome = "rejected"
+)
+
+// GangResult is the result of the Permit phase of a Pod in a group.
+// It's reflected on the Pod as the value of annotation.GangResultAnnotationKey in JSON.
+type GangResult struct {
+	// Group is "<namespace>/<group name>".
+	Group string `json:"group"`
+	// Plugin is the Permit plugin which made the Pod wait.
+	Plugin  string      `json:"plugin"`
+	Outcome GangOutcome `json:"outcome"`
+	// StartedAt is when the Permit plugin was called.
+	StartedAt time.Time `json:"startedAt"`
+	// Waited is how long the Pod waited in the Permit phase.
+	Waited string `json:"waited"`
+	// Timeout is the timeout returned from the Permit plugin.
+	Timeout string `json:"timeout"`
+	// WaitingWith are the Pods in the same group which were waiting at the same time, in "<namespace>/<name>".
+	WaitingWith []string `json:"waitingWith,omitempty"`
+
+	timeout time.Duration
+}
+
+// AddGangPermitResult records the result of the Permit plugin for a Pod in the group.
+// When the status is WaitMessage, the Pod is regarded as waiting until FinishGangWaiting is called.
+// Otherwise, the Permit phase of the Pod ends immediately.
+func (s *Store) AddGangPermitResult(namespace, podName, pluginName, group, status string, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := newKey(namespace, podName)
+	if _, ok := s.results[k]; !ok {
+		s.results[k] = newData()
+	}
+	if g := s.results[k].gang; g != nil && g.Outcome == GangOutcomeWaiting {
+		// Another Permit plugin has already made the Pod wait.
+		return
+	}
+
+	g := &GangResult{
+		Group:     group,
+		Plugin:    pluginName,
+		StartedAt: time.Now(),
+		Waited:    time.Duration(0).String(),
+		Timeout:   timeout.String(),
+		timeout:   timeout,
+	}
+	s.results[k].gang = g
+
+	switch status {
+	case WaitMessage:
+		g.Outcome = GangOutcomeWaiting
+		if s.gangWaiting == nil {
+			s.gangWaiting = map[string]map[key]struct{}{}
+		}
+		if s.gangWaiting[group] == nil {
+			s.gangWaiting[group] = map[key]struct{}{}
+		}
+		for other := range s.gangWaiting[group] {
+			g.WaitingWith = append(g.WaitingWith, string(other))
+			if r, ok := s.results[other]; ok && r.gang != nil {
+				r.gang.WaitingWith = append(r.gang.WaitingWith, string(k))
+			}
+		}
+		sort.Strings(g.WaitingWith)
+		s.gangWaiting[group][k] = struct{}{}
+	case SuccessMessage:
+		g.Outcome = GangOutcomeAdmitted
+	default:
+		g.Outcome = GangOutcomeRejected
+	}
+}
+
+// FinishGangWaiting records that the waiting Pod was admitted or rejected.
+// The rejected Pod is regarded as timed out if it has waited until the timeout.
+// It does nothing if the Pod isn't waiting.
+func (s *Store) FinishGangWaiting(namespace, podName string, admitted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := newKey(namespace, podName)
+	r, ok := s.results[k]
+	if !ok || r.gang == nil || r.gang.Outcome != GangOutcomeWaiting {
+		return
+	}
+
+	g := r.gang
+	waited := time.Since(g.StartedAt)
+	g.Waited = waited.String()
+	switch {
+	case admitted:
+		g.Outcome = GangOutcomeAdmitted
+	case waited >= g.timeout:
+		g.Outcome = GangOutcomeTimedOut
+	default:
+		g.Outcome = GangOutcomeRejected
+	}
+	delete(s.gangWaiting[g.Group], k)
+	if len(s.gangWaiting[g.Group]) == 0 {
+		delete(s.gangWaiting, g.Group)
+	}
+}
+
+// DeletePod deletes all data of the deleted Pod, including the gang result kept by DeleteData while the Pod is waiting.
+// The Pod is removed from the waiting members of its group as well, so it's no longer listed in WaitingWith of the others.
+func (s *Store) DeletePod(pod v1.Pod) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := newKey(pod.Namespace, pod.Name)
+	if r, ok := s.results[k]; ok && r.gang != nil {
+		delete(s.gangWaiting[r.gang.Group], k)
+		if len(s.gangWaiting[r.gang.Group]) == 0 {
+			delete(s.gangWaiting, r.gang.Group)
+		}
+	}
+	delete(s.results, k)
+}
+
+func (s *Store) addGangResultToMap(anno map[string]string, k key) error {
+	g := s.results[k].gang
+	if g == nil {
+		return nil
+	}
+	r, err := json.Marshal(g)
+	if err != nil {
+		return xerrors.Errorf("encode json to record gang result: %w", err)
+	}
+	anno[annotation.GangResultAnnotationKey] = string(r)
+	return nil
+}
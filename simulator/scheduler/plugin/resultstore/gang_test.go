@@ -0,0 +1,118 @@
+package resultstore
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+)
+
+func TestPodGroup(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{
+			name:   "label of Coscheduling",
+			labels: map[string]string{"scheduling.x-k8s.io/pod-group": "pg1"},
+			want:   "default/pg1",
+		},
+		{
+			name:   "label of older Coscheduling",
+			labels: map[string]string{"pod-group.scheduling.sigs.k8s.io": "pg1"},
+			want:   "default/pg1",
+		},
+		{
+			name: "no group",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Labels: tt.labels}}
+			assert.Equal(t, tt.want, PodGroup(pod))
+		})
+	}
+}
+
+func TestStore_GangWaiting(t *testing.T) {
+	t.Parallel()
+	s := &Store{mu: &sync.Mutex{}, results: map[key]*result{}}
+
+	s.AddGangPermitResult("default", "pod1", "Coscheduling", "default/pg1", WaitMessage, time.Hour)
+	s.AddGangPermitResult("default", "pod2", "Coscheduling", "default/pg1", WaitMessage, time.Hour)
+	// pod3 is the last member and admits the others.
+	s.AddGangPermitResult("default", "pod3", "Coscheduling", "default/pg1", SuccessMessage, 0)
+	// Another group doesn't affect pg1.
+	s.AddGangPermitResult("default", "pod4", "Coscheduling", "default/pg2", WaitMessage, 0)
+
+	s.FinishGangWaiting("default", "pod1", true)
+	s.FinishGangWaiting("default", "pod2", true)
+	// The second call doesn't change the outcome.
+	s.FinishGangWaiting("default", "pod2", false)
+	s.FinishGangWaiting("default", "pod4", false)
+
+	tests := []struct {
+		pod             string
+		wantOutcome     GangOutcome
+		wantWaitingWith []string
+	}{
+		{pod: "pod1", wantOutcome: GangOutcomeAdmitted, wantWaitingWith: []string{"default/pod2"}},
+		{pod: "pod2", wantOutcome: GangOutcomeAdmitted, wantWaitingWith: []string{"default/pod1"}},
+		{pod: "pod3", wantOutcome: GangOutcomeAdmitted},
+		{pod: "pod4", wantOutcome: GangOutcomeTimedOut},
+	}
+	for _, tt := range tests {
+		anno := s.GetStoredResult(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: tt.pod, Namespace: "default"}})
+		got := &GangResult{}
+		require.NoError(t, json.Unmarshal([]byte(anno[annotation.GangResultAnnotationKey]), got), tt.pod)
+		assert.Equal(t, "Coscheduling", got.Plugin, tt.pod)
+		assert.Equal(t, tt.wantOutcome, got.Outcome, tt.pod)
+		assert.Equal(t, tt.wantWaitingWith, got.WaitingWith, tt.pod)
+	}
+	assert.Empty(t, s.gangWaiting)
+}
+
+func TestStore_GangWaiting_Rejected(t *testing.T) {
+	t.Parallel()
+	s := &Store{mu: &sync.Mutex{}, results: map[key]*result{}}
+
+	s.AddGangPermitResult("default", "pod1", "Coscheduling", "default/pg1", WaitMessage, time.Hour)
+	// The results reflected on the Pod while it's waiting don't remove the waiting.
+	s.DeleteData(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}})
+	// The Pod is rejected before the timeout, e.g., the group is rejected by another member.
+	s.FinishGangWaiting("default", "pod1", false)
+
+	g := s.results["default/pod1"].gang
+	assert.Equal(t, GangOutcomeRejected, g.Outcome)
+	assert.Equal(t, "1h0m0s", g.Timeout)
+}
+
+func TestStore_DeletePod(t *testing.T) {
+	t.Parallel()
+	s := &Store{mu: &sync.Mutex{}, results: map[key]*result{}}
+
+	s.AddGangPermitResult("default", "pod1", "Coscheduling", "default/pg1", WaitMessage, time.Hour)
+	s.DeleteData(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}})
+	require.Contains(t, s.results, key("default/pod1"), "the waiting Pod keeps its gang result after the results are reflected")
+
+	// The waiting Pod is deleted before the waiting ends.
+	s.DeletePod(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}})
+	assert.Empty(t, s.results)
+	assert.Empty(t, s.gangWaiting)
+
+	// The next member doesn't wait with the deleted Pod.
+	s.AddGangPermitResult("default", "pod2", "Coscheduling", "default/pg1", WaitMessage, time.Hour)
+	assert.Empty(t, s.results["default/pod2"].gang.WaitingWith)
+}
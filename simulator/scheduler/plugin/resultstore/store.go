@@ -8,10 +8,12 @@ import (
 
 	"golang.org/x/xerrors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
 )
 
 // Store has results of scheduling.
@@ -19,8 +21,14 @@ import (
 type Store struct {
 	mu *sync.Mutex
 
+	// profile is the scheduler name of the profile whose plugins record the results.
+	// It's empty when the store is shared by all profiles.
+	profile           string
 	results           map[key]*result
 	scorePluginWeight map[string]int32
+	// gangWaiting has the Pods waiting in the Permit phase of each group.
+	// group → keys of the Pods
+	gangWaiting map[string]map[key]struct{}
 }
 
 const (
@@ -34,6 +42,11 @@ const (
 	PostFilterNominatedMessage = "preemption victim"
 )
 
+// CycleStateAnnotationSizeLimitB is the limit of the size of the CycleState result annotation.
+// The annotation is also kept in the result history, which must fit in validation.TotalAnnotationSizeLimitB with the other results,
+// so it's limited to a small part of it.
+const CycleStateAnnotationSizeLimitB = validation.TotalAnnotationSizeLimitB / 8
+
 // result has a scheduling result of pod.
 type result struct {
 	// selectedNode is the scheduling result. It'll be filled when the Pod go through Reserve phase.
@@ -83,6 +96,13 @@ type result struct {
 	// plugin name → bind result(string)
 	bind map[string]string
 
+	// state key → CycleState entry(JSON)
+	// It's nil until an entry is added, because the CycleState is recorded only when it's enabled.
+	cycleState map[string]string
+
+	// gang is the result of the Permit phase when the Pod belongs to a group.
+	gang *GangResult
+
 	// customResults has the user defined custom results.
 	// annotation key -> result(string)
 	customResults map[string]string
@@ -98,6 +118,15 @@ func New(scorePluginWeight map[string]int32) *Store {
 	return s
 }
 
+// NewForProfile creates the Store for the plugins in the profile with the scheduler name.
+// scorePluginWeight must be the weights of the score plugins in the profile,
+// and the scheduler name is recorded along with the results.
+func NewForProfile(profile string, scorePluginWeight map[string]int32) *Store {
+	s := New(scorePluginWeight)
+	s.profile = profile
+	return s
+}
+
 // key is the key of result map on Store.
 // key is created from namespace and podName.
 type key string
@@ -171,6 +200,16 @@ func (s *Store) GetStoredResult(pod *v1.Pod) map[string]string {
 		return nil
 	}
 
+	if err := s.addScorePluginWeightToMap(annotation, k); err != nil {
+		klog.Errorf("failed to add score plugin weight to pod: %+v", err)
+		return nil
+	}
+
+	if err := s.addCycleStateResultToMap(annotation, k); err != nil {
+		klog.Errorf("failed to add cycle state result to pod: %+v", err)
+		return nil
+	}
+
 	if err := s.addReserveResultToMap(annotation, k); err != nil {
 		klog.Errorf("failed to add reserve result to pod: %+v", err)
 		return nil
@@ -181,6 +220,11 @@ func (s *Store) GetStoredResult(pod *v1.Pod) map[string]string {
 		return nil
 	}
 
+	if err := s.addGangResultToMap(annotation, k); err != nil {
+		klog.Errorf("failed to add gang result to pod: %+v", err)
+		return nil
+	}
+
 	if err := s.addPreBindResultToMap(annotation, k); err != nil {
 		klog.Errorf("failed to add prebind result to pod: %+v", err)
 		return nil
@@ -193,6 +237,9 @@ func (s *Store) GetStoredResult(pod *v1.Pod) map[string]string {
 
 	s.addCustomResultsToMap(annotation, k)
 	s.addSelectedNodeToPod(annotation, k)
+	if s.profile != "" {
+		annotation[storereflector.ProfileAnnotation] = s.profile
+	}
 
 	return annotation
 }
@@ -409,6 +456,72 @@ func (s *Store) addFinalScoreResultToMap(anno map[string]string, k key) error {
 	return nil
 }
 
+// addScorePluginWeightToMap records the weights of the plugins in the final score
+// so that the final score can be recalculated with other weights later.
+func (s *Store) addScorePluginWeightToMap(anno map[string]string, k key) error {
+	_, ok := anno[annotation.ScorePluginWeightAnnotationKey]
+	if ok {
+		return nil
+	}
+
+	weights := map[string]int32{}
+	for _, scores := range s.results[k].finalScore {
+		for pluginName := range scores {
+			weights[pluginName] = s.scorePluginWeight[pluginName]
+		}
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+	d, err := json.Marshal(weights)
+	if err != nil {
+		return xerrors.Errorf("encode json to record score plugin weights: %w", err)
+	}
+
+	anno[annotation.ScorePluginWeightAnnotationKey] = string(d)
+	return nil
+}
+
+func (s *Store) addCycleStateResultToMap(anno map[string]string, k key) error {
+	if len(s.results[k].cycleState) == 0 {
+		return nil
+	}
+	entries := make(map[string]json.RawMessage, len(s.results[k].cycleState))
+	for stateKey, e := range s.results[k].cycleState {
+		entries[stateKey] = json.RawMessage(e)
+	}
+
+	// If the annotation exceeds the limit, we replace the largest entries with the message until it fits,
+	// since the small entries are likely to be the ones easy to read.
+	dropped := map[string]bool{}
+	for {
+		d, err := json.Marshal(entries)
+		if err != nil {
+			return xerrors.Errorf("encode json to record cycle state: %w", err)
+		}
+		if len(d) <= CycleStateAnnotationSizeLimitB {
+			anno[annotation.CycleStateResultAnnotationKey] = string(d)
+			return nil
+		}
+		largest := ""
+		for stateKey, e := range entries {
+			if dropped[stateKey] {
+				continue
+			}
+			if largest == "" || len(e) > len(entries[largest]) || (len(e) == len(entries[largest]) && stateKey < largest) {
+				largest = stateKey
+			}
+		}
+		if largest == "" {
+			// Basically shouldn't happen unless the state keys alone exceed the limit.
+			return xerrors.Errorf("cycle state result still exceeds annotation limit even after dropping all entries")
+		}
+		klog.InfoS("Dropped the CycleState entry from the annotation because it's too large", "pod", k, "stateKey", largest, "size", len(entries[largest]))
+		entries[largest] = json.RawMessage(strconv.Quote("dropped: " + strconv.Itoa(len(entries[largest])) + " bytes exceed the annotation size limit"))
+		dropped[largest] = true
+	}
+}
+
 func (s *Store) addCustomResultsToMap(anno map[string]string, k key) {
 	for annokey, r := range s.results[k].customResults {
 		_, ok := anno[annokey]
@@ -514,8 +627,15 @@ func (s *Store) DeleteData(pod v1.Pod) {
 }
 
 // deleteData deletes the result stored with the given key.
+// The Pod still waiting in the Permit phase keeps its gang result so that the end of the waiting is recorded later.
 // Note: we assume the store lock is already acquired.
 func (s *Store) deleteData(k key) {
+	if r, ok := s.results[k]; ok && r.gang != nil && r.gang.Outcome == GangOutcomeWaiting {
+		d := newData()
+		d.gang = r.gang
+		s.results[k] = d
+		return
+	}
 	delete(s.results, k)
 }
 
@@ -607,6 +727,22 @@ func (s *Store) AddPreBindResult(namespace, podName, pluginName, status string)
 	s.results[k].prebind[pluginName] = status
 }
 
+// AddCycleStateResult records the CycleState entry with the state key. entry must be JSON.
+func (s *Store) AddCycleStateResult(namespace, podName, stateKey, entry string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := newKey(namespace, podName)
+	if _, ok := s.results[k]; !ok {
+		s.results[k] = newData()
+	}
+
+	if s.results[k].cycleState == nil {
+		s.results[k].cycleState = map[string]string{}
+	}
+	s.results[k].cycleState[stateKey] = entry
+}
+
 // AddCustomResult adds user defined data.
 // The results added through this func is reflected on the Pod's annotation eventually like other scheduling results.
 // This function is intended to be called from the plugin.PluginExtender; allow users to export some internal state on Pods for debugging purpose.
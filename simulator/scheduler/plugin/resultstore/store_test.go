@@ -2,6 +2,8 @@ package resultstore
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/annotation"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
 )
 
 func TestStore_AddFilterResult(t *testing.T) {
@@ -754,6 +757,7 @@ func TestStore_GetStoredResult(t *testing.T) {
 					d, _ := json.Marshal(r)
 					return string(d)
 				}(),
+				annotation.ScorePluginWeightAnnotationKey: `{"plugin1":2}`,
 			},
 		},
 		{
@@ -822,8 +826,9 @@ func TestStore_GetStoredResult(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			s := &Store{
-				mu:      new(sync.Mutex),
-				results: tt.result,
+				mu:                new(sync.Mutex),
+				results:           tt.result,
+				scorePluginWeight: map[string]int32{"plugin1": 2},
 			}
 			p := tt.newObj
 			result := s.GetStoredResult(p)
@@ -1141,6 +1146,38 @@ func TestStore_AddPreBindResult(t *testing.T) {
 	}
 }
 
+func TestStore_AddCycleStateResult(t *testing.T) {
+	t.Parallel()
+	s := New(nil)
+	s.AddCycleStateResult("namespace", "pod", "PreFilterNodePorts", `[{"ContainerPort":80}]`)
+	s.AddCycleStateResult("namespace", "pod", "PreScoreTaintToleration", `{"tolerationsPreferNoSchedule":null}`)
+
+	got := s.GetStoredResult(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "namespace"}})
+	assert.JSONEq(t, `{"PreFilterNodePorts":[{"ContainerPort":80}],"PreScoreTaintToleration":{"tolerationsPreferNoSchedule":null}}`, got[annotation.CycleStateResultAnnotationKey])
+}
+
+func TestStore_AddCycleStateResult_SizeLimit(t *testing.T) {
+	t.Parallel()
+	s := New(nil)
+	large := `"` + strings.Repeat("a", CycleStateAnnotationSizeLimitB) + `"`
+	s.AddCycleStateResult("namespace", "pod", "PreFilterNodePorts", `[{"ContainerPort":80}]`)
+	s.AddCycleStateResult("namespace", "pod", "PreFilterLarge", large)
+
+	got := s.GetStoredResult(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "namespace"}})
+	// Only the large entry is dropped.
+	assert.JSONEq(t, `{"PreFilterNodePorts":[{"ContainerPort":80}],"PreFilterLarge":"dropped: `+strconv.Itoa(len(large))+` bytes exceed the annotation size limit"}`, got[annotation.CycleStateResultAnnotationKey])
+}
+
+func TestNewForProfile(t *testing.T) {
+	t.Parallel()
+	s := NewForProfile("profile2", map[string]int32{"plugin1": 3})
+	s.AddScoreResult("namespace", "pod", "node1", "plugin1", 10)
+
+	got := s.GetStoredResult(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "namespace"}})
+	assert.Equal(t, "profile2", got[storereflector.ProfileAnnotation])
+	assert.JSONEq(t, `{"node1":{"plugin1":"30"}}`, got[annotation.FinalScoreResultAnnotationKey])
+}
+
 func TestStore_DeleteData(t *testing.T) {
 	t.Parallel()
 	podName := "pod1"
@@ -2,12 +2,18 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/cyclestate"
 	schedulingresultstore "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/resultstore"
 )
 
@@ -21,10 +27,13 @@ type Store interface {
 	AddScoreResult(namespace, podName, nodeName, pluginName string, score int64)
 	AddPostFilterResult(namespace, podName, nominatedNodeName, pluginName string, nodeNames []string)
 	AddPermitResult(namespace, podName, pluginName, status string, timeout time.Duration)
+	AddGangPermitResult(namespace, podName, pluginName, group, status string, timeout time.Duration)
+	FinishGangWaiting(namespace, podName string, admitted bool)
 	AddReserveResult(namespace, podName, pluginName, status string)
 	AddSelectedNode(namespace, podName, nodeName string)
 	AddBindResult(namespace, podName, pluginName, status string)
 	AddPreBindResult(namespace, podName, pluginName, status string)
+	AddCycleStateResult(namespace, podName, stateKey, entry string)
 	// AddCustomResult is intended to be used from outside of simulator.
 	AddCustomResult(namespace, podName, annotationKey, result string)
 }
@@ -41,6 +50,20 @@ type SimulatorHandle interface {
 	// AddCustomResult("namespace", "incomingPod", "node-affinity-filter-internal-state-anno-key", "internal-state")
 	// Then, "incomingPod" Pod will get {"node-affinity-filter-internal-state-anno-key": "internal-state"} annotation after scheduling.
 	AddCustomResult(namespace, podName, annotationKey, result string)
+	// FrameworkHandle returns the handle of the scheduler framework the plugin runs in,
+	// e.g., to get the Nodes from the snapshot.
+	// It's nil when the plugin is created outside the scheduler framework.
+	FrameworkHandle() framework.Handle
+}
+
+// simulatorHandle is the SimulatorHandle given to the PluginExtenderInitializer.
+type simulatorHandle struct {
+	Store
+	handle framework.Handle
+}
+
+func (h *simulatorHandle) FrameworkHandle() framework.Handle {
+	return h.handle
 }
 
 // PreFilterPluginExtender is the extender for PreFilter plugin.
@@ -173,11 +196,17 @@ type PluginExtenders struct {
 type options struct {
 	extenderInitializerOption PluginExtenderInitializer
 	pluginNameOption          string
+	breakpointsOption         *breakpoint.Manager
+	handleOption              framework.Handle
+	cycleStateOption          *cyclestate.Registry
 }
 
 type (
-	extendersOption  PluginExtenderInitializer
-	pluginNameOption string
+	extendersOption   PluginExtenderInitializer
+	pluginNameOption  string
+	breakpointsOption struct{ manager *breakpoint.Manager }
+	handleOption      struct{ handle framework.Handle }
+	cycleStateOption  struct{ registry *cyclestate.Registry }
 )
 
 type Option interface {
@@ -192,6 +221,18 @@ func (p pluginNameOption) apply(opts *options) {
 	opts.pluginNameOption = string(p)
 }
 
+func (b breakpointsOption) apply(opts *options) {
+	opts.breakpointsOption = b.manager
+}
+
+func (h handleOption) apply(opts *options) {
+	opts.handleOption = h.handle
+}
+
+func (c cycleStateOption) apply(opts *options) {
+	opts.cycleStateOption = c.registry
+}
+
 // WithExtendersOption provides an easy way to extend the behavior of the plugin.
 // These containing functions in PluginExtenders should be run before and after the original plugin of Scheduler Framework.
 func WithExtendersOption(opt PluginExtenderInitializer) Option {
@@ -203,6 +244,22 @@ func WithPluginNameOption(opt *string) Option {
 	return pluginNameOption(*opt)
 }
 
+// WithBreakpointsOption makes the wrappedPlugin pause the scheduling at the breakpoints in the manager.
+func WithBreakpointsOption(manager *breakpoint.Manager) Option {
+	return breakpointsOption{manager: manager}
+}
+
+// WithCycleStateOption makes the wrappedPlugin record the CycleState entries of the original plugin
+// after PreFilter and PreScore, with the serializers registered in the registry.
+func WithCycleStateOption(registry *cyclestate.Registry) Option {
+	return cycleStateOption{registry: registry}
+}
+
+// withHandleOption gives the framework handle to the wrappedPlugin, which is used to get the NodeInfo at the breakpoints.
+func withHandleOption(h framework.Handle) Option {
+	return handleOption{handle: h}
+}
+
 // wrappedPlugin behaves as if it is original plugin, but it records result of plugin.
 type wrappedPlugin struct {
 	// name is plugin's name returned by Name() method.
@@ -212,6 +269,12 @@ type wrappedPlugin struct {
 	// store records plugin's result.
 	// TODO: move store's logic to plugin extender.
 	store Store
+	// breakpoints pauses the scheduling at the breakpoints. It's nil when the breakpoints are disabled.
+	breakpoints *breakpoint.Manager
+	// handle is nil when the wrappedPlugin is created outside the scheduler framework.
+	handle framework.Handle
+	// cycleState serializes the CycleState entries to record. It's nil when the CycleState isn't recorded.
+	cycleState *cyclestate.Registry
 
 	originalPreEnqueuePlugin framework.PreEnqueuePlugin
 	originalPreFilterPlugin  framework.PreFilterPlugin
@@ -224,6 +287,8 @@ type wrappedPlugin struct {
 	originalPreBindPlugin    framework.PreBindPlugin
 	originalBindPlugin       framework.BindPlugin
 	originalPostBindPlugin   framework.PostBindPlugin
+	// originalEnqueueExtensions is nil when the original plugin doesn't implement EnqueueExtensions.
+	originalEnqueueExtensions framework.EnqueueExtensions
 
 	// plugin extenders
 	preFilterPluginExtender      PreFilterPluginExtender
@@ -247,6 +312,11 @@ func pluginName(pluginName string) string {
 	return pluginName + pluginSuffix
 }
 
+// OriginalPluginName returns the name of the original plugin from the name of the wrapped plugin.
+func OriginalPluginName(wrappedPluginName string) string {
+	return strings.TrimSuffix(wrappedPluginName, pluginSuffix)
+}
+
 // NewWrappedPlugin makes wrappedPlugin from score or/and filter plugin.
 //
 //nolint:funlen,cyclop
@@ -264,11 +334,14 @@ func NewWrappedPlugin(s Store, p framework.Plugin, opts ...Option) framework.Plu
 	}
 
 	plg := &wrappedPlugin{
-		name:  pName,
-		store: s,
+		name:        pName,
+		store:       s,
+		breakpoints: options.breakpointsOption,
+		handle:      options.handleOption,
+		cycleState:  options.cycleStateOption,
 	}
 
-	extender := options.extenderInitializerOption(s)
+	extender := options.extenderInitializerOption(&simulatorHandle{Store: s, handle: options.handleOption})
 
 	if extender.PreFilterPluginExtender != nil {
 		plg.preFilterPluginExtender = extender.PreFilterPluginExtender
@@ -352,6 +425,11 @@ func NewWrappedPlugin(s Store, p framework.Plugin, opts ...Option) framework.Plu
 		plg.originalPostBindPlugin = postbp
 	}
 
+	eqe, ok := p.(framework.EnqueueExtensions)
+	if ok {
+		plg.originalEnqueueExtensions = eqe
+	}
+
 	queuesortp, ok := p.(framework.QueueSortPlugin)
 	if ok {
 		// There must be only one in each profile for which the QueueSortPlugin interface is implemented.
@@ -364,6 +442,16 @@ func NewWrappedPlugin(s Store, p framework.Plugin, opts ...Option) framework.Plu
 }
 
 func (w *wrappedPlugin) Name() string { return w.name }
+
+// EventsToRegister returns the events registered by the original plugin,
+// so that the Pods rejected by the wrapped plugin are requeued in the same way as the original one.
+func (w *wrappedPlugin) EventsToRegister(ctx context.Context) ([]framework.ClusterEventWithHint, error) {
+	if w.originalEnqueueExtensions == nil {
+		// The scheduler regards the plugins without EnqueueExtensions as interested in all events.
+		return framework.UnrollWildCardResource(), nil
+	}
+	return w.originalEnqueueExtensions.EventsToRegister(ctx)
+}
 func (w *wrappedPlugin) ScoreExtensions() framework.ScoreExtensions {
 	if w.originalScorePlugin != nil && w.originalScorePlugin.ScoreExtensions() != nil {
 		return w
@@ -390,6 +478,7 @@ func (w *wrappedPlugin) NormalizeScore(ctx context.Context, state *framework.Cyc
 		// return nil not to affect scoring
 		return nil
 	}
+	w.pause(ctx, "NormalizeScore", state, pod, "", nil)
 
 	if w.normalizeScorePluginExtender != nil {
 		if s := w.normalizeScorePluginExtender.BeforeNormalizeScore(ctx, state, pod, scores); !s.IsSuccess() {
@@ -422,6 +511,7 @@ func (w *wrappedPlugin) Score(ctx context.Context, state *framework.CycleState,
 		// return zero-score and nil not to affect scoring
 		return 0, nil
 	}
+	w.pause(ctx, "Score", state, pod, nodeName, nil)
 
 	if w.scorePluginExtender != nil {
 		score, s := w.scorePluginExtender.BeforeScore(ctx, state, pod, nodeName)
@@ -431,16 +521,17 @@ func (w *wrappedPlugin) Score(ctx context.Context, state *framework.CycleState,
 	}
 
 	score, s := w.originalScorePlugin.Score(ctx, state, pod, nodeName)
+	if w.scorePluginExtender != nil {
+		score, s = w.scorePluginExtender.AfterScore(ctx, state, pod, nodeName, score, s)
+	}
+
+	// The score is recorded after AfterScore so that the annotation shows the score the scheduler actually uses,
+	// which matters for the plugins without NormalizeScore as their scores are recorded as the final scores as well.
 	if !s.IsSuccess() {
 		klog.Errorf("failed to run score plugin. Scores won't be recorded on Pod annotation: %v, %v", s.Code(), s.Message())
 	} else {
-		// TODO: move to AfterScore.
 		w.store.AddScoreResult(pod.Namespace, pod.Name, nodeName, w.originalScorePlugin.Name(), score)
 	}
-
-	if w.scorePluginExtender != nil {
-		return w.scorePluginExtender.AfterScore(ctx, state, pod, nodeName, score, s)
-	}
 	return score, s
 }
 
@@ -461,6 +552,7 @@ func (w *wrappedPlugin) PreScore(ctx context.Context, state *framework.CycleStat
 		// return nil not to affect scoring
 		return nil
 	}
+	w.pause(ctx, "PreScore", state, pod, "", nil)
 
 	if w.preScorePluginExtender != nil {
 		s := w.preScorePluginExtender.BeforePreScore(ctx, state, pod, nodes)
@@ -477,6 +569,7 @@ func (w *wrappedPlugin) PreScore(ctx context.Context, state *framework.CycleStat
 		msg = s.Message()
 	}
 	w.store.AddPreScoreResult(pod.Namespace, pod.Name, w.originalPreScorePlugin.Name(), msg)
+	w.recordCycleState(state, pod, w.originalPreScorePlugin)
 
 	if w.preScorePluginExtender != nil {
 		return w.preScorePluginExtender.AfterPreScore(ctx, state, pod, nodes, s)
@@ -493,6 +586,7 @@ func (w *wrappedPlugin) PreFilter(ctx context.Context, state *framework.CycleSta
 		// return nils not to affect scoring
 		return nil, nil
 	}
+	w.pause(ctx, "PreFilter", state, p, "", nil)
 
 	if w.preFilterPluginExtender != nil {
 		r, s := w.preFilterPluginExtender.BeforePreFilter(ctx, state, p)
@@ -509,6 +603,7 @@ func (w *wrappedPlugin) PreFilter(ctx context.Context, state *framework.CycleSta
 		msg = s.Message()
 	}
 	w.store.AddPreFilterResult(p.Namespace, p.Name, w.originalPreFilterPlugin.Name(), msg, result)
+	w.recordCycleState(state, p, w.originalPreFilterPlugin)
 
 	if w.preFilterPluginExtender != nil {
 		return w.preFilterPluginExtender.AfterPreFilter(ctx, state, p, result, s)
@@ -525,6 +620,7 @@ func (w *wrappedPlugin) Filter(ctx context.Context, state *framework.CycleState,
 		// return nil not to affect filtering
 		return nil
 	}
+	w.pause(ctx, "Filter", state, pod, nodeInfo.Node().Name, nodeInfo)
 
 	if w.filterPluginExtender != nil {
 		if s := w.filterPluginExtender.BeforeFilter(ctx, state, pod, nodeInfo); !s.IsSuccess() {
@@ -553,6 +649,7 @@ func (w *wrappedPlugin) PostFilter(ctx context.Context, state *framework.CycleSt
 		// (If return Unschedulable, the scheduler will execute next PostFilter plugin.)
 		return nil, framework.NewStatus(framework.Unschedulable)
 	}
+	w.pause(ctx, "PostFilter", state, pod, "", nil)
 	if w.postFilterPluginExtender != nil {
 		r, s := w.postFilterPluginExtender.BeforePostFilter(ctx, state, pod, filteredNodeStatusMap)
 		if !s.IsSuccess() {
@@ -590,6 +687,7 @@ func (w *wrappedPlugin) Permit(ctx context.Context, state *framework.CycleState,
 		// return zero-score and nil not to affect scoring
 		return nil, 0
 	}
+	w.pause(ctx, "Permit", state, pod, nodeName, nil)
 
 	if w.permitPluginExtender != nil {
 		s, d := w.permitPluginExtender.BeforePermit(ctx, state, pod, nodeName)
@@ -608,6 +706,12 @@ func (w *wrappedPlugin) Permit(ctx context.Context, state *framework.CycleState,
 	}
 
 	w.store.AddPermitResult(pod.Namespace, pod.Name, w.originalPermitPlugin.Name(), msg, timeout)
+	if group := schedulingresultstore.PodGroup(pod); group != "" {
+		w.store.AddGangPermitResult(pod.Namespace, pod.Name, w.originalPermitPlugin.Name(), group, msg, timeout)
+		if s.IsWait() {
+			state.Write(gangWaitingStateKey, gangWaitingState{})
+		}
+	}
 
 	if w.permitPluginExtender != nil {
 		return w.permitPluginExtender.AfterPermit(ctx, state, pod, nodeName, s, timeout)
@@ -626,6 +730,7 @@ func (w *wrappedPlugin) Reserve(ctx context.Context, state *framework.CycleState
 		// return nil not to affect scoring
 		return nil
 	}
+	w.pause(ctx, "Reserve", state, pod, nodename, nil)
 
 	if w.reservePluginExtender != nil {
 		s := w.reservePluginExtender.BeforeReserve(ctx, state, pod, nodename)
@@ -654,9 +759,13 @@ func (w *wrappedPlugin) Reserve(ctx context.Context, state *framework.CycleState
 // You can run your function before and/or after the execution of original Unreserve plugin
 // by configuring with WithExtendersOption.
 func (w *wrappedPlugin) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) {
+	// The Pod waiting in the Permit phase is unreserved when it's rejected or timed out.
+	w.finishGangWaiting(state, pod, false)
+
 	if w.originalReservePlugin == nil {
 		return
 	}
+	w.pause(ctx, "Unreserve", state, pod, nodename, nil)
 
 	if w.reservePluginExtender != nil {
 		s := w.reservePluginExtender.BeforeUnreserve(ctx, state, pod, nodename)
@@ -674,10 +783,14 @@ func (w *wrappedPlugin) Unreserve(ctx context.Context, state *framework.CycleSta
 }
 
 func (w *wrappedPlugin) PreBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	// The binding cycle reaches PreBind only after the waiting Pod is allowed.
+	w.finishGangWaiting(state, pod, true)
+
 	if w.originalPreBindPlugin == nil {
 		// return nil not to affect scoring
 		return nil
 	}
+	w.pause(ctx, "PreBind", state, pod, nodename, nil)
 
 	if w.preBindPluginExtender != nil {
 		s := w.preBindPluginExtender.BeforePreBind(ctx, state, pod, nodename)
@@ -703,10 +816,14 @@ func (w *wrappedPlugin) PreBind(ctx context.Context, state *framework.CycleState
 }
 
 func (w *wrappedPlugin) Bind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) *framework.Status {
+	// The profile may have no PreBind plugins.
+	w.finishGangWaiting(state, pod, true)
+
 	if w.originalBindPlugin == nil {
 		// return skip not to affect other bind plugins.
 		return framework.NewStatus(framework.Skip, "called wrapped bind plugin is nil")
 	}
+	w.pause(ctx, "Bind", state, pod, nodename, nil)
 
 	if w.bindPluginExtender != nil {
 		s := w.bindPluginExtender.BeforeBind(ctx, state, pod, nodename)
@@ -731,10 +848,102 @@ func (w *wrappedPlugin) Bind(ctx context.Context, state *framework.CycleState, p
 	return s
 }
 
+// gangWaitingStateKey is the CycleState key with which the Permit plugin marks the Pod in a group as waiting.
+// The first plugin run in the binding cycle or Unreserve takes the mark, so that the end of the waiting is recorded once in a cycle.
+const gangWaitingStateKey framework.StateKey = "kube-scheduler-simulator.sigs.k8s.io/gang-waiting"
+
+type gangWaitingState struct{}
+
+func (gangWaitingState) Clone() framework.StateData { return gangWaitingState{} }
+
+// finishGangWaiting records the end of the waiting in the Permit phase if the Pod has been waiting in this cycle.
+func (w *wrappedPlugin) finishGangWaiting(state *framework.CycleState, pod *v1.Pod, admitted bool) {
+	if state == nil {
+		return
+	}
+	if _, err := state.Read(gangWaitingStateKey); err != nil {
+		return
+	}
+	state.Delete(gangWaitingStateKey)
+	w.store.FinishGangWaiting(pod.Namespace, pod.Name, admitted)
+}
+
+// recordCycleState records the CycleState entries written by the original plugin.
+func (w *wrappedPlugin) recordCycleState(state *framework.CycleState, pod *v1.Pod, original framework.Plugin) {
+	if w.cycleState == nil || state == nil {
+		return
+	}
+	originalName := original.Name()
+	if !w.cycleState.Has(originalName) {
+		return
+	}
+	entries, err := w.cycleState.Serialize(originalName, state)
+	if err != nil {
+		klog.ErrorS(err, "failed to serialize cycle state. Some entries won't be recorded on Pod annotation", "pod", klog.KObj(pod), "plugin", originalName)
+	}
+	for k, e := range entries {
+		w.store.AddCycleStateResult(pod.Namespace, pod.Name, k, e)
+	}
+}
+
+// pause blocks until the scheduling is resumed if a breakpoint matches the extension point.
+// nodeInfo is looked up by nodeName if it's nil.
+func (w *wrappedPlugin) pause(ctx context.Context, extensionPoint string, state *framework.CycleState, pod *v1.Pod, nodeName string, nodeInfo *framework.NodeInfo) {
+	if w.breakpoints == nil {
+		return
+	}
+	loc := breakpoint.Location{Pod: pod, Plugin: OriginalPluginName(w.name), ExtensionPoint: extensionPoint, Node: nodeName}
+	w.breakpoints.Pause(ctx, loc, func() breakpoint.Snapshot {
+		if nodeInfo == nil && nodeName != "" && w.handle != nil {
+			ni, err := w.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+			if err != nil {
+				klog.ErrorS(err, "failed to get NodeInfo at breakpoint", "node", nodeName)
+			}
+			nodeInfo = ni
+		}
+		return breakpoint.Snapshot{CycleState: w.cycleStateSnapshot(state), NodeInfo: breakpoint.NewNodeInfo(nodeInfo)}
+	})
+}
+
+// cycleStateSnapshot copies the CycleState with the original plugin names.
+// The entries are serialized by the registry for recording the CycleState, or by the one for the in-tree plugins when it's not recorded.
+func (w *wrappedPlugin) cycleStateSnapshot(state *framework.CycleState) *breakpoint.CycleState {
+	if state == nil {
+		return nil
+	}
+	ret := &breakpoint.CycleState{}
+	for _, p := range sets.List(state.SkipFilterPlugins) {
+		ret.SkipFilterPlugins = append(ret.SkipFilterPlugins, OriginalPluginName(p))
+	}
+	for _, p := range sets.List(state.SkipScorePlugins) {
+		ret.SkipScorePlugins = append(ret.SkipScorePlugins, OriginalPluginName(p))
+	}
+
+	registry := w.cycleState
+	if registry == nil {
+		registry = inTreeCycleState()
+	}
+	entries, err := registry.SerializeAll(state)
+	if err != nil {
+		klog.ErrorS(err, "failed to serialize cycle state at breakpoint. Some entries won't be shown")
+	}
+	if len(entries) != 0 {
+		ret.Entries = make(map[string]json.RawMessage, len(entries))
+		for k, e := range entries {
+			ret.Entries[k] = json.RawMessage(e)
+		}
+	}
+	return ret
+}
+
+// inTreeCycleState serializes the CycleState entries of the in-tree plugins at the breakpoints.
+var inTreeCycleState = sync.OnceValue(cyclestate.InTreeRegistry)
+
 func (w *wrappedPlugin) PostBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodename string) {
 	if w.originalPostBindPlugin == nil {
 		return
 	}
+	w.pause(ctx, "PostBind", state, pod, nodename, nil)
 
 	if w.postBindPluginExtender != nil {
 		s := w.postBindPluginExtender.BeforePostBind(ctx, state, pod, nodename)
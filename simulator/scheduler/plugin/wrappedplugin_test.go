@@ -8,12 +8,17 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/nodeports"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/cyclestate"
 	mock_plugin "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/mock"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin/resultstore"
 )
@@ -238,6 +243,102 @@ func Test_wrappedPlugin_Filter(t *testing.T) {
 	}
 }
 
+func Test_wrappedPlugin_EventsToRegister(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	events := func(evs []framework.ClusterEventWithHint) []framework.ClusterEvent {
+		ret := make([]framework.ClusterEvent, 0, len(evs))
+		for _, ev := range evs {
+			ret = append(ret, ev.Event)
+		}
+		return ret
+	}
+
+	// The events registered by the original plugin are used, so that the Pods rejected by it are requeued in the same way.
+	p, err := nodeports.New(ctx, nil, nil, feature.Features{})
+	require.NoError(t, err)
+	want, err := p.(framework.EnqueueExtensions).EventsToRegister(ctx)
+	require.NoError(t, err)
+	got, err := NewWrappedPlugin(nil, p).(framework.EnqueueExtensions).EventsToRegister(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events(want), events(got))
+	for i := range got {
+		assert.Equal(t, want[i].QueueingHintFn != nil, got[i].QueueingHintFn != nil)
+	}
+
+	// The plugin without EnqueueExtensions is interested in all events, as the scheduler regards it.
+	got, err = NewWrappedPlugin(nil, fakeFilterPlugin{}).(framework.EnqueueExtensions).EventsToRegister(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events(framework.UnrollWildCardResource()), events(got))
+}
+
+type fakeStateData struct {
+	Ports []int
+}
+
+func (d *fakeStateData) Clone() framework.StateData { return d }
+
+func Test_wrappedPlugin_Filter_Breakpoint(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}
+	nodeInfo := &framework.NodeInfo{}
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+
+	ctrl := gomock.NewController(t)
+	s := mock_plugin.NewMockStore(ctrl)
+	s.EXPECT().AddFilterResult("default", "pod1", "node1", "fakeFilterPlugin", resultstore.PassedFilterMessage)
+	m := breakpoint.NewManager()
+	_, err := m.AddBreakpoint(ctx, &breakpoint.Breakpoint{Plugin: "fakeFilterPlugin", ExtensionPoint: "Filter"})
+	require.NoError(t, err)
+	pl := NewWrappedPlugin(s, fakeFilterPlugin{}, WithBreakpointsOption(m)).(framework.FilterPlugin)
+
+	cs := framework.NewCycleState()
+	cs.Write("PreFilterNodePorts", &fakeStateData{Ports: []int{8080}})
+	done := make(chan *framework.Status)
+	go func() {
+		done <- pl.Filter(ctx, cs, testPod, nodeInfo)
+	}()
+
+	var hits []breakpoint.Hit
+	require.Eventually(t, func() bool {
+		hits, err = m.ListHits(ctx)
+		return err == nil && len(hits) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "node1", hits[0].NodeInfo.Name)
+	// The entries of the in-tree plugins are shown even if the CycleState isn't recorded.
+	assert.JSONEq(t, `{"Ports":[8080]}`, string(hits[0].CycleState.Entries["PreFilterNodePorts"]))
+
+	// The original plugin isn't called until the scheduling is resumed.
+	select {
+	case <-done:
+		t.Fatal("Filter returned while it's paused")
+	default:
+	}
+	require.NoError(t, m.Resume(ctx, hits[0].ID))
+	assert.Nil(t, <-done)
+}
+
+func Test_wrappedPlugin_PreFilter_CycleState(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Ports: []v1.ContainerPort{{HostPort: 8080, Protocol: v1.ProtocolTCP}}}}},
+	}
+	p, err := nodeports.New(ctx, nil, nil, feature.Features{})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	s := mock_plugin.NewMockStore(ctrl)
+	s.EXPECT().AddPreFilterResult("default", "pod1", nodeports.Name, resultstore.SuccessMessage, nil)
+	s.EXPECT().AddCycleStateResult("default", "pod1", "PreFilterNodePorts", `[{"ContainerPort":0,"HostIP":"","HostPort":8080,"Name":"","Protocol":"TCP"}]`)
+	pl := NewWrappedPlugin(s, p, WithCycleStateOption(cyclestate.InTreeRegistry())).(framework.PreFilterPlugin)
+
+	_, status := pl.PreFilter(ctx, framework.NewCycleState(), testPod)
+	assert.True(t, status.IsSuccess())
+}
+
 func Test_wrappedPlugin_Filter_WithPluginExtender(t *testing.T) {
 	t.Parallel()
 
@@ -1082,7 +1183,7 @@ func Test_wrappedPlugin_Score_WithPluginExtender(t *testing.T) {
 				p.EXPECT().Score(ctx, nil, as.pod, "node1").Return(int64(2222), success2)
 				se.EXPECT().AfterScore(ctx, nil, as.pod, "node1", int64(2222), success2).Return(int64(3333), success3)
 				p.EXPECT().Name().Return("fakeScorePlugin").AnyTimes()
-				s.EXPECT().AddScoreResult("default", "pod1", "node1", "fakeScorePlugin", int64(2222))
+				s.EXPECT().AddScoreResult("default", "pod1", "node1", "fakeScorePlugin", int64(3333))
 			},
 			args: args{
 				pod:      &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
@@ -1092,7 +1193,7 @@ func Test_wrappedPlugin_Score_WithPluginExtender(t *testing.T) {
 			wantstatus: framework.NewStatus(framework.Success, "AfterScore returned"),
 		},
 		{
-			name: "return AfterScore's results & does not call AddScoreResult after Score, if Score fails",
+			name: "return AfterScore's results & record the score of AfterScore, if Score fails but AfterScore succeeds",
 			prepareEachMockFn: func(ctx context.Context, s *mock_plugin.MockStore, p *mock_plugin.MockScorePlugin, se *mock_plugin.MockScorePluginExtender, as args) {
 				success1 := framework.NewStatus(framework.Success, "BeforeScore returned")
 				failure := framework.NewStatus(framework.Error, "Score returned")
@@ -1101,7 +1202,7 @@ func Test_wrappedPlugin_Score_WithPluginExtender(t *testing.T) {
 				p.EXPECT().Score(ctx, nil, as.pod, "node1").Return(int64(2222), failure)
 				se.EXPECT().AfterScore(ctx, nil, as.pod, "node1", int64(2222), failure).Return(int64(3333), success3)
 				p.EXPECT().Name().Return("fakeScorePlugin").AnyTimes()
-				s.EXPECT().AddScoreResult(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				s.EXPECT().AddScoreResult("default", "pod1", "node1", "fakeScorePlugin", int64(3333))
 			},
 			args: args{
 				pod:      &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
@@ -1125,7 +1226,7 @@ func Test_wrappedPlugin_Score_WithPluginExtender(t *testing.T) {
 			wantstatus: framework.NewStatus(framework.Error, "BeforeScore returned"),
 		},
 		{
-			name: "return AfterScore's results when AfterScore is fails",
+			name: "return AfterScore's results & does not call AddScoreResult, if AfterScore fails",
 			prepareEachMockFn: func(ctx context.Context, s *mock_plugin.MockStore, p *mock_plugin.MockScorePlugin, se *mock_plugin.MockScorePluginExtender, as args) {
 				success1 := framework.NewStatus(framework.Success, "BeforeScore returned")
 				success2 := framework.NewStatus(framework.Success, "Score returned")
@@ -1134,7 +1235,7 @@ func Test_wrappedPlugin_Score_WithPluginExtender(t *testing.T) {
 				p.EXPECT().Score(ctx, nil, as.pod, "node1").Return(int64(2222), success2)
 				se.EXPECT().AfterScore(ctx, nil, as.pod, "node1", int64(2222), success2).Return(int64(3333), failure)
 				p.EXPECT().Name().Return("fakeScorePlugin").AnyTimes()
-				s.EXPECT().AddScoreResult("default", "pod1", "node1", "fakeScorePlugin", int64(2222))
+				s.EXPECT().AddScoreResult(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			args: args{
 				pod:      &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
@@ -1508,6 +1609,47 @@ func Test_wrappedPlugin_Permit(t *testing.T) {
 	}
 }
 
+func Test_wrappedPlugin_Permit_PodGroup(t *testing.T) {
+	t.Parallel()
+	testPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "namespace", Labels: map[string]string{"scheduling.x-k8s.io/pod-group": "pg"}}}
+	testNodeName := "node"
+
+	ctrl := gomock.NewController(t)
+	s := mock_plugin.NewMockStore(ctrl)
+	p := mock_plugin.NewMockPermitPlugin(ctrl)
+	p.EXPECT().Permit(gomock.Any(), gomock.Any(), testPod, testNodeName).Return(framework.NewStatus(framework.Wait), time.Second)
+	p.EXPECT().Name().Return("Coscheduling").Times(2)
+	gomock.InOrder(
+		s.EXPECT().AddPermitResult("namespace", "pod", "Coscheduling", resultstore.WaitMessage, time.Second),
+		s.EXPECT().AddGangPermitResult("namespace", "pod", "Coscheduling", "namespace/pg", resultstore.WaitMessage, time.Second),
+		// The end of the waiting is recorded once in the cycle by the first plugin run after the Permit phase.
+		s.EXPECT().FinishGangWaiting("namespace", "pod", true),
+	)
+
+	state := framework.NewCycleState()
+	w := &wrappedPlugin{store: s, originalPermitPlugin: p}
+	got, got1 := w.Permit(context.Background(), state, testPod, testNodeName)
+	assert.Equal(t, framework.NewStatus(framework.Wait), got)
+	assert.Equal(t, time.Second, got1)
+	// Another wrapped plugin, e.g., the one of the binder, runs in the binding cycle.
+	other := &wrappedPlugin{store: s}
+	assert.Nil(t, other.PreBind(context.Background(), state, testPod, testNodeName))
+	assert.Equal(t, framework.NewStatus(framework.Skip, "called wrapped bind plugin is nil"), other.Bind(context.Background(), state, testPod, testNodeName))
+	w.Unreserve(context.Background(), state, testPod, testNodeName)
+
+	// The waiting in another cycle is recorded again.
+	p.EXPECT().Permit(gomock.Any(), gomock.Any(), testPod, testNodeName).Return(framework.NewStatus(framework.Wait), time.Second)
+	p.EXPECT().Name().Return("Coscheduling").Times(2)
+	gomock.InOrder(
+		s.EXPECT().AddPermitResult("namespace", "pod", "Coscheduling", resultstore.WaitMessage, time.Second),
+		s.EXPECT().AddGangPermitResult("namespace", "pod", "Coscheduling", "namespace/pg", resultstore.WaitMessage, time.Second),
+		s.EXPECT().FinishGangWaiting("namespace", "pod", false),
+	)
+	state = framework.NewCycleState()
+	w.Permit(context.Background(), state, testPod, testNodeName)
+	w.Unreserve(context.Background(), state, testPod, testNodeName)
+}
+
 func Test_wrappedPlugin_Reserve(t *testing.T) {
 	t.Parallel()
 	testPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "namespace"}}
@@ -0,0 +1,349 @@
+// Package queue looks into the scheduling queue of a running debuggable scheduler,
+// so that the Pods still waiting to be scheduled can be seen along with the scheduled ones.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/profile"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
+)
+
+// ErrSchedulerNotRunning is returned when the Inspector has no running scheduler.
+var ErrSchedulerNotRunning = errors.New("scheduler is not running")
+
+// Snapshot is the content of the scheduling queue at a point in time.
+//
+// The upstream scheduler only tells which Pods are in activeQ.
+// The Pods in the other queues are told apart by their backoff expiry and the number of Pods in backoffQ
+// reported in Summary, so a Pod may be shown in the wrong one of BackoffQ and Unschedulable
+// while it's moving between them.
+type Snapshot struct {
+	ActiveQ       []PodInfo `json:"activeQ"`
+	BackoffQ      []PodInfo `json:"backoffQ"`
+	Unschedulable []PodInfo `json:"unschedulable"`
+	// Summary is the numbers of the Pods in each queue reported by the scheduler.
+	Summary string `json:"summary"`
+	// Time is when the snapshot was taken.
+	Time time.Time `json:"time"`
+}
+
+// PodInfo is a Pod in the scheduling queue.
+type PodInfo struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	SchedulerName string `json:"schedulerName"`
+	// Attempts is the number of the scheduling attempts of the Pod.
+	Attempts int `json:"attempts"`
+	// Timestamp is when the Pod failed to be scheduled last time, or when it was created if it hasn't been attempted.
+	Timestamp               time.Time  `json:"timestamp"`
+	InitialAttemptTimestamp *time.Time `json:"initialAttemptTimestamp,omitempty"`
+	// BackoffExpiry is when the backoff of the Pod ends. It's nil when the Pod hasn't been attempted.
+	BackoffExpiry *time.Time `json:"backoffExpiry,omitempty"`
+	// UnschedulablePlugins are the plugins which rejected the Pod in the last attempt.
+	UnschedulablePlugins []string `json:"unschedulablePlugins,omitempty"`
+	// PendingPlugins are the plugins which returned Pending for the Pod in the last attempt.
+	PendingPlugins []string `json:"pendingPlugins,omitempty"`
+	// Gated is true when a PreEnqueue plugin keeps the Pod out of activeQ.
+	Gated bool `json:"gated"`
+	// Events are the cluster events which may move the Pod out of the unschedulable Pods,
+	// registered by UnschedulablePlugins and PendingPlugins.
+	Events []Event `json:"events,omitempty"`
+}
+
+// Event is a cluster event registered by a plugin via EventsToRegister.
+type Event struct {
+	Plugin string `json:"plugin"`
+	// Event is the label of the cluster event, e.g., "NodeAdd".
+	Event string `json:"event"`
+	// QueueingHint is true when the plugin checks the event with a queueing hint function
+	// before the Pod is moved, so the event doesn't always move the Pod.
+	QueueingHint bool `json:"queueingHint"`
+}
+
+// podQueue is the part of the scheduling queue used by the Inspector.
+type podQueue interface {
+	PendingPods() ([]*v1.Pod, string)
+	PodsInActiveQ() []*v1.Pod
+}
+
+// Inspector takes snapshots of the scheduling queue of the scheduler set by SetScheduler.
+// It's safe to replace the scheduler while taking snapshots, e.g., when the scheduler is restarted.
+type Inspector struct {
+	mu             sync.Mutex
+	queue          podQueue
+	profiles       profile.Map
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// failures are the last failed attempts of the Pods keyed by their namespaces and names.
+	// The ones of the Pods which have left the queue are removed when a snapshot is taken.
+	failures map[string]failedAttempt
+}
+
+// failedAttempt is the copy of the QueuedPodInfo of a Pod taken when it failed to be scheduled.
+// The QueuedPodInfo can't be read from the queue, because the queue modifies it under its own lock.
+type failedAttempt struct {
+	uid                     types.UID
+	attempts                int
+	timestamp               time.Time
+	initialAttemptTimestamp *time.Time
+	unschedulablePlugins    sets.Set[string]
+	pendingPlugins          sets.Set[string]
+}
+
+// NewInspector initializes Inspector without a scheduler.
+func NewInspector() *Inspector {
+	return &Inspector{failures: map[string]failedAttempt{}}
+}
+
+// SetScheduler sets the scheduler to look into, and the backoff settings it's created with.
+// A nil scheduler means that no scheduler is running.
+// It wraps the FailureHandler of the scheduler to copy the details of the failed attempts,
+// so it must be called before the scheduler runs.
+func (i *Inspector) SetScheduler(sched *scheduler.Scheduler, podInitialBackoffSeconds, podMaxBackoffSeconds int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.failures = map[string]failedAttempt{}
+	if sched == nil {
+		i.queue, i.profiles = nil, nil
+		return
+	}
+	var q podQueue = sched.SchedulingQueue
+	i.queue = q
+	i.profiles = sched.Profiles
+	i.initialBackoff = time.Duration(podInitialBackoffSeconds) * time.Second
+	i.maxBackoff = time.Duration(podMaxBackoffSeconds) * time.Second
+
+	handleFailure := sched.FailureHandler
+	sched.FailureHandler = func(ctx context.Context, fwk framework.Framework, podInfo *framework.QueuedPodInfo, status *framework.Status, nominatingInfo *framework.NominatingInfo, start time.Time) {
+		// The Pod isn't in the queue until handleFailure puts it back, so podInfo can be read here.
+		i.recordFailure(q, podInfo, status, time.Now())
+		handleFailure(ctx, fwk, podInfo, status, nominatingInfo, start)
+	}
+}
+
+// recordFailure copies the details of the failed attempt from podInfo.
+// The plugins which rejected the Pod are taken from the status in the same way as the upstream failure handler does.
+func (i *Inspector) recordFailure(q podQueue, podInfo *framework.QueuedPodInfo, status *framework.Status, now time.Time) {
+	unschedulable, pending := podInfo.UnschedulablePlugins, podInfo.PendingPlugins
+	if fitErr, ok := status.AsError().(*framework.FitError); ok {
+		unschedulable, pending = fitErr.Diagnosis.UnschedulablePlugins, fitErr.Diagnosis.PendingPlugins
+	}
+	f := failedAttempt{
+		uid:                  podInfo.Pod.UID,
+		attempts:             podInfo.Attempts,
+		timestamp:            now,
+		unschedulablePlugins: unschedulable.Clone(),
+		pendingPlugins:       pending.Clone(),
+	}
+	if podInfo.InitialAttemptTimestamp != nil {
+		t := *podInfo.InitialAttemptTimestamp
+		f.initialAttemptTimestamp = &t
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.queue != q {
+		// The attempt is of the scheduler which has been replaced.
+		return
+	}
+	if i.failures == nil {
+		i.failures = map[string]failedAttempt{}
+	}
+	i.failures[podInfo.Pod.Namespace+"/"+podInfo.Pod.Name] = f
+}
+
+// Snapshot returns the current content of the scheduling queue.
+// The Pods in each queue are ordered by their namespaces and names.
+func (i *Inspector) Snapshot(ctx context.Context) (*Snapshot, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.queue == nil {
+		return nil, xerrors.Errorf("take snapshot of scheduling queue: %w", ErrSchedulerNotRunning)
+	}
+	events, err := registeredEvents(ctx, i.profiles)
+	if err != nil {
+		return nil, xerrors.Errorf("get events registered by plugins: %w", err)
+	}
+	return i.snapshot(ctx, i.queue, events, time.Now()), nil
+}
+
+func (i *Inspector) snapshot(ctx context.Context, q podQueue, events map[string]map[string][]Event, now time.Time) *Snapshot {
+	pending, summary := q.PendingPods()
+	active := sets.New[string]()
+	for _, p := range q.PodsInActiveQ() {
+		active.Insert(p.Namespace + "/" + p.Name)
+	}
+
+	ret := &Snapshot{ActiveQ: []PodInfo{}, BackoffQ: []PodInfo{}, Unschedulable: []PodInfo{}, Summary: summary, Time: now}
+	var others []PodInfo
+	inQueue := sets.New[string]()
+	for _, p := range pending {
+		key := p.Namespace + "/" + p.Name
+		inQueue.Insert(key)
+		if active.Has(key) {
+			ret.ActiveQ = append(ret.ActiveQ, i.podInfo(p, false, events[p.Spec.SchedulerName]))
+			continue
+		}
+		others = append(others, i.podInfo(p, gated(ctx, i.profiles[p.Spec.SchedulerName], p), events[p.Spec.SchedulerName]))
+	}
+	ret.BackoffQ, ret.Unschedulable = splitBackoffQ(others, summary, now)
+
+	// The Pods being scheduled aren't in the queue either, but their attempts are recorded again if they fail.
+	for key := range i.failures {
+		if !inQueue.Has(key) {
+			delete(i.failures, key)
+		}
+	}
+
+	for _, q := range [][]PodInfo{ret.ActiveQ, ret.BackoffQ, ret.Unschedulable} {
+		sort.Slice(q, func(a, b int) bool {
+			if q[a].Namespace != q[b].Namespace {
+				return q[a].Namespace < q[b].Namespace
+			}
+			return q[a].Name < q[b].Name
+		})
+	}
+	return ret
+}
+
+// gated tells if a PreEnqueue plugin of the framework keeps the Pod out of activeQ.
+// The queue doesn't tell it without the lock, so the plugins are run against the Pod again.
+func gated(ctx context.Context, fwk framework.Framework, pod *v1.Pod) bool {
+	if fwk == nil {
+		return false
+	}
+	for _, pl := range fwk.PreEnqueuePlugins() {
+		if s := pl.PreEnqueue(ctx, pod); !s.IsSuccess() {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBackoffQ tells the Pods in backoffQ from the unschedulable Pods.
+// The Pods whose backoff hasn't expired are regarded as the ones in backoffQ
+// if their number matches the one in the summary.
+// Otherwise, some of them are still in the unschedulable Pods,
+// and only the ones failed without being rejected by any plugins, e.g., by an error, are regarded as in backoffQ.
+func splitBackoffQ(pods []PodInfo, summary string, now time.Time) ([]PodInfo, []PodInfo) {
+	var backingOff []int
+	for idx, p := range pods {
+		if !p.Gated && p.BackoffExpiry != nil && p.BackoffExpiry.After(now) {
+			backingOff = append(backingOff, idx)
+		}
+	}
+
+	var activeQ, backoffQ, unschedulable int
+	_, err := fmt.Sscanf(summary, "activeQ:%d; backoffQ:%d; unschedulablePods:%d", &activeQ, &backoffQ, &unschedulable)
+	countMatches := err == nil && backoffQ == len(backingOff)
+
+	inBackoffQ := sets.New[int]()
+	for _, idx := range backingOff {
+		p := pods[idx]
+		if countMatches || len(p.UnschedulablePlugins)+len(p.PendingPlugins) == 0 {
+			inBackoffQ.Insert(idx)
+		}
+	}
+
+	backoffPods, unschedulablePods := []PodInfo{}, []PodInfo{}
+	for idx, p := range pods {
+		if inBackoffQ.Has(idx) {
+			backoffPods = append(backoffPods, p)
+			continue
+		}
+		unschedulablePods = append(unschedulablePods, p)
+	}
+	return backoffPods, unschedulablePods
+}
+
+// podInfo builds the PodInfo from the Pod and its last failed attempt.
+// The Pods which haven't failed are regarded as added to the queue when they were created.
+func (i *Inspector) podInfo(pod *v1.Pod, gated bool, events map[string][]Event) PodInfo {
+	info := PodInfo{
+		Namespace:     pod.Namespace,
+		Name:          pod.Name,
+		SchedulerName: pod.Spec.SchedulerName,
+		Timestamp:     pod.CreationTimestamp.Time,
+		Gated:         gated,
+	}
+	f, ok := i.failures[pod.Namespace+"/"+pod.Name]
+	if !ok || f.uid != pod.UID {
+		return info
+	}
+	info.Attempts = f.attempts
+	info.Timestamp = f.timestamp
+	info.InitialAttemptTimestamp = f.initialAttemptTimestamp
+	info.UnschedulablePlugins = originalNames(f.unschedulablePlugins)
+	info.PendingPlugins = originalNames(f.pendingPlugins)
+	if f.attempts > 0 {
+		expiry := f.timestamp.Add(i.backoffDuration(f.attempts))
+		info.BackoffExpiry = &expiry
+	}
+	for _, pl := range sets.List(f.unschedulablePlugins.Union(f.pendingPlugins)) {
+		info.Events = append(info.Events, events[pl]...)
+	}
+	return info
+}
+
+// backoffDuration is the same as the one the upstream scheduling queue calculates:
+// it doubles from the initial backoff on every attempt, up to the max backoff.
+func (i *Inspector) backoffDuration(attempts int) time.Duration {
+	d := i.initialBackoff
+	for n := 1; n < attempts; n++ {
+		if d > i.maxBackoff-d {
+			return i.maxBackoff
+		}
+		d += d
+	}
+	return d
+}
+
+// registeredEvents returns the events registered by each plugin in each profile.
+// The events are keyed by the profile names and then by the plugin names the scheduler uses, i.e., the wrapped ones.
+func registeredEvents(ctx context.Context, profiles profile.Map) (map[string]map[string][]Event, error) {
+	ret := make(map[string]map[string][]Event, len(profiles))
+	for name, fwk := range profiles {
+		ret[name] = map[string][]Event{}
+		for _, ext := range fwk.EnqueueExtensions() {
+			evs, err := ext.EventsToRegister(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("get events of plugin %s in profile %s: %w", ext.Name(), name, err)
+			}
+			original := plugin.OriginalPluginName(ext.Name())
+			for _, ev := range evs {
+				ret[name][ext.Name()] = append(ret[name][ext.Name()], Event{
+					Plugin:       original,
+					Event:        ev.Event.Label(),
+					QueueingHint: ev.QueueingHintFn != nil,
+				})
+			}
+		}
+	}
+	return ret, nil
+}
+
+func originalNames(plugins sets.Set[string]) []string {
+	if plugins.Len() == 0 {
+		return nil
+	}
+	ret := make([]string, 0, plugins.Len())
+	for _, p := range sets.List(plugins) {
+		ret = append(ret, plugin.OriginalPluginName(p))
+	}
+	return ret
+}
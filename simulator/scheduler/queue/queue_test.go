@@ -0,0 +1,225 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler"
+	internalqueue "k8s.io/kubernetes/pkg/scheduler/backend/queue"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
+)
+
+type fakeQueue struct {
+	pods    []*v1.Pod
+	active  sets.Set[string]
+	summary string
+}
+
+func (q *fakeQueue) PendingPods() ([]*v1.Pod, string) {
+	return q.pods, q.summary
+}
+
+func (q *fakeQueue) PodsInActiveQ() []*v1.Pod {
+	pods := []*v1.Pod{}
+	for _, p := range q.pods {
+		if q.active.Has(p.Name) {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
+
+func pod(name string, uid types.UID) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: uid},
+		Spec:       v1.PodSpec{SchedulerName: "default-scheduler"},
+	}
+}
+
+func queuedPod(p *v1.Pod, attempts int, unschedulablePlugins ...string) *framework.QueuedPodInfo {
+	return &framework.QueuedPodInfo{
+		PodInfo:              &framework.PodInfo{Pod: p},
+		Attempts:             attempts,
+		UnschedulablePlugins: sets.New(unschedulablePlugins...),
+	}
+}
+
+func rejectedBy(p *v1.Pod, plugins ...string) *framework.Status {
+	return framework.AsStatus(&framework.FitError{
+		Pod:         p,
+		NumAllNodes: 1,
+		Diagnosis:   framework.Diagnosis{UnschedulablePlugins: sets.New(plugins...)},
+	})
+}
+
+func TestInspector_snapshot(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := map[string]map[string][]Event{
+		"default-scheduler": {
+			"NodeResourcesFitWrapped": {{Plugin: "NodeResourcesFit", Event: "NodeAdd", QueueingHint: true}},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		summary           string
+		wantActiveQ       []string
+		wantBackoffQ      []string
+		wantUnschedulable []string
+	}{
+		{
+			name:              "the Pods backing off are in backoffQ when their number matches the summary",
+			summary:           "activeQ:1; backoffQ:2; unschedulablePods:2",
+			wantActiveQ:       []string{"new"},
+			wantBackoffQ:      []string{"errored", "rejected-recently"},
+			wantUnschedulable: []string{"recreated", "rejected-long-ago"},
+		},
+		{
+			name:              "only the Pods without rejecting plugins are in backoffQ when the number doesn't match",
+			summary:           "activeQ:1; backoffQ:1; unschedulablePods:3",
+			wantActiveQ:       []string{"new"},
+			wantBackoffQ:      []string{"errored"},
+			wantUnschedulable: []string{"recreated", "rejected-long-ago", "rejected-recently"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			newPod, recentlyRejected, rejectedLongAgo, errored, recreated := pod("new", "1"), pod("rejected-recently", "2"), pod("rejected-long-ago", "3"), pod("errored", "4"), pod("recreated", "5")
+			q := &fakeQueue{
+				pods:    []*v1.Pod{newPod, recentlyRejected, rejectedLongAgo, errored, recreated},
+				active:  sets.New("new"),
+				summary: tt.summary,
+			}
+			i := &Inspector{queue: q, initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+			// The backoff is 2s after the 2nd attempt.
+			i.recordFailure(q, queuedPod(recentlyRejected, 2), rejectedBy(recentlyRejected, "NodeResourcesFitWrapped"), now.Add(-time.Second))
+			i.recordFailure(q, queuedPod(rejectedLongAgo, 1), rejectedBy(rejectedLongAgo, "NodeResourcesFitWrapped"), now.Add(-time.Minute))
+			i.recordFailure(q, queuedPod(errored, 1), framework.AsStatus(errors.New("error")), now)
+			// The attempt of the Pod deleted before being recreated with the same name.
+			i.recordFailure(q, queuedPod(pod("recreated", "6"), 1), framework.AsStatus(errors.New("error")), now)
+			// The Pod which has been scheduled.
+			i.recordFailure(q, queuedPod(pod("scheduled", "7"), 1), framework.AsStatus(errors.New("error")), now)
+
+			got := i.snapshot(context.Background(), q, events, now)
+			assert.Equal(t, tt.wantActiveQ, names(got.ActiveQ))
+			assert.Equal(t, tt.wantBackoffQ, names(got.BackoffQ))
+			assert.Equal(t, tt.wantUnschedulable, names(got.Unschedulable))
+			assert.Equal(t, tt.summary, got.Summary)
+
+			for _, p := range append(got.BackoffQ, got.Unschedulable...) {
+				switch p.Name {
+				case "rejected-recently":
+					assert.Equal(t, now.Add(time.Second), *p.BackoffExpiry)
+					assert.Equal(t, 2, p.Attempts)
+					assert.Equal(t, []string{"NodeResourcesFit"}, p.UnschedulablePlugins)
+					assert.Equal(t, []Event{{Plugin: "NodeResourcesFit", Event: "NodeAdd", QueueingHint: true}}, p.Events)
+				case "recreated":
+					assert.Equal(t, 0, p.Attempts)
+					assert.Nil(t, p.BackoffExpiry)
+				}
+			}
+			assert.Nil(t, got.ActiveQ[0].BackoffExpiry)
+			assert.NotContains(t, i.failures, "default/scheduled")
+			assert.Contains(t, i.failures, "default/errored")
+		})
+	}
+}
+
+func TestInspector_recordFailure(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &fakeQueue{}
+	i := &Inspector{queue: q}
+
+	p := pod("pod", "1")
+	initial := now.Add(-time.Minute)
+	podInfo := queuedPod(p, 2, "NodeAffinityWrapped")
+	initialAttemptTimestamp := initial
+	podInfo.InitialAttemptTimestamp = &initialAttemptTimestamp
+	i.recordFailure(q, podInfo, framework.AsStatus(errors.New("error")), now)
+	// The queue modifies the QueuedPodInfo after the failure is recorded.
+	podInfo.Attempts = 3
+	podInfo.UnschedulablePlugins.Insert("TaintTolerationWrapped")
+	*podInfo.InitialAttemptTimestamp = now
+
+	assert.Equal(t, failedAttempt{
+		uid:                     "1",
+		attempts:                2,
+		timestamp:               now,
+		initialAttemptTimestamp: &initial,
+		unschedulablePlugins:    sets.New("NodeAffinityWrapped"),
+		pendingPlugins:          sets.New[string](),
+	}, i.failures["default/pod"])
+
+	// The failure of the scheduler which has been replaced isn't recorded.
+	i.recordFailure(&fakeQueue{}, queuedPod(pod("old", "2"), 1), framework.AsStatus(errors.New("error")), now)
+	assert.NotContains(t, i.failures, "default/old")
+}
+
+func TestInspector_backoffDuration(t *testing.T) {
+	t.Parallel()
+
+	i := &Inspector{initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+	assert.Equal(t, time.Second, i.backoffDuration(1))
+	assert.Equal(t, 4*time.Second, i.backoffDuration(3))
+	assert.Equal(t, 10*time.Second, i.backoffDuration(5))
+}
+
+func names(pods []PodInfo) []string {
+	ret := []string{}
+	for _, p := range pods {
+		ret = append(ret, p.Name)
+	}
+	return ret
+}
+
+func TestInspector_SetScheduler(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The scheduling queue records the metrics.
+	metrics.Register()
+	i := NewInspector()
+	_, err := i.Snapshot(ctx)
+	assert.ErrorIs(t, err, ErrSchedulerNotRunning)
+
+	var handled *framework.QueuedPodInfo
+	sched := &scheduler.Scheduler{
+		SchedulingQueue: internalqueue.NewTestQueue(ctx, nil),
+		FailureHandler: func(_ context.Context, _ framework.Framework, podInfo *framework.QueuedPodInfo, _ *framework.Status, _ *framework.NominatingInfo, _ time.Time) {
+			handled = podInfo
+		},
+	}
+	i.SetScheduler(sched, 1, 10)
+	assert.Equal(t, 10*time.Second, i.maxBackoff)
+
+	p := pod("pod", "1")
+	podInfo := queuedPod(p, 1)
+	sched.FailureHandler(ctx, nil, podInfo, rejectedBy(p, "NodeResourcesFitWrapped"), nil, time.Now())
+	assert.Same(t, podInfo, handled)
+	require.Contains(t, i.failures, "default/pod")
+	assert.Equal(t, sets.New("NodeResourcesFitWrapped"), i.failures["default/pod"].unschedulablePlugins)
+
+	_, err = i.Snapshot(ctx)
+	require.NoError(t, err)
+
+	i.SetScheduler(nil, 0, 0)
+	_, err = i.Snapshot(ctx)
+	assert.ErrorIs(t, err, ErrSchedulerNotRunning)
+	assert.Empty(t, i.failures)
+}
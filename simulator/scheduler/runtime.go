@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"golang.org/x/xerrors"
+	configv1 "k8s.io/kube-scheduler/config/v1"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
+)
+
+// debuggableSchedulerProxyPort is the default port of the proxy server in the debuggable scheduler,
+// which serves the scheduling queue and the breakpoints as well as the requests for extenders.
+const debuggableSchedulerProxyPort = 1212
+
+// Runtime runs a debuggable scheduler and restarts it with a new configuration.
+type Runtime interface {
+	// Start starts the scheduler with cfg when the simulator starts.
+	Start(cfg *configv1.KubeSchedulerConfiguration) error
+	// Restart restarts the scheduler with cfg.
+	Restart(cfg *configv1.KubeSchedulerConfiguration) error
+	// Shutdown stops the scheduler.
+	Shutdown()
+	// SchedulingQueue returns the snapshot of the scheduling queue of the running scheduler.
+	SchedulingQueue(ctx context.Context) (*queue.Snapshot, error)
+	// ExtenderCalls returns the calls to the extenders for the Pod.
+	ExtenderCalls(ctx context.Context, namespace, podName string) ([]extender.Call, error)
+	// Debugger returns the Debugger to manage the breakpoints of the scheduler.
+	Debugger() breakpoint.Debugger
+}
+
+// dockerRuntime manages the scheduler running in a container.
+// The container is started along with the simulator (e.g., by compose.yml),
+// and the config file is shared between the container and the simulator.
+type dockerRuntime struct {
+	containerName string
+	// writeCfg writes the config to the file mounted on the container.
+	writeCfg func(*configv1.KubeSchedulerConfiguration) error
+}
+
+// NewDockerRuntime initializes the Runtime to restart the container with the name via the Docker daemon.
+// writeCfg is called to write the config to the file mounted on the container before restarting it.
+func NewDockerRuntime(containerName string, writeCfg func(*configv1.KubeSchedulerConfiguration) error) Runtime {
+	return &dockerRuntime{containerName: containerName, writeCfg: writeCfg}
+}
+
+// Start does nothing because the container is started outside the simulator.
+func (r *dockerRuntime) Start(_ *configv1.KubeSchedulerConfiguration) error {
+	return nil
+}
+
+func (r *dockerRuntime) Restart(cfg *configv1.KubeSchedulerConfiguration) error {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return xerrors.Errorf("failed to create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return xerrors.Errorf("failed to get container list: %w", err)
+	}
+	for _, c := range containers {
+		if c.Names[0] != "/"+r.containerName {
+			continue
+		}
+		if err := r.writeCfg(cfg); err != nil {
+			return xerrors.Errorf("write scheduler config: %w", err)
+		}
+
+		if err := cli.ContainerRestart(ctx, c.ID, container.StopOptions{}); err != nil {
+			return xerrors.Errorf("failed restart container: %w", err)
+		}
+		inspect, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return xerrors.Errorf("failed get container inspect: %w", err)
+		}
+		if inspect.State.Status != "running" {
+			return xerrors.Errorf("restart container status is not running")
+		}
+		return nil
+	}
+
+	return xerrors.Errorf("can not find %s, are you running the debuggable scheduler along with this simulator container?", r.containerName)
+}
+
+// Shutdown does nothing because the container is stopped outside the simulator.
+func (r *dockerRuntime) Shutdown() {}
+
+// SchedulingQueue gets the snapshot from the proxy server in the container,
+// which is reachable by the container name on the network shared with the simulator.
+func (r *dockerRuntime) SchedulingQueue(ctx context.Context) (*queue.Snapshot, error) {
+	url := r.proxyURL() + "/queue"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, xerrors.Errorf("create request to %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("get scheduling queue from %s: %w", r.containerName, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("get scheduling queue from %s: unexpected status %s", r.containerName, resp.Status)
+	}
+
+	snapshot := &queue.Snapshot{}
+	if err := json.NewDecoder(resp.Body).Decode(snapshot); err != nil {
+		return nil, xerrors.Errorf("decode scheduling queue: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ExtenderCalls gets the calls recorded on the proxy server in the container.
+func (r *dockerRuntime) ExtenderCalls(ctx context.Context, namespace, podName string) ([]extender.Call, error) {
+	query := url.Values{}
+	query.Set("namespace", namespace)
+	query.Set("pod", podName)
+	u := r.proxyURL() + "/extender/calls?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, xerrors.Errorf("create request to %s: %w", u, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("get extender calls from %s: %w", r.containerName, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("get extender calls from %s: unexpected status %s", r.containerName, resp.Status)
+	}
+
+	calls := []extender.Call{}
+	if err := json.NewDecoder(resp.Body).Decode(&calls); err != nil {
+		return nil, xerrors.Errorf("decode extender calls: %w", err)
+	}
+	return calls, nil
+}
+
+// Debugger returns the client of the breakpoint API on the proxy server in the container.
+func (r *dockerRuntime) Debugger() breakpoint.Debugger {
+	return breakpoint.NewClient(r.proxyURL())
+}
+
+// proxyURL is the URL of the API group on the proxy server in the container.
+func (r *dockerRuntime) proxyURL() string {
+	return fmt.Sprintf("http://%s:%d/api/v1", r.containerName, debuggableSchedulerProxyPort)
+}
@@ -3,9 +3,8 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
 
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
 	"golang.org/x/xerrors"
 	v1 "k8s.io/api/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
@@ -18,23 +17,50 @@ import (
 	apiconfigv1 "k8s.io/kubernetes/pkg/scheduler/apis/config/v1"
 
 	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
 	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/plugin"
-	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 )
 
 // Service manages scheduler.
 type Service struct {
-	// function to shutdown scheduler.
-	shutdownfn func()
+	// runtime runs the default scheduler.
+	runtime Runtime
 
 	clientset           clientset.Interface
 	restclientCfg       *restclient.Config
 	initialSchedulerCfg *configv1.KubeSchedulerConfiguration
 	currentSchedulerCfg *configv1.KubeSchedulerConfiguration
 	extenderService     ExtenderService
-	sharedStore         storereflector.Reflector
 	simulatorPort       int
+	// additionalSchedulers are the schedulers run along with the default one, in the order of the simulator config.
+	additionalSchedulers []*additionalScheduler
+	// history keeps the configurations applied to the default scheduler.
+	history *configHistory
+	// wasmModuleDir is the directory the uploaded wasm modules are stored in. defaultWasmModuleDir is used when it's empty.
+	wasmModuleDir string
+}
+
+// additionalScheduler is a debuggable scheduler run along with the default one.
+type additionalScheduler struct {
+	name          string
+	containerName string
+	runtime       Runtime
+	initialCfg    *configv1.KubeSchedulerConfiguration
+	currentCfg    *configv1.KubeSchedulerConfiguration
+	history       *configHistory
+}
+
+// Scheduler describes a debuggable scheduler managed by Service.
+type Scheduler struct {
+	Name string `json:"name"`
+	// ContainerName is empty when the scheduler runs in the simulator process.
+	ContainerName string `json:"containerName,omitempty"`
+	// SchedulerNames are the schedulerName of the profiles.
+	// Pods whose spec.schedulerName is one of them are scheduled by this scheduler.
+	SchedulerNames []string `json:"schedulerNames"`
 }
 
 type ExtenderService interface {
@@ -44,80 +70,256 @@ type ExtenderService interface {
 	Bind(id int, args extenderv1.ExtenderBindingArgs) (*extenderv1.ExtenderBindingResult, error)
 }
 
-var ErrServiceDisabled = errors.New("scheduler service is disabled")
+var (
+	ErrServiceDisabled = errors.New("scheduler service is disabled")
+	// ErrSchedulerNotFound is returned when no scheduler has the given name.
+	ErrSchedulerNotFound = errors.New("scheduler not found")
+	// ErrSchedulerNameConflict is returned when a configuration has a schedulerName used by another scheduler.
+	ErrSchedulerNameConflict = errors.New("schedulerName is used by another scheduler")
+	// ErrExtenderCallsNotRecorded is returned when the scheduler calls the extenders directly without the simulator server.
+	ErrExtenderCallsNotRecorded = errors.New("the calls to the extenders aren't recorded for the scheduler")
+	// ErrRolledBack is returned when the scheduler fails to start with a new configuration and runs with the old one again.
+	ErrRolledBack = errors.New("the scheduler is rolled back to the old configuration")
+)
 
-// NewSchedulerService starts scheduler and return *Service.
-func NewSchedulerService(client clientset.Interface, restclientCfg *restclient.Config, initialSchedulerCfg *configv1.KubeSchedulerConfiguration, simulatorPort int) *Service {
-	// sharedStore has some resultstores which are referenced by Registry of Plugins and Extenders.
-	sharedStore := storereflector.New()
+// defaultSchedulerContainerName is the name of the container running the default scheduler.
+const defaultSchedulerContainerName = "simulator-scheduler"
 
-	initCfg := initialSchedulerCfg.DeepCopy()
-	return &Service{clientset: client, restclientCfg: restclientCfg, initialSchedulerCfg: initCfg, sharedStore: sharedStore, simulatorPort: simulatorPort}
+// Options configures Service.
+type Options struct {
+	// AdditionalSchedulers are run along with the default scheduler, and managed by their names.
+	AdditionalSchedulers []simulatorconfig.AdditionalSchedulerConfig
+	// SchedulerRuntime decides whether the schedulers run in containers or in the simulator process.
+	SchedulerRuntime simulatorconfig.SchedulerRuntime
+	// RecordCycleState makes the schedulers in the simulator process record the CycleState.
+	RecordCycleState bool
+	// PluginExtenders are attached to the plugins of the schedulers in the simulator process.
+	PluginExtenders map[string]plugin.PluginExtenderInitializer
+	// ExtenderOpts configure the extender services of the schedulers in the simulator process.
+	ExtenderOpts []extender.Option
+	// SimulatorPort is the port of the simulator server, which the requests for the extenders of the schedulers in the simulator process are directed to.
+	SimulatorPort int
 }
 
-func restartContainer(ctx context.Context, cli *client.Client, cfg *configv1.KubeSchedulerConfiguration) error {
-	containers, err := cli.ContainerList(ctx, container.ListOptions{})
-	if err != nil {
-		return xerrors.Errorf("failed to get container list: %w", err)
+// NewSchedulerService initializes Service. The schedulers are started by StartScheduler.
+func NewSchedulerService(client clientset.Interface, restclientCfg *restclient.Config, initialSchedulerCfg *configv1.KubeSchedulerConfiguration, opts Options) *Service {
+	initCfg := initialSchedulerCfg.DeepCopy()
+	s := &Service{clientset: client, restclientCfg: restclientCfg, initialSchedulerCfg: initCfg, simulatorPort: opts.SimulatorPort, history: newConfigHistory(initCfg)}
+
+	if opts.SchedulerRuntime == simulatorconfig.InProcessSchedulerRuntime {
+		rt := NewInProcessRuntime(client, restclientCfg, InProcessOptions{
+			SimulatorPort:    opts.SimulatorPort,
+			RecordCycleState: opts.RecordCycleState,
+			PluginExtenders:  opts.PluginExtenders,
+			ExtenderOpts:     opts.ExtenderOpts,
+		})
+		s.runtime = rt
+		// The requests for the extenders of the default scheduler are directed to the simulator server.
+		s.extenderService, _ = rt.(ExtenderService)
+	} else {
+		s.runtime = NewDockerRuntime(defaultSchedulerContainerName, simulatorschedconfig.UpdateSchedulerConfig)
 	}
-	for _, c := range containers {
-		if c.Names[0] != "/simulator-scheduler" {
-			continue
-		}
-		if err := simulatorschedconfig.UpdateSchedulerConfig(cfg); err != nil {
-			return xerrors.Errorf("read old scheduler.yaml: %w", err)
-		}
 
-		if err := cli.ContainerRestart(ctx, c.ID, container.StopOptions{}); err != nil {
-			return xerrors.Errorf("failed restart container: %w", err)
+	for _, as := range opts.AdditionalSchedulers {
+		a := &additionalScheduler{
+			name:       as.Name,
+			initialCfg: as.InitialSchedulerCfg.DeepCopy(),
+			currentCfg: as.InitialSchedulerCfg.DeepCopy(),
+			history:    newConfigHistory(as.InitialSchedulerCfg),
 		}
-		inspect, err := cli.ContainerInspect(ctx, c.ID)
-		if err != nil {
-			return xerrors.Errorf("failed get container inspect: %w", err)
+		if opts.SchedulerRuntime == simulatorconfig.InProcessSchedulerRuntime {
+			// The requests for the extenders of the additional schedulers are directed to their own endpoints of the simulator server.
+			a.runtime = NewInProcessRuntime(client, restclientCfg, InProcessOptions{
+				SimulatorPort:    opts.SimulatorPort,
+				SchedulerName:    as.Name,
+				RecordCycleState: opts.RecordCycleState,
+				PluginExtenders:  opts.PluginExtenders,
+				ExtenderOpts:     opts.ExtenderOpts,
+			})
+		} else {
+			configPath := as.ConfigPath
+			a.containerName = as.ContainerName
+			a.runtime = NewDockerRuntime(as.ContainerName, func(cfg *configv1.KubeSchedulerConfiguration) error {
+				return simulatorschedconfig.WriteSchedulerConfig(configPath, cfg)
+			})
 		}
-		if inspect.State.Status != "running" {
-			return xerrors.Errorf("restart container status is not running")
+		s.additionalSchedulers = append(s.additionalSchedulers, a)
+	}
+	return s
+}
+
+// StartScheduler starts all schedulers with their initial configs.
+// It does nothing for the schedulers in containers, which are started outside the simulator.
+func (s *Service) StartScheduler() error {
+	if err := s.runtime.Start(s.initialSchedulerCfg.DeepCopy()); err != nil {
+		return xerrors.Errorf("start scheduler: %w", err)
+	}
+	for _, as := range s.additionalSchedulers {
+		if err := as.runtime.Start(as.initialCfg.DeepCopy()); err != nil {
+			return xerrors.Errorf("start scheduler %s: %w", as.name, err)
 		}
-		return nil
 	}
+	return nil
+}
 
-	return xerrors.New("can not find simulator-scheduler, are you running the debuggable scheduler along with this simulator container?")
+// restartWithRollback restarts the scheduler with cfg.
+// If it fails, the scheduler is restarted with oldCfg again and ErrRolledBack is returned.
+func restartWithRollback(rt Runtime, cfg, oldCfg *configv1.KubeSchedulerConfiguration) error {
+	restartErr := rt.Restart(cfg)
+	if restartErr == nil {
+		return nil
+	}
+	klog.Errorf("failed to apply new scheduler config: %v", restartErr)
+	// If failing restarting the scheduler, we roll back to the old config.
+	if err := rt.Restart(oldCfg); err != nil {
+		return xerrors.Errorf("oldConfig restart failed: %w", err)
+	}
+	return xerrors.Errorf("%w: %v", ErrRolledBack, restartErr)
 }
 
 // RestartScheduler restarts the debuggable scheduler with a new config.
-// Specifically, it updates the config file, which is also mounted on the debuggable scheduler,
+// When the scheduler runs in a container, it updates the config file, which is also mounted on the debuggable scheduler,
 // and then restart the debuggable scheduler.
 func (s *Service) RestartScheduler(cfg *configv1.KubeSchedulerConfiguration) error {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	return s.RestartNamedSchedulerWithComment(simulatorconfig.DefaultSchedulerName, cfg, "")
+}
+
+func (s *Service) restartDefaultScheduler(cfg *configv1.KubeSchedulerConfiguration, comment string) error {
+	oldCfg := s.currentSchedulerCfg
+	if oldCfg == nil {
+		oldCfg = s.initialSchedulerCfg
+	}
+	if err := restartWithRollback(s.runtime, cfg, oldCfg.DeepCopy()); err != nil {
+		return err
+	}
+	s.SetSchedulerConfig(cfg)
+	s.history.record(cfg, comment)
+	return nil
+}
+
+// RestartNamedScheduler restarts the scheduler with the name with a new config.
+// The default scheduler can be restarted with simulatorconfig.DefaultSchedulerName as well.
+func (s *Service) RestartNamedScheduler(name string, cfg *configv1.KubeSchedulerConfiguration) error {
+	return s.RestartNamedSchedulerWithComment(name, cfg, "")
+}
+
+// RestartNamedSchedulerWithComment is the same as RestartNamedScheduler,
+// but the comment is recorded in the history along with the new config.
+func (s *Service) RestartNamedSchedulerWithComment(name string, cfg *configv1.KubeSchedulerConfiguration, comment string) error {
+	if name == simulatorconfig.DefaultSchedulerName {
+		if err := s.checkSchedulerNames(name, cfg); err != nil {
+			return err
+		}
+		return s.restartDefaultScheduler(cfg, comment)
+	}
+	as := s.additionalScheduler(name)
+	if as == nil {
+		return xerrors.Errorf("restart scheduler %s: %w", name, ErrSchedulerNotFound)
+	}
+	if err := s.checkSchedulerNames(name, cfg); err != nil {
+		return err
+	}
+	return as.restart(cfg, comment)
+}
+
+func (as *additionalScheduler) restart(cfg *configv1.KubeSchedulerConfiguration, comment string) error {
+	if err := restartWithRollback(as.runtime, cfg, as.currentCfg.DeepCopy()); err != nil {
+		return xerrors.Errorf("restart scheduler %s: %w", as.name, err)
+	}
+	as.currentCfg = cfg.DeepCopy()
+	as.history.record(cfg, comment)
+	return nil
+}
+
+// ListSchedulerConfigVersions returns all versions of the configurations applied to the scheduler with the name.
+// The configurations themselves are omitted; see GetSchedulerConfigVersion.
+func (s *Service) ListSchedulerConfigVersions(name string) ([]ConfigVersion, error) {
+	h, err := s.configHistory(name)
 	if err != nil {
-		return xerrors.Errorf("failed to create docker client: %w", err)
+		return nil, xerrors.Errorf("list config versions: %w", err)
 	}
+	return h.list(), nil
+}
 
-	oldCfg, err := simulatorconfig.GetSchedulerCfg()
+// GetSchedulerConfigVersion returns the configuration with the version applied to the scheduler with the name.
+func (s *Service) GetSchedulerConfigVersion(name string, version int) (*ConfigVersion, error) {
+	h, err := s.configHistory(name)
 	if err != nil {
-		return xerrors.Errorf("read old scheduler.yaml: %w", err)
+		return nil, xerrors.Errorf("get config version: %w", err)
 	}
+	return h.get(version)
+}
 
-	if err := restartContainer(ctx, cli, cfg); err != nil {
-		klog.Errorf("failed to apply new scheduler config: %v", err)
-		// If failing restarting the container, we roll back to the old config.
-		if err := restartContainer(ctx, cli, oldCfg); err != nil {
-			return xerrors.Errorf("oldConfig restart failed: %w", err)
-		}
+// DiffSchedulerConfigVersions returns the fields changed from the version "from" to the version "to"
+// of the configurations applied to the scheduler with the name.
+func (s *Service) DiffSchedulerConfigVersions(name string, from, to int) ([]simulatorschedconfig.Change, error) {
+	h, err := s.configHistory(name)
+	if err != nil {
+		return nil, xerrors.Errorf("diff config versions: %w", err)
 	}
-	s.SetSchedulerConfig(cfg)
-	return nil
+	fromVersion, err := h.get(from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := h.get(to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := simulatorschedconfig.DiffSchedulerConfigs(fromVersion.Config, toVersion.Config)
+	if err != nil {
+		return nil, xerrors.Errorf("diff versions %d and %d: %w", from, to, err)
+	}
+	return changes, nil
+}
+
+// RollbackScheduler restarts the scheduler with the name with the configuration of the version.
+// The rollback is recorded as a new version so that it can be undone as well.
+func (s *Service) RollbackScheduler(name string, version int) error {
+	h, err := s.configHistory(name)
+	if err != nil {
+		return xerrors.Errorf("rollback scheduler: %w", err)
+	}
+	v, err := h.get(version)
+	if err != nil {
+		return xerrors.Errorf("rollback scheduler %s: %w", name, err)
+	}
+	return s.RestartNamedSchedulerWithComment(name, v.Config, fmt.Sprintf("rollback to version %d", version))
+}
+
+func (s *Service) configHistory(name string) (*configHistory, error) {
+	if name == simulatorconfig.DefaultSchedulerName {
+		return s.history, nil
+	}
+	as := s.additionalScheduler(name)
+	if as == nil {
+		return nil, xerrors.Errorf("scheduler %s: %w", name, ErrSchedulerNotFound)
+	}
+	return as.history, nil
 }
 
+// resetComment is recorded in the history when the schedulers are reset.
+const resetComment = "reset to the initial configuration"
+
+// ResetScheduler restarts all schedulers with their initial configs.
+// The schedulerNames aren't checked here because the initial configs are validated when the simulator starts,
+// and the current configs of the other schedulers may conflict with them until all schedulers are reset.
 func (s *Service) ResetScheduler() error {
-	return s.RestartScheduler(s.initialSchedulerCfg.DeepCopy())
+	if err := s.restartDefaultScheduler(s.initialSchedulerCfg.DeepCopy(), resetComment); err != nil {
+		return err
+	}
+	for _, as := range s.additionalSchedulers {
+		if err := as.restart(as.initialCfg.DeepCopy(), resetComment); err != nil {
+			return xerrors.Errorf("reset scheduler %s: %w", as.name, err)
+		}
+	}
+	return nil
 }
 
+// ShutdownScheduler stops all schedulers run by the simulator.
 func (s *Service) ShutdownScheduler() {
-	if s.shutdownfn != nil {
-		klog.Info("shutdown scheduler...")
-		s.shutdownfn()
+	s.runtime.Shutdown()
+	for _, as := range s.additionalSchedulers {
+		as.runtime.Shutdown()
 	}
 }
 
@@ -129,6 +331,132 @@ func (s *Service) SetSchedulerConfig(cfg *configv1.KubeSchedulerConfiguration) {
 	s.currentSchedulerCfg = cfg.DeepCopy()
 }
 
+// GetNamedSchedulerConfig returns the current config of the scheduler with the name.
+func (s *Service) GetNamedSchedulerConfig(name string) (*configv1.KubeSchedulerConfiguration, error) {
+	if name == simulatorconfig.DefaultSchedulerName {
+		return s.GetSchedulerConfig()
+	}
+	as := s.additionalScheduler(name)
+	if as == nil {
+		return nil, xerrors.Errorf("get scheduler config of %s: %w", name, ErrSchedulerNotFound)
+	}
+	return as.currentCfg, nil
+}
+
+// GetSchedulingQueue returns the snapshot of the scheduling queue of the scheduler with the name.
+func (s *Service) GetSchedulingQueue(ctx context.Context, name string) (*queue.Snapshot, error) {
+	rt := s.runtime
+	if name != simulatorconfig.DefaultSchedulerName {
+		as := s.additionalScheduler(name)
+		if as == nil {
+			return nil, xerrors.Errorf("get scheduling queue of %s: %w", name, ErrSchedulerNotFound)
+		}
+		rt = as.runtime
+	}
+	snapshot, err := rt.SchedulingQueue(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get scheduling queue of %s: %w", name, err)
+	}
+	return snapshot, nil
+}
+
+// ListExtenderCalls returns the calls to the extenders of the scheduler with the name for the Pod.
+func (s *Service) ListExtenderCalls(ctx context.Context, name, namespace, podName string) ([]extender.Call, error) {
+	rt := s.runtime
+	if name != simulatorconfig.DefaultSchedulerName {
+		as := s.additionalScheduler(name)
+		if as == nil {
+			return nil, xerrors.Errorf("list extender calls of %s: %w", name, ErrSchedulerNotFound)
+		}
+		rt = as.runtime
+	}
+	calls, err := rt.ExtenderCalls(ctx, namespace, podName)
+	if err != nil {
+		return nil, xerrors.Errorf("list extender calls of %s: %w", name, err)
+	}
+	return calls, nil
+}
+
+// Debugger returns the Debugger to manage the breakpoints of the scheduler with the name.
+func (s *Service) Debugger(name string) (breakpoint.Debugger, error) {
+	if name == simulatorconfig.DefaultSchedulerName {
+		return s.runtime.Debugger(), nil
+	}
+	as := s.additionalScheduler(name)
+	if as == nil {
+		return nil, xerrors.Errorf("get debugger of %s: %w", name, ErrSchedulerNotFound)
+	}
+	return as.runtime.Debugger(), nil
+}
+
+// ListSchedulers returns the default scheduler followed by the additional schedulers.
+func (s *Service) ListSchedulers() []Scheduler {
+	schedulers := make([]Scheduler, 0, len(s.additionalSchedulers)+1)
+	defaultScheduler := Scheduler{Name: simulatorconfig.DefaultSchedulerName, SchedulerNames: []string{}}
+	if r, ok := s.runtime.(*dockerRuntime); ok {
+		defaultScheduler.ContainerName = r.containerName
+	}
+	if s.currentSchedulerCfg != nil {
+		defaultScheduler.SchedulerNames = simulatorschedconfig.ProfileSchedulerNames(s.currentSchedulerCfg)
+	}
+	schedulers = append(schedulers, defaultScheduler)
+	for _, as := range s.additionalSchedulers {
+		schedulers = append(schedulers, Scheduler{
+			Name:           as.name,
+			ContainerName:  as.containerName,
+			SchedulerNames: simulatorschedconfig.ProfileSchedulerNames(as.currentCfg),
+		})
+	}
+	return schedulers
+}
+
+func (s *Service) additionalScheduler(name string) *additionalScheduler {
+	for _, as := range s.additionalSchedulers {
+		if as.name == name {
+			return as
+		}
+	}
+	return nil
+}
+
+// checkSchedulerNames checks that cfg for the scheduler with the name doesn't have a schedulerName used by the other schedulers.
+// Otherwise, multiple schedulers would try to schedule the same Pods.
+func (s *Service) checkSchedulerNames(name string, cfg *configv1.KubeSchedulerConfiguration) error {
+	used := map[string]string{}
+	for _, sched := range s.ListSchedulers() {
+		if sched.Name == name {
+			continue
+		}
+		for _, n := range sched.SchedulerNames {
+			used[n] = sched.Name
+		}
+	}
+	for _, n := range simulatorschedconfig.ProfileSchedulerNames(cfg) {
+		if owner, ok := used[n]; ok {
+			return xerrors.Errorf("schedulerName %q is used by scheduler %s: %w", n, owner, ErrSchedulerNameConflict)
+		}
+	}
+	return nil
+}
+
+// NamedExtenderService returns the ExtenderService serving the requests for the extenders of the scheduler with the name.
+// It returns ErrExtenderCallsNotRecorded when the extenders of the scheduler aren't proxied by the simulator server.
+func (s *Service) NamedExtenderService(name string) (ExtenderService, error) {
+	rt := s.runtime
+	if name != simulatorconfig.DefaultSchedulerName {
+		as := s.additionalScheduler(name)
+		if as == nil {
+			return nil, xerrors.Errorf("get extender service of %s: %w", name, ErrSchedulerNotFound)
+		}
+		rt = as.runtime
+	}
+	es, ok := rt.(ExtenderService)
+	if !ok {
+		return nil, xerrors.Errorf("get extender service of %s: %w", name, ErrExtenderCallsNotRecorded)
+	}
+	return es, nil
+}
+
 // ExtenderService returns ExtenderService interface.
 func (s *Service) ExtenderService() ExtenderService {
 	return s.extenderService
@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"sort"
 	"testing"
 
@@ -11,7 +13,12 @@ import (
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	"k8s.io/utils/ptr"
 
+	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/config/v1alpha1"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
 	schedConfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 )
 
 var (
@@ -516,6 +523,213 @@ func Test_convertConfigurationForSimulator(t *testing.T) {
 	}
 }
 
+func TestService_checkSchedulerNames(t *testing.T) {
+	t.Parallel()
+
+	profiles := func(names ...string) *configv1.KubeSchedulerConfiguration {
+		cfg := &configv1.KubeSchedulerConfiguration{}
+		for _, n := range names {
+			cfg.Profiles = append(cfg.Profiles, configv1.KubeSchedulerProfile{SchedulerName: ptr.To(n)})
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name          string
+		schedulerName string
+		cfg           *configv1.KubeSchedulerConfiguration
+		wantErr       bool
+	}{
+		{
+			name:          "the default scheduler can keep its own schedulerName",
+			schedulerName: "default",
+			cfg:           profiles(v1.DefaultSchedulerName, "another-scheduler"),
+		},
+		{
+			name:          "the default scheduler cannot take the schedulerName of the batch scheduler",
+			schedulerName: "default",
+			cfg:           profiles(v1.DefaultSchedulerName, "batch-scheduler"),
+			wantErr:       true,
+		},
+		{
+			name:          "a config without profiles uses default-scheduler",
+			schedulerName: "batch",
+			cfg:           &configv1.KubeSchedulerConfiguration{},
+			wantErr:       true,
+		},
+		{
+			name:          "the batch scheduler can rename its profile",
+			schedulerName: "batch",
+			cfg:           profiles("batch-scheduler-2"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewSchedulerService(nil, nil, profiles(v1.DefaultSchedulerName), Options{
+				AdditionalSchedulers: []simulatorconfig.AdditionalSchedulerConfig{
+					{Name: "batch", ContainerName: "simulator-batch-scheduler", InitialSchedulerCfg: profiles("batch-scheduler")},
+				},
+				SchedulerRuntime: simulatorconfig.DockerSchedulerRuntime,
+				SimulatorPort:    1212,
+			})
+			s.SetSchedulerConfig(profiles(v1.DefaultSchedulerName))
+
+			err := s.checkSchedulerNames(tt.schedulerName, tt.cfg)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSchedulerNameConflict)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// fakeRuntime records the configs the scheduler is restarted with.
+// It fails to restart with failOn, if any.
+type fakeRuntime struct {
+	restarted []*configv1.KubeSchedulerConfiguration
+	failOn    *configv1.KubeSchedulerConfiguration
+}
+
+func (r *fakeRuntime) Start(_ *configv1.KubeSchedulerConfiguration) error { return nil }
+
+func (r *fakeRuntime) Restart(cfg *configv1.KubeSchedulerConfiguration) error {
+	if r.failOn != nil && assert.ObjectsAreEqual(r.failOn, cfg) {
+		return errors.New("failed to start")
+	}
+	r.restarted = append(r.restarted, cfg.DeepCopy())
+	return nil
+}
+
+func (r *fakeRuntime) Shutdown() {}
+
+func (r *fakeRuntime) SchedulingQueue(_ context.Context) (*queue.Snapshot, error) { return nil, nil }
+
+func (r *fakeRuntime) ExtenderCalls(_ context.Context, _, _ string) ([]extender.Call, error) {
+	return nil, nil
+}
+
+func (r *fakeRuntime) Debugger() breakpoint.Debugger { return nil }
+
+func TestService_RollbackScheduler(t *testing.T) {
+	t.Parallel()
+
+	cfgWithWeight := func(weight int32) *configv1.KubeSchedulerConfiguration {
+		return &configv1.KubeSchedulerConfiguration{
+			Profiles: []configv1.KubeSchedulerProfile{{
+				SchedulerName: ptr.To(v1.DefaultSchedulerName),
+				Plugins: &configv1.Plugins{
+					Score: configv1.PluginSet{Enabled: []configv1.Plugin{{Name: "NodeResourcesFit", Weight: ptr.To(weight)}}},
+				},
+			}},
+		}
+	}
+
+	rt := &fakeRuntime{}
+	s := NewSchedulerService(nil, nil, cfgWithWeight(1), Options{SchedulerRuntime: simulatorconfig.DockerSchedulerRuntime, SimulatorPort: 1212})
+	s.runtime = rt
+	s.SetSchedulerConfig(cfgWithWeight(1))
+
+	assert.NoError(t, s.RestartNamedSchedulerWithComment(simulatorconfig.DefaultSchedulerName, cfgWithWeight(2), "try weight 2"))
+	assert.NoError(t, s.RestartScheduler(cfgWithWeight(3)))
+
+	versions, err := s.ListSchedulerConfigVersions(simulatorconfig.DefaultSchedulerName)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, "try weight 2", versions[1].Comment)
+	assert.Nil(t, versions[1].Config)
+
+	changes, err := s.DiffSchedulerConfigVersions(simulatorconfig.DefaultSchedulerName, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []schedConfig.Change{{
+		Path: "profiles[0].plugins.score.enabled[0].weight",
+		Type: schedConfig.ChangeTypeChanged,
+		From: float64(1),
+		To:   float64(3),
+	}}, changes)
+
+	assert.NoError(t, s.RollbackScheduler(simulatorconfig.DefaultSchedulerName, 2))
+	assert.Equal(t, cfgWithWeight(2), rt.restarted[len(rt.restarted)-1])
+	current, err := s.GetSchedulerConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, cfgWithWeight(2), current)
+
+	// The rollback is recorded as a new version.
+	latest, err := s.GetSchedulerConfigVersion(simulatorconfig.DefaultSchedulerName, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "rollback to version 2", latest.Comment)
+	assert.Equal(t, cfgWithWeight(2), latest.Config)
+
+	_, err = s.GetSchedulerConfigVersion(simulatorconfig.DefaultSchedulerName, 5)
+	assert.ErrorIs(t, err, ErrConfigVersionNotFound)
+	assert.ErrorIs(t, s.RollbackScheduler("unknown", 1), ErrSchedulerNotFound)
+}
+
+func TestService_RestartNamedSchedulerWithComment_rollback(t *testing.T) {
+	t.Parallel()
+
+	cfgWithWeight := func(name string, weight int32) *configv1.KubeSchedulerConfiguration {
+		return &configv1.KubeSchedulerConfiguration{
+			Profiles: []configv1.KubeSchedulerProfile{{
+				SchedulerName: ptr.To(name),
+				Plugins: &configv1.Plugins{
+					Score: configv1.PluginSet{Enabled: []configv1.Plugin{{Name: "NodeResourcesFit", Weight: ptr.To(weight)}}},
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		schedulerName string
+		profileName   string
+	}{
+		{
+			name:          "the default scheduler",
+			schedulerName: simulatorconfig.DefaultSchedulerName,
+			profileName:   v1.DefaultSchedulerName,
+		},
+		{
+			name:          "an additional scheduler",
+			schedulerName: "second",
+			profileName:   "second-scheduler",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewSchedulerService(nil, nil, cfgWithWeight(v1.DefaultSchedulerName, 1), Options{
+				AdditionalSchedulers: []simulatorconfig.AdditionalSchedulerConfig{{
+					Name:                "second",
+					InitialSchedulerCfg: cfgWithWeight("second-scheduler", 1),
+				}},
+				SchedulerRuntime: simulatorconfig.DockerSchedulerRuntime,
+				SimulatorPort:    1212,
+			})
+			rt := &fakeRuntime{failOn: cfgWithWeight(tt.profileName, 2)}
+			s.runtime = rt
+			s.additionalSchedulers[0].runtime = rt
+			assert.NoError(t, s.RestartNamedScheduler(tt.schedulerName, cfgWithWeight(tt.profileName, 3)))
+
+			err := s.RestartNamedSchedulerWithComment(tt.schedulerName, cfgWithWeight(tt.profileName, 2), "broken")
+			assert.ErrorIs(t, err, ErrRolledBack)
+			// The scheduler runs with the old config again.
+			assert.Equal(t, cfgWithWeight(tt.profileName, 3), rt.restarted[len(rt.restarted)-1])
+			current, err := s.GetNamedSchedulerConfig(tt.schedulerName)
+			assert.NoError(t, err)
+			assert.Equal(t, cfgWithWeight(tt.profileName, 3), current)
+			// The config that failed to start isn't recorded.
+			versions, err := s.ListSchedulerConfigVersions(tt.schedulerName)
+			assert.NoError(t, err)
+			assert.Len(t, versions, 2)
+		})
+	}
+}
+
 func configGeneratedFromDefault() configv1.KubeSchedulerConfiguration {
 	versioned, _ := schedConfig.DefaultSchedulerConfig()
 	cfg := versioned.DeepCopy()
@@ -583,3 +797,71 @@ func configGeneratedFromDefault() configv1.KubeSchedulerConfiguration {
 
 	return *cfg
 }
+
+func TestService_ReloadWasmPlugin_Errors(t *testing.T) {
+	t.Parallel()
+
+	cfg := &configv1.KubeSchedulerConfiguration{
+		Profiles: []configv1.KubeSchedulerProfile{{
+			SchedulerName: ptr.To(v1.DefaultSchedulerName),
+			Plugins: &configv1.Plugins{
+				MultiPoint: configv1.PluginSet{Enabled: []configv1.Plugin{{Name: "NodeResourcesFit"}}},
+			},
+		}},
+	}
+	rt := &fakeRuntime{}
+	s := NewSchedulerService(nil, nil, cfg, Options{SchedulerRuntime: simulatorconfig.DockerSchedulerRuntime, SimulatorPort: 1212})
+	s.runtime = rt
+	s.SetSchedulerConfig(cfg)
+
+	_, err := s.ReloadWasmPlugin(context.Background(), simulatorconfig.DefaultSchedulerName, "NodeNumber", "file:///plugin.wasm")
+	assert.ErrorIs(t, err, ErrWasmPluginNotFound)
+	_, err = s.ReloadWasmPlugin(context.Background(), "unknown", "NodeNumber", "file:///plugin.wasm")
+	assert.ErrorIs(t, err, ErrSchedulerNotFound)
+	assert.Empty(t, rt.restarted)
+
+	_, err = s.StoreWasmModule(simulatorconfig.DefaultSchedulerName, "NodeNumber", []byte("module"))
+	assert.ErrorIs(t, err, ErrWasmUploadNotSupported)
+	_, err = s.StoreWasmModule("unknown", "NodeNumber", []byte("module"))
+	assert.ErrorIs(t, err, ErrSchedulerNotFound)
+}
+
+func TestService_NamedExtenderService(t *testing.T) {
+	t.Parallel()
+	profile := func(name string) *configv1.KubeSchedulerConfiguration {
+		return &configv1.KubeSchedulerConfiguration{Profiles: []configv1.KubeSchedulerProfile{{SchedulerName: ptr.To(name)}}}
+	}
+	additional := []simulatorconfig.AdditionalSchedulerConfig{{Name: "batch", InitialSchedulerCfg: profile("batch-scheduler")}}
+	extenderOpts := []extender.Option{
+		extender.WithMockExtenders([]v1alpha1.MockExtender{{URLPrefix: "http://extender/"}}),
+		extender.WithExtenderFaults([]v1alpha1.ExtenderFault{{Type: v1alpha1.ExtenderFaultServerError, Rate: 1}}),
+	}
+
+	s := NewSchedulerService(nil, nil, profile(v1.DefaultSchedulerName), Options{
+		AdditionalSchedulers: additional,
+		SchedulerRuntime:     simulatorconfig.InProcessSchedulerRuntime,
+		ExtenderOpts:         extenderOpts,
+		SimulatorPort:        1212,
+	})
+	for _, name := range []string{simulatorconfig.DefaultSchedulerName, "batch"} {
+		_, err := s.NamedExtenderService(name)
+		assert.NoError(t, err, name)
+	}
+	// The additional scheduler proxies its extenders with the same options as the default scheduler.
+	rt := s.additionalSchedulers[0].runtime.(*inProcessRuntime)
+	assert.Equal(t, 1212, rt.simulatorPort)
+	assert.Equal(t, "batch", rt.schedulerName)
+	assert.Len(t, rt.extenderOpts, len(extenderOpts))
+	assert.NotNil(t, rt.extenderCalls)
+
+	_, err := s.NamedExtenderService("unknown")
+	assert.ErrorIs(t, err, ErrSchedulerNotFound)
+
+	s = NewSchedulerService(nil, nil, profile(v1.DefaultSchedulerName), Options{
+		AdditionalSchedulers: additional,
+		SchedulerRuntime:     simulatorconfig.DockerSchedulerRuntime,
+		SimulatorPort:        1212,
+	})
+	_, err = s.NamedExtenderService("batch")
+	assert.ErrorIs(t, err, ErrExtenderCallsNotRecorded)
+}
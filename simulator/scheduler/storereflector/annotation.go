@@ -2,3 +2,10 @@ package storereflector
 
 // ResultsHistoryAnnotation has the all results including the past ones.
 const ResultsHistoryAnnotation = "kube-scheduler-simulator.sigs.k8s.io/result-history"
+
+// ConfigFingerprintAnnotation has the fingerprint of the scheduler configuration which produced the results.
+// See config.Fingerprint in the scheduler/config package.
+const ConfigFingerprintAnnotation = "kube-scheduler-simulator.sigs.k8s.io/config-fingerprint"
+
+// ProfileAnnotation has the name of the scheduler profile which produced the results.
+const ProfileAnnotation = "kube-scheduler-simulator.sigs.k8s.io/profile"
@@ -34,16 +34,40 @@ type ResultStore interface {
 	DeleteData(key corev1.Pod)
 }
 
+// PodDeleter is implemented by the ResultStores which keep some data of the Pod after DeleteData,
+// e.g., while the Pod is waiting in the Permit phase. DeletePod is called when the Pod is deleted
+// so that the data doesn't remain in the store.
+type PodDeleter interface {
+	DeletePod(pod corev1.Pod)
+}
+
 // store manages any ResultStore.
 // ResultStore stores any result that should be reflected to the Pod.
 type reflector struct {
 	resultStores map[string]ResultStore
+	// configFingerprint is recorded along with the results if it's not empty.
+	configFingerprint string
+}
+
+// Option configures the Reflector.
+type Option func(*reflector)
+
+// WithConfigFingerprint makes the Reflector tag the results with the fingerprint of the scheduler configuration
+// so that the results produced by different configurations can be told apart.
+func WithConfigFingerprint(fingerprint string) Option {
+	return func(r *reflector) {
+		r.configFingerprint = fingerprint
+	}
 }
 
-func New() Reflector {
-	return &reflector{
+func New(opts ...Option) Reflector {
+	r := &reflector{
 		resultStores: map[string]ResultStore{},
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // AddResultStore adds the ResultStore to the map.
@@ -60,6 +84,7 @@ func (s *reflector) ResisterResultSavingToInformer(client clientset.Interface, s
 	_, err := informerFactory.Core().V1().Pods().Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			UpdateFunc: s.storeAllResultToPodFunc(client),
+			DeleteFunc: s.deletePodFunc,
 		},
 	)
 	if err != nil {
@@ -116,6 +141,7 @@ func (s *reflector) storeAllResultToPodFunc(client clientset.Interface) func(int
 				// no need to update anything on the Pod.
 				return true, nil
 			}
+			s.tagResults(pod, resultSet)
 
 			if err := updateResultHistory(pod, resultSet); err != nil {
 				klog.ErrorS(err, "cannot update "+ResultsHistoryAnnotation, "pod", klog.KObj(pod))
@@ -146,6 +172,42 @@ func (s *reflector) storeAllResultToPodFunc(client clientset.Interface) func(int
 	}
 }
 
+// deletePodFunc drops the data the ResultStores keep for the deleted Pod.
+// It will be used as the event handler of resource deleting.
+func (s *reflector) deletePodFunc(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	for k := range s.resultStores {
+		if d, ok := s.resultStores[k].(PodDeleter); ok {
+			d.DeletePod(*pod)
+		}
+	}
+}
+
+// tagResults records which configuration and profile produced the results
+// both on the Pod annotation and in the results to be added to the history.
+// The profile recorded by the ResultStore is preferred to the schedulerName of the Pod.
+func (s *reflector) tagResults(pod *corev1.Pod, resultSet map[string]string) {
+	profile := resultSet[ProfileAnnotation]
+	if profile == "" {
+		profile = pod.Spec.SchedulerName
+	}
+	if profile == "" {
+		profile = corev1.DefaultSchedulerName
+	}
+	resultSet[ProfileAnnotation] = profile
+	metav1.SetMetaDataAnnotation(&pod.ObjectMeta, ProfileAnnotation, profile)
+	if s.configFingerprint != "" {
+		resultSet[ConfigFingerprintAnnotation] = s.configFingerprint
+		metav1.SetMetaDataAnnotation(&pod.ObjectMeta, ConfigFingerprintAnnotation, s.configFingerprint)
+	}
+}
+
 func updateResultHistory(p *corev1.Pod, m map[string]string) error {
 	a, ok := p.GetAnnotations()[ResultsHistoryAnnotation]
 	if !ok {
@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/storereflector/mock_storereflector"
 )
@@ -29,6 +30,7 @@ func TestReflector_storeAllResultToPodFunc(t *testing.T) {
 		podNamespace                string
 		prepareMockResultStoreSetFn func(m *mock_storereflector.MockResultStore)
 		prepareFakeClientSetFn      func() *fake.Clientset
+		configFingerprint           string
 		wantAnnotation              map[string]string
 	}{
 		{
@@ -49,7 +51,38 @@ func TestReflector_storeAllResultToPodFunc(t *testing.T) {
 				}, metav1.CreateOptions{})
 				return c
 			},
-			wantAnnotation: map[string]string{ExtenderFilterResultAnnotationKey: "some results", ResultsHistoryAnnotation: "[{\"kube-scheduler-simulator.sigs.k8s.io/extender-filter-result\":\"some results\"}]"},
+			wantAnnotation: map[string]string{
+				ExtenderFilterResultAnnotationKey: "some results",
+				ProfileAnnotation:                 "default-scheduler",
+				ResultsHistoryAnnotation:          "[{\"kube-scheduler-simulator.sigs.k8s.io/extender-filter-result\":\"some results\",\"kube-scheduler-simulator.sigs.k8s.io/profile\":\"default-scheduler\"}]",
+			},
+		},
+		{
+			name:         "success: results are tagged with the config fingerprint and the profile",
+			podName:      "pod1",
+			podNamespace: "default",
+			prepareMockResultStoreSetFn: func(m *mock_storereflector.MockResultStore) {
+				m.EXPECT().GetStoredResult(gomock.Any()).Return(map[string]string{ExtenderFilterResultAnnotationKey: "some results"})
+				m.EXPECT().DeleteData(gomock.Any())
+			},
+			prepareFakeClientSetFn: func() *fake.Clientset {
+				c := fake.NewSimpleClientset()
+				c.CoreV1().Pods("default").Create(context.Background(), &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod1",
+						Namespace: "default",
+					},
+					Spec: corev1.PodSpec{SchedulerName: "batch-scheduler"},
+				}, metav1.CreateOptions{})
+				return c
+			},
+			configFingerprint: "0123456789ab",
+			wantAnnotation: map[string]string{
+				ExtenderFilterResultAnnotationKey: "some results",
+				ProfileAnnotation:                 "batch-scheduler",
+				ConfigFingerprintAnnotation:       "0123456789ab",
+				ResultsHistoryAnnotation:          "[{\"kube-scheduler-simulator.sigs.k8s.io/config-fingerprint\":\"0123456789ab\",\"kube-scheduler-simulator.sigs.k8s.io/extender-filter-result\":\"some results\",\"kube-scheduler-simulator.sigs.k8s.io/profile\":\"batch-scheduler\"}]",
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -61,7 +94,8 @@ func TestReflector_storeAllResultToPodFunc(t *testing.T) {
 			rs := mock_storereflector.NewMockResultStore(ctrl)
 			tt.prepareMockResultStoreSetFn(rs)
 			r := &reflector{
-				resultStores: map[string]ResultStore{ResultStoreKey: rs},
+				resultStores:      map[string]ResultStore{ResultStoreKey: rs},
+				configFingerprint: tt.configFingerprint,
 			}
 			fn := r.storeAllResultToPodFunc(c)
 			p, _ := c.CoreV1().Pods(tt.podNamespace).Get(context.Background(), tt.podName, metav1.GetOptions{})
@@ -79,6 +113,57 @@ func TestReflector_storeAllResultToPodFunc(t *testing.T) {
 	}
 }
 
+// fakePodDeleter records the Pods passed to DeletePod.
+type fakePodDeleter struct {
+	*mock_storereflector.MockResultStore
+	deleted []string
+}
+
+func (f *fakePodDeleter) DeletePod(pod corev1.Pod) {
+	f.deleted = append(f.deleted, pod.Namespace+"/"+pod.Name)
+}
+
+func TestReflector_deletePodFunc(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		{
+			name: "deleted Pod",
+			obj:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
+			want: []string{"default/pod1"},
+		},
+		{
+			name: "tombstone of deleted Pod",
+			obj:  cache.DeletedFinalStateUnknown{Key: "default/pod1", Obj: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}},
+			want: []string{"default/pod1"},
+		},
+		{
+			name: "not a Pod",
+			obj:  &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			deleter := &fakePodDeleter{MockResultStore: mock_storereflector.NewMockResultStore(ctrl)}
+			// The stores which don't implement PodDeleter are left as they are.
+			other := mock_storereflector.NewMockResultStore(ctrl)
+
+			s := New()
+			s.AddResultStore(deleter, "deleter")
+			s.AddResultStore(other, "other")
+			s.(*reflector).deletePodFunc(tt.obj)
+
+			assert.Equal(t, tt.want, deleter.deleted)
+		})
+	}
+}
+
 func Test_updateResultHistory(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
+	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+)
+
+var (
+	// ErrWasmPluginNotFound is returned when the scheduler configuration doesn't have the wasm plugin with the given name.
+	ErrWasmPluginNotFound = errors.New("wasm plugin not found in the scheduler configuration")
+	// ErrWasmPluginLoad is returned when the wasm module can't be loaded as the plugin.
+	ErrWasmPluginLoad = errors.New("failed to load the wasm plugin")
+	// ErrWasmUploadNotSupported is returned when the wasm module is uploaded for the scheduler in a container,
+	// which can't read the files of the simulator.
+	ErrWasmUploadNotSupported = errors.New("wasm modules can be uploaded only for the schedulers in the simulator process")
+)
+
+// defaultWasmModuleDir is the directory the uploaded wasm modules are stored in.
+var defaultWasmModuleDir = filepath.Join(os.TempDir(), "kube-scheduler-simulator", "wasm")
+
+// ReloadWasmPlugin restarts the scheduler with the name with the wasm plugin loaded from guestURL,
+// after checking that the guest can be loaded. guestURL is applied to all profiles having the plugin.
+// It returns the new version of the configuration, and the previous one can be restored by RollbackScheduler.
+func (s *Service) ReloadWasmPlugin(ctx context.Context, name, pluginName, guestURL string) (*ConfigVersion, error) {
+	cfg, err := s.GetNamedSchedulerConfig(name)
+	if err != nil {
+		return nil, xerrors.Errorf("reload wasm plugin: %w", err)
+	}
+	if cfg == nil {
+		// The default scheduler has no current config until it's restarted.
+		cfg = s.initialSchedulerCfg
+	}
+	cfg = cfg.DeepCopy()
+
+	wasmCfgs, err := simulatorschedconfig.SetWasmGuestURL(cfg, pluginName, guestURL)
+	if err != nil {
+		return nil, xerrors.Errorf("reload wasm plugin: %w", err)
+	}
+	if len(wasmCfgs) == 0 {
+		return nil, xerrors.Errorf("reload wasm plugin %s of scheduler %s: %w", pluginName, name, ErrWasmPluginNotFound)
+	}
+	for _, wasmCfg := range wasmCfgs {
+		if err := simulatorschedconfig.LoadWasmPlugin(ctx, pluginName, wasmCfg); err != nil {
+			return nil, xerrors.Errorf("%w: %v", ErrWasmPluginLoad, err)
+		}
+	}
+
+	if err := s.RestartNamedSchedulerWithComment(name, cfg, fmt.Sprintf("reload wasm plugin %s from %s", pluginName, guestURL)); err != nil {
+		return nil, xerrors.Errorf("reload wasm plugin: %w", err)
+	}
+	h, err := s.configHistory(name)
+	if err != nil {
+		return nil, xerrors.Errorf("reload wasm plugin: %w", err)
+	}
+	versions := h.list()
+	return &versions[len(versions)-1], nil
+}
+
+// StoreWasmModule stores the uploaded wasm module for the plugin of the scheduler with the name,
+// and returns the guestURL to load it.
+// The modules are named after their digest and kept as they are, so that the previous versions can be rolled back to.
+func (s *Service) StoreWasmModule(name, pluginName string, module []byte) (string, error) {
+	var rt Runtime
+	if name == simulatorconfig.DefaultSchedulerName {
+		rt = s.runtime
+	} else if as := s.additionalScheduler(name); as != nil {
+		rt = as.runtime
+	} else {
+		return "", xerrors.Errorf("store wasm module for %s: %w", name, ErrSchedulerNotFound)
+	}
+	if _, ok := rt.(*inProcessRuntime); !ok {
+		return "", xerrors.Errorf("store wasm module for %s: %w", name, ErrWasmUploadNotSupported)
+	}
+
+	dir := s.wasmModuleDir
+	if dir == "" {
+		dir = defaultWasmModuleDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", xerrors.Errorf("create directory for wasm modules: %w", err)
+	}
+	digest := sha256.Sum256(module)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.wasm", filepath.Base(pluginName), hex.EncodeToString(digest[:8])))
+	if err := os.WriteFile(path, module, 0o600); err != nil {
+		return "", xerrors.Errorf("write wasm module: %w", err)
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String(), nil
+}
@@ -12,11 +12,19 @@ import (
 	restclient "k8s.io/client-go/rest"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/capacity"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/checkpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/comparison"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/gang"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/oneshotimporter"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/optimizer"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/replayer"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/rescore"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/reset"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/resourceapplier"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/resourcewatcher"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/results"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/snapshot"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/syncer"
@@ -27,6 +35,14 @@ type Container struct {
 	schedulerService               SchedulerService
 	snapshotService                SnapshotService
 	resetService                   ResetService
+	checkpointService              CheckpointService
+	dryRunService                  DryRunService
+	capacityService                CapacityService
+	comparisonService              ComparisonService
+	resultService                  ResultService
+	rescoreService                 RescoreService
+	optimizerService               OptimizerService
+	gangService                    GangService
 	oneshotClusterResourceImporter OneShotClusterResourceImporter
 	resourceSyncer                 ResourceSyncer
 	resourceWatcherService         ResourceWatcherService
@@ -47,19 +63,28 @@ func NewDIContainer(
 	resourceSyncEnabled bool,
 	replayEnabled bool,
 	externalDynamicClient dynamic.Interface,
-	simulatorPort int,
+	schedulerOptions scheduler.Options,
 	resourceapplierOptions resourceapplier.Options,
 	replayerOptions replayer.Options,
 ) (*Container, error) {
 	c := &Container{}
 
 	// initializes each service
-	c.schedulerService = scheduler.NewSchedulerService(client, restclientCfg, initialSchedulerCfg, simulatorPort)
+	c.schedulerService = scheduler.NewSchedulerService(client, restclientCfg, initialSchedulerCfg, schedulerOptions)
 	var err error
 	c.resetService, err = reset.NewResetService(etcdclient, client, c.schedulerService)
 	if err != nil {
 		return nil, xerrors.Errorf("initialize reset service: %w", err)
 	}
+	c.checkpointService = checkpoint.NewCheckpointService(etcdclient, c.schedulerService)
+	dryRunSvc := dryrun.NewService(client, c.schedulerService)
+	c.dryRunService = dryRunSvc
+	c.capacityService = capacity.NewService(dryRunSvc)
+	c.comparisonService = comparison.NewService(dryRunSvc, c.schedulerService)
+	c.resultService = results.NewService(client, c.schedulerService)
+	c.rescoreService = rescore.NewService(client)
+	c.optimizerService = optimizer.NewService(client, c.schedulerService)
+	c.gangService = gang.NewService(client)
 	snapshotSvc := snapshot.NewService(client, c.schedulerService)
 	c.snapshotService = snapshotSvc
 	resourceApplierService := resourceapplier.New(dynamicClient, restMapper, resourceapplierOptions)
@@ -92,6 +117,26 @@ func (c *Container) ResetService() ResetService {
 	return c.resetService
 }
 
+// CheckpointService returns CheckpointService.
+func (c *Container) CheckpointService() CheckpointService {
+	return c.checkpointService
+}
+
+// DryRunService returns DryRunService.
+func (c *Container) DryRunService() DryRunService {
+	return c.dryRunService
+}
+
+// CapacityService returns CapacityService.
+func (c *Container) CapacityService() CapacityService {
+	return c.capacityService
+}
+
+// ComparisonService returns ComparisonService.
+func (c *Container) ComparisonService() ComparisonService {
+	return c.comparisonService
+}
+
 // OneshotClusterResourceImporter returns OneshotClusterResourceImporter.
 // Note: this service will return nil when `externalImportEnabled` is false.
 func (c *Container) OneshotClusterResourceImporter() OneShotClusterResourceImporter {
@@ -103,6 +148,26 @@ func (c *Container) ResourceSyncer() ResourceSyncer {
 	return c.resourceSyncer
 }
 
+// ResultService returns ResultService.
+func (c *Container) ResultService() ResultService {
+	return c.resultService
+}
+
+// RescoreService returns RescoreService.
+func (c *Container) RescoreService() RescoreService {
+	return c.rescoreService
+}
+
+// OptimizerService returns OptimizerService.
+func (c *Container) OptimizerService() OptimizerService {
+	return c.optimizerService
+}
+
+// GangService returns GangService.
+func (c *Container) GangService() GangService {
+	return c.gangService
+}
+
 // ReplayService returns ReplayService.
 func (c *Container) ReplayService() ReplayService {
 	return c.replayService
@@ -113,6 +178,11 @@ func (c *Container) ResourceWatcherService() ResourceWatcherService {
 	return c.resourceWatcherService
 }
 
+// BreakpointService returns BreakpointService.
+func (c *Container) BreakpointService() BreakpointService {
+	return c.schedulerService
+}
+
 // ExtenderService returns ExtenderService.
 func (c *Container) ExtenderService() ExtenderService {
 	return c.schedulerService.ExtenderService()
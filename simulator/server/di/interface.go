@@ -3,13 +3,26 @@ package di
 import (
 	"context"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/capacity"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/checkpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/comparison"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/dryrun"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/gang"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/optimizer"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/rescore"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/resourcewatcher"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/resourcewatcher/streamwriter"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/results"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/snapshot"
 )
 
@@ -19,8 +32,23 @@ type SchedulerService interface {
 	SetSchedulerConfig(cfg *configv1.KubeSchedulerConfiguration)
 	RestartScheduler(cfg *configv1.KubeSchedulerConfiguration) error
 	ResetScheduler() error
+	ListSchedulers() []scheduler.Scheduler
+	GetNamedSchedulerConfig(name string) (*configv1.KubeSchedulerConfiguration, error)
+	RestartNamedScheduler(name string, cfg *configv1.KubeSchedulerConfiguration) error
+	RestartNamedSchedulerWithComment(name string, cfg *configv1.KubeSchedulerConfiguration, comment string) error
+	ListSchedulerConfigVersions(name string) ([]scheduler.ConfigVersion, error)
+	GetSchedulerConfigVersion(name string, version int) (*scheduler.ConfigVersion, error)
+	DiffSchedulerConfigVersions(name string, from, to int) ([]simulatorschedconfig.Change, error)
+	RollbackScheduler(name string, version int) error
+	ReloadWasmPlugin(ctx context.Context, name, pluginName, guestURL string) (*scheduler.ConfigVersion, error)
+	StoreWasmModule(name, pluginName string, module []byte) (string, error)
+	GetSchedulingQueue(ctx context.Context, name string) (*queue.Snapshot, error)
+	ListExtenderCalls(ctx context.Context, name, namespace, podName string) ([]extender.Call, error)
+	Debugger(name string) (breakpoint.Debugger, error)
+	StartScheduler() error
 	ShutdownScheduler()
 	ExtenderService() scheduler.ExtenderService
+	NamedExtenderService(name string) (scheduler.ExtenderService, error)
 }
 
 // SnapshotService represents a service for exporting/importing resources on the simulator.
@@ -34,6 +62,49 @@ type ResetService interface {
 	Reset(ctx context.Context) error
 }
 
+// CheckpointService represents a service to save the simulator state as named checkpoints and restore them.
+type CheckpointService interface {
+	Save(ctx context.Context, name string) (*checkpoint.Checkpoint, error)
+	List() []checkpoint.Checkpoint
+	Restore(ctx context.Context, name string) error
+}
+
+// DryRunService represents a service to evaluate where Pods would be placed without creating them.
+type DryRunService interface {
+	Schedule(ctx context.Context, pods []v1.Pod) ([]dryrun.Result, error)
+}
+
+// CapacityService represents a service to estimate how many replicas of a Pod fit in the cluster.
+type CapacityService interface {
+	Estimate(ctx context.Context, template *v1.Pod, maxReplicas int) (*capacity.Estimation, error)
+}
+
+// ComparisonService represents a service to compare two scheduler configurations on the same workload.
+type ComparisonService interface {
+	Compare(ctx context.Context, in *comparison.Input) (*comparison.Report, error)
+}
+
+// RescoreService represents a service to recalculate the recorded scores with other score plugin weights.
+type RescoreService interface {
+	Rescore(ctx context.Context, req *rescore.Request) (*rescore.Report, error)
+}
+
+// OptimizerService represents a service to search the score plugin weights which maximize an objective.
+type OptimizerService interface {
+	Optimize(ctx context.Context, req *optimizer.Request) (*optimizer.Result, error)
+}
+
+// GangService represents a service to show the groups of Pods scheduled together.
+type GangService interface {
+	List(ctx context.Context, namespace string) ([]gang.Group, error)
+	Get(ctx context.Context, namespace, name string) (*gang.Group, error)
+}
+
+// ResultService represents a service to list the scheduling results recorded on Pods.
+type ResultService interface {
+	List(ctx context.Context, filter results.Filter) ([]results.PodResults, error)
+}
+
 // OneShotClusterResourceImporter represents a service to import resources from a target cluster when starting the simulator.
 type OneShotClusterResourceImporter interface {
 	ImportClusterResources(ctx context.Context, labelSelector metav1.LabelSelector) error
@@ -72,3 +143,18 @@ type ExtenderService interface {
 	Preempt(id int, args extenderv1.ExtenderPreemptionArgs) (*extenderv1.ExtenderPreemptionResult, error)
 	Bind(id int, args extenderv1.ExtenderBindingArgs) (*extenderv1.ExtenderBindingResult, error)
 }
+
+// ExtenderCallService represents service to get the calls to the extenders recorded in the same process.
+type ExtenderCallService interface {
+	Calls(namespace, podName string) []extender.Call
+}
+
+// BreakpointService represents service to get the debugger managing the breakpoints of each scheduler.
+type BreakpointService interface {
+	Debugger(name string) (breakpoint.Debugger, error)
+}
+
+// SchedulingQueueService represents service to look into the scheduling queue of the scheduler running in the same process.
+type SchedulingQueueService interface {
+	Snapshot(ctx context.Context) (*queue.Snapshot, error)
+}
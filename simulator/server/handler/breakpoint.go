@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/breakpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// BreakpointHandler is handler for the breakpoints of the schedulers.
+// The scheduler is specified by the name path parameter;
+// it's empty on the proxy server of the debuggable scheduler, which has only one scheduler.
+type BreakpointHandler struct {
+	service di.BreakpointService
+}
+
+func NewBreakpointHandler(s di.BreakpointService) *BreakpointHandler {
+	return &BreakpointHandler{
+		service: s,
+	}
+}
+
+// ListBreakpoints returns the breakpoints of the scheduler.
+func (h *BreakpointHandler) ListBreakpoints(c echo.Context) error {
+	d, err := h.debugger(c)
+	if err != nil {
+		return err
+	}
+	bps, err := d.ListBreakpoints(c.Request().Context())
+	if err != nil {
+		return breakpointFailure(err)
+	}
+	return c.JSON(http.StatusOK, bps)
+}
+
+// AddBreakpoint adds the posted breakpoint to the scheduler.
+func (h *BreakpointHandler) AddBreakpoint(c echo.Context) error {
+	req := new(breakpoint.Breakpoint)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind breakpoint request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	d, err := h.debugger(c)
+	if err != nil {
+		return err
+	}
+	bp, err := d.AddBreakpoint(c.Request().Context(), req)
+	if err != nil {
+		return breakpointFailure(err)
+	}
+	return c.JSON(http.StatusCreated, bp)
+}
+
+// DeleteBreakpoint deletes the breakpoint specified by the id path parameter,
+// and resumes the scheduling paused at it.
+func (h *BreakpointHandler) DeleteBreakpoint(c echo.Context) error {
+	d, err := h.debugger(c)
+	if err != nil {
+		return err
+	}
+	if err := d.DeleteBreakpoint(c.Request().Context(), c.Param("id")); err != nil {
+		return breakpointFailure(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListHits returns the scheduling paused at the breakpoints with the snapshots of the scheduling cycles.
+func (h *BreakpointHandler) ListHits(c echo.Context) error {
+	d, err := h.debugger(c)
+	if err != nil {
+		return err
+	}
+	hits, err := d.ListHits(c.Request().Context())
+	if err != nil {
+		return breakpointFailure(err)
+	}
+	return c.JSON(http.StatusOK, hits)
+}
+
+// Resume resumes the scheduling paused with the id path parameter.
+func (h *BreakpointHandler) Resume(c echo.Context) error {
+	d, err := h.debugger(c)
+	if err != nil {
+		return err
+	}
+	if err := d.Resume(c.Request().Context(), c.Param("id")); err != nil {
+		return breakpointFailure(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *BreakpointHandler) debugger(c echo.Context) (breakpoint.Debugger, error) {
+	d, err := h.service.Debugger(c.Param("name"))
+	if err != nil {
+		return nil, breakpointFailure(err)
+	}
+	return d, nil
+}
+
+func breakpointFailure(err error) error {
+	klog.Errorf("failed to handle breakpoints: %+v", err)
+	switch {
+	case errors.Is(err, breakpoint.ErrInvalidBreakpoint):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, scheduler.ErrSchedulerNotFound), errors.Is(err, breakpoint.ErrBreakpointNotFound), errors.Is(err, breakpoint.ErrHitNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError)
+}
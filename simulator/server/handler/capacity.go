@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// CapacityHandler is handler for estimating how many replicas of a Pod fit in the cluster.
+type CapacityHandler struct {
+	service di.CapacityService
+}
+
+// CapacityRequest is the request body of a capacity estimation.
+type CapacityRequest struct {
+	// Pod is the template of the replicas.
+	Pod *v1.Pod `json:"pod"`
+	// MaxReplicas is the upper limit of simulated replicas. The default is capacity.DefaultMaxReplicas.
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+}
+
+// NewCapacityHandler initializes CapacityHandler.
+func NewCapacityHandler(s di.CapacityService) *CapacityHandler {
+	return &CapacityHandler{service: s}
+}
+
+func (h *CapacityHandler) Estimate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req := new(CapacityRequest)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind capacity estimation request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	if req.Pod == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "pod is not specified")
+	}
+
+	est, err := h.service.Estimate(ctx, req.Pod, req.MaxReplicas)
+	if err != nil {
+		klog.Errorf("failed to estimate capacity: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, est)
+}
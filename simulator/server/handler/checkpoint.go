@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/checkpoint"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// CheckpointHandler is handler for saving and restoring named checkpoints.
+type CheckpointHandler struct {
+	service di.CheckpointService
+}
+
+// SaveCheckpointRequest is the request body to save a checkpoint.
+type SaveCheckpointRequest struct {
+	Name string `json:"name"`
+}
+
+// NewCheckpointHandler initializes CheckpointHandler.
+func NewCheckpointHandler(s di.CheckpointService) *CheckpointHandler {
+	return &CheckpointHandler{service: s}
+}
+
+func (h *CheckpointHandler) Save(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req := new(SaveCheckpointRequest)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind save checkpoint request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	cp, err := h.service.Save(ctx, req.Name)
+	if err != nil {
+		klog.Errorf("failed to save checkpoint: %+v", err)
+		switch {
+		case errors.Is(err, checkpoint.ErrEmptyCheckpointName):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case errors.Is(err, checkpoint.ErrCheckpointAlreadyExists):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusCreated, cp)
+}
+
+func (h *CheckpointHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.List())
+}
+
+func (h *CheckpointHandler) Restore(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if err := h.service.Restore(ctx, c.Param("name")); err != nil {
+		klog.Errorf("failed to restore checkpoint: %+v", err)
+		if errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/comparison"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// ComparisonHandler is handler for comparing two scheduler configurations on the same workload.
+type ComparisonHandler struct {
+	service di.ComparisonService
+}
+
+// NewComparisonHandler initializes ComparisonHandler.
+func NewComparisonHandler(s di.ComparisonService) *ComparisonHandler {
+	return &ComparisonHandler{service: s}
+}
+
+func (h *ComparisonHandler) Compare(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	in := new(comparison.Input)
+	if err := c.Bind(in); err != nil {
+		klog.Errorf("failed to bind comparison request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	report, err := h.service.Compare(ctx, in)
+	if err != nil {
+		klog.Errorf("failed to compare scheduler configurations: %+v", err)
+		if errors.Is(err, comparison.ErrMultipleWorkloads) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// DryRunHandler is handler for the placement queries which don't change the cluster.
+type DryRunHandler struct {
+	service di.DryRunService
+}
+
+// DryRunRequest is the request body of a dry-run.
+// Either Pod or Pods should be set. When both are set, Pod is evaluated first.
+type DryRunRequest struct {
+	Pod  *v1.Pod  `json:"pod,omitempty"`
+	Pods []v1.Pod `json:"pods,omitempty"`
+}
+
+// NewDryRunHandler initializes DryRunHandler.
+func NewDryRunHandler(s di.DryRunService) *DryRunHandler {
+	return &DryRunHandler{service: s}
+}
+
+func (h *DryRunHandler) DryRun(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req := new(DryRunRequest)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind dry-run request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	pods := req.Pods
+	if req.Pod != nil {
+		pods = append([]v1.Pod{*req.Pod}, pods...)
+	}
+	if len(pods) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "no pod is specified")
+	}
+
+	results, err := h.service.Schedule(ctx, pods)
+	if err != nil {
+		klog.Errorf("failed to run dry-run: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, results)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,12 +9,16 @@ import (
 	"k8s.io/klog/v2"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/extender"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
 )
 
 // ExtenderHandler is a handler about scheduling.
 type ExtenderHandler struct {
 	service di.ExtenderService
+	// namedService returns the service for the scheduler named by the path parameter. It's nil when the handler has only service.
+	namedService func(name string) (scheduler.ExtenderService, error)
 }
 
 func NewExtenderHandler(s di.ExtenderService) *ExtenderHandler {
@@ -22,6 +27,29 @@ func NewExtenderHandler(s di.ExtenderService) *ExtenderHandler {
 	}
 }
 
+// NewNamedExtenderHandler initializes ExtenderHandler serving the requests for the extenders of the scheduler named by the path parameter.
+func NewNamedExtenderHandler(namedService func(name string) (scheduler.ExtenderService, error)) *ExtenderHandler {
+	return &ExtenderHandler{
+		namedService: namedService,
+	}
+}
+
+// extenderService returns the service for the request.
+func (h *ExtenderHandler) extenderService(c echo.Context) (di.ExtenderService, error) {
+	if h.namedService == nil {
+		return h.service, nil
+	}
+	s, err := h.namedService(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) || errors.Is(err, scheduler.ErrExtenderCallsNotRecorded) {
+			return nil, echo.NewHTTPError(http.StatusNotFound)
+		}
+		klog.Errorf("failed to get extender service: %+v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return s, nil
+}
+
 // Filter request the original extender server which is specified by user,
 // and return the response as is.
 func (h *ExtenderHandler) Filter(c echo.Context) error {
@@ -36,8 +64,15 @@ func (h *ExtenderHandler) Filter(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	res, err := h.service.Filter(id, *req)
+	service, err := h.extenderService(c)
+	if err != nil {
+		return err
+	}
+	res, err := service.Filter(id, *req)
 	if err != nil {
+		if fault := injectedFault(err); fault != nil {
+			return c.Blob(fault.StatusCode, fault.ContentType, fault.Body)
+		}
 		klog.Errorf("failed to Filter request to the extender's actually host server: %+v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
@@ -58,8 +93,15 @@ func (h *ExtenderHandler) Prioritize(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	res, err := h.service.Prioritize(id, *req)
+	service, err := h.extenderService(c)
 	if err != nil {
+		return err
+	}
+	res, err := service.Prioritize(id, *req)
+	if err != nil {
+		if fault := injectedFault(err); fault != nil {
+			return c.Blob(fault.StatusCode, fault.ContentType, fault.Body)
+		}
 		klog.Errorf("failed to Prioritize request to the extender's actually host server: %+v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
@@ -80,8 +122,15 @@ func (h *ExtenderHandler) Preempt(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	res, err := h.service.Preempt(id, *req)
+	service, err := h.extenderService(c)
+	if err != nil {
+		return err
+	}
+	res, err := service.Preempt(id, *req)
 	if err != nil {
+		if fault := injectedFault(err); fault != nil {
+			return c.Blob(fault.StatusCode, fault.ContentType, fault.Body)
+		}
 		klog.Errorf("failed to Preempt request to the extender's actually host server: %+v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
@@ -102,10 +151,43 @@ func (h *ExtenderHandler) Bind(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	res, err := h.service.Bind(id, *req)
+	service, err := h.extenderService(c)
+	if err != nil {
+		return err
+	}
+	res, err := service.Bind(id, *req)
 	if err != nil {
+		if fault := injectedFault(err); fault != nil {
+			return c.Blob(fault.StatusCode, fault.ContentType, fault.Body)
+		}
 		klog.Errorf("failed to bind request to the extender's actually host server: %+v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, res)
 }
+
+// injectedFault returns the fault injected by the extender service, which is the response to the scheduler as is.
+func injectedFault(err error) *extender.FaultResponse {
+	var fault *extender.FaultResponse
+	if errors.As(err, &fault) {
+		return fault
+	}
+	return nil
+}
+
+// ExtenderCallHandler serves the calls to the extenders recorded on the proxy server of the debuggable scheduler.
+// The simulator server gets them from there when the scheduler runs in a container.
+type ExtenderCallHandler struct {
+	service di.ExtenderCallService
+}
+
+func NewExtenderCallHandler(s di.ExtenderCallService) *ExtenderCallHandler {
+	return &ExtenderCallHandler{
+		service: s,
+	}
+}
+
+// ListCalls returns the calls to the extenders for the Pod specified by the namespace and pod query parameters.
+func (h *ExtenderCallHandler) ListCalls(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.Calls(c.QueryParam("namespace"), c.QueryParam("pod")))
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/gang"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// GangHandler is handler for showing the groups of Pods scheduled together.
+type GangHandler struct {
+	service di.GangService
+}
+
+// NewGangHandler initializes GangHandler.
+func NewGangHandler(s di.GangService) *GangHandler {
+	return &GangHandler{service: s}
+}
+
+// List returns the groups in the namespace given by the query parameter.
+func (h *GangHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	groups, err := h.service.List(ctx, c.QueryParam("namespace"))
+	if err != nil {
+		klog.Errorf("failed to list pod groups: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, groups)
+}
+
+// Get returns the group with the namespace and name in the path.
+func (h *GangHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	g, err := h.service.Get(ctx, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if errors.Is(err, gang.ErrGroupNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		klog.Errorf("failed to get pod group: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, g)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/optimizer"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// OptimizerHandler is handler for searching the score plugin weights.
+type OptimizerHandler struct {
+	service di.OptimizerService
+}
+
+// NewOptimizerHandler initializes OptimizerHandler.
+func NewOptimizerHandler(s di.OptimizerService) *OptimizerHandler {
+	return &OptimizerHandler{service: s}
+}
+
+func (h *OptimizerHandler) Optimize(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req := new(optimizer.Request)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind optimization request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	result, err := h.service.Optimize(ctx, req)
+	if err != nil {
+		if errors.Is(err, optimizer.ErrInvalidRequest) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		klog.Errorf("failed to optimize score plugin weights: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// SchedulingQueueHandler serves the scheduling queue of the debuggable scheduler on its proxy server.
+// The simulator server gets it from there when the scheduler runs in a container.
+type SchedulingQueueHandler struct {
+	service di.SchedulingQueueService
+}
+
+func NewSchedulingQueueHandler(s di.SchedulingQueueService) *SchedulingQueueHandler {
+	return &SchedulingQueueHandler{
+		service: s,
+	}
+}
+
+// GetSchedulingQueue returns the Pods in activeQ, backoffQ and the unschedulable Pods.
+func (h *SchedulingQueueHandler) GetSchedulingQueue(c echo.Context) error {
+	snapshot, err := h.service.Snapshot(c.Request().Context())
+	if err != nil {
+		klog.Errorf("failed to get scheduling queue: %+v", err)
+		if errors.Is(err, queue.ErrSchedulerNotRunning) {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/rescore"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// RescoreHandler is handler for recalculating the recorded scores with other score plugin weights.
+type RescoreHandler struct {
+	service di.RescoreService
+}
+
+// NewRescoreHandler initializes RescoreHandler.
+func NewRescoreHandler(s di.RescoreService) *RescoreHandler {
+	return &RescoreHandler{service: s}
+}
+
+func (h *RescoreHandler) Rescore(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req := new(rescore.Request)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind rescore request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	for plugin, w := range req.Weights {
+		if w < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "the weight of "+plugin+" must not be negative")
+		}
+	}
+
+	report, err := h.service.Rescore(ctx, req)
+	if err != nil {
+		klog.Errorf("failed to rescore pods: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/results"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
+)
+
+// ResultHandler is handler for listing the scheduling results recorded on Pods.
+type ResultHandler struct {
+	service di.ResultService
+}
+
+// NewResultHandler initializes ResultHandler.
+func NewResultHandler(s di.ResultService) *ResultHandler {
+	return &ResultHandler{service: s}
+}
+
+// List returns the results filtered by the query parameters.
+func (h *ResultHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	filter := results.Filter{
+		Namespace:         c.QueryParam("namespace"),
+		ConfigFingerprint: c.QueryParam("configFingerprint"),
+		Scheduler:         c.QueryParam("scheduler"),
+		Profile:           c.QueryParam("profile"),
+	}
+	if v := c.QueryParam("configVersion"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "configVersion must be an integer")
+		}
+		filter.ConfigVersion = version
+	}
+
+	rs, err := h.service.List(ctx, filter)
+	if err != nil {
+		klog.Errorf("failed to list results: %+v", err)
+		if errors.Is(err, results.ErrInvalidFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) || errors.Is(err, scheduler.ErrConfigVersionNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, rs)
+}
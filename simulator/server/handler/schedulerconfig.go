@@ -2,13 +2,19 @@ package handler
 
 import (
 	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"k8s.io/klog/v2"
 	configv1 "k8s.io/kube-scheduler/config/v1"
 
+	simulatorconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/config"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler"
+	simulatorschedconfig "sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/config"
+	"sigs.k8s.io/kube-scheduler-simulator/simulator/scheduler/queue"
 	"sigs.k8s.io/kube-scheduler-simulator/simulator/server/di"
 )
 
@@ -38,6 +44,7 @@ func (h *SchedulerConfigHandler) GetSchedulerConfig(c echo.Context) error {
 
 // ApplySchedulerConfig currently only takes profiles and extenders from the
 // posted payload and applies them.
+// The comment query parameter is recorded in the history along with the config.
 func (h *SchedulerConfigHandler) ApplySchedulerConfig(c echo.Context) error {
 	reqSchedulerCfg := new(configv1.KubeSchedulerConfiguration)
 	if err := c.Bind(reqSchedulerCfg); err != nil {
@@ -53,10 +60,338 @@ func (h *SchedulerConfigHandler) ApplySchedulerConfig(c echo.Context) error {
 	cfg = cfg.DeepCopy()
 	cfg.Profiles = reqSchedulerCfg.Profiles
 	cfg.Extenders = reqSchedulerCfg.Extenders
-	if err := h.service.RestartScheduler(cfg); err != nil {
+	res, err := h.validateForApply(simulatorconfig.DefaultSchedulerName, cfg)
+	if err != nil || !res.Valid {
+		return validationFailure(c, res, err)
+	}
+	warn(c, res.Warnings)
+	if err := h.service.RestartNamedSchedulerWithComment(simulatorconfig.DefaultSchedulerName, cfg, c.QueryParam("comment")); err != nil {
 		klog.Errorf("failed to restart scheduler: %+v", err)
+		if errors.Is(err, scheduler.ErrSchedulerNameConflict) || errors.Is(err, scheduler.ErrRolledBack) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ListSchedulers returns all schedulers run in the simulator.
+func (h *SchedulerConfigHandler) ListSchedulers(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.ListSchedulers())
+}
+
+// GetNamedSchedulerConfig returns the config of the scheduler specified by the path parameter.
+func (h *SchedulerConfigHandler) GetNamedSchedulerConfig(c echo.Context) error {
+	cfg, err := h.service.GetNamedSchedulerConfig(c.Param("name"))
+	if err != nil {
+		klog.Errorf("failed to get scheduler config: %+v", err)
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		if errors.Is(err, scheduler.ErrServiceDisabled) {
+			return c.JSON(http.StatusBadRequest, "When using an external scheduler, you cannot see and edit the scheduler configuration.")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, cfg)
+}
+
+// ApplyNamedSchedulerConfig applies the profiles and extenders in the posted payload
+// to the scheduler specified by the path parameter, like ApplySchedulerConfig.
+func (h *SchedulerConfigHandler) ApplyNamedSchedulerConfig(c echo.Context) error {
+	name := c.Param("name")
+	reqSchedulerCfg := new(configv1.KubeSchedulerConfiguration)
+	if err := c.Bind(reqSchedulerCfg); err != nil {
+		klog.Errorf("failed to bind scheduler config request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	cfg, err := h.service.GetNamedSchedulerConfig(name)
+	if err != nil {
+		klog.Errorf("failed to get scheduler config: %+v", err)
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	cfg = cfg.DeepCopy()
+	cfg.Profiles = reqSchedulerCfg.Profiles
+	cfg.Extenders = reqSchedulerCfg.Extenders
+	res, err := h.validateForApply(name, cfg)
+	if err != nil || !res.Valid {
+		return validationFailure(c, res, err)
+	}
+	warn(c, res.Warnings)
+	if err := h.service.RestartNamedSchedulerWithComment(name, cfg, c.QueryParam("comment")); err != nil {
+		klog.Errorf("failed to restart scheduler %s: %+v", name, err)
+		if errors.Is(err, scheduler.ErrSchedulerNameConflict) || errors.Is(err, scheduler.ErrRolledBack) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ValidationResponse is the response of ValidateSchedulerConfig.
+type ValidationResponse struct {
+	Valid  bool                              `json:"valid"`
+	Errors []simulatorschedconfig.FieldError `json:"errors"`
+	// Warnings are the problems which don't stop the config from being applied.
+	// They're given only when the config is applied to a scheduler running in a container.
+	Warnings []simulatorschedconfig.FieldError `json:"warnings,omitempty"`
+}
+
+// ValidateSchedulerConfig validates the profiles and extenders in the posted payload
+// merged into the current config, in the same way as ApplySchedulerConfig, without restarting the scheduler.
+func (h *SchedulerConfigHandler) ValidateSchedulerConfig(c echo.Context) error {
+	reqSchedulerCfg := new(configv1.KubeSchedulerConfiguration)
+	if err := c.Bind(reqSchedulerCfg); err != nil {
+		klog.Errorf("failed to bind scheduler config request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	cfg, err := h.service.GetSchedulerConfig()
+	if err != nil && !errors.Is(err, scheduler.ErrServiceDisabled) {
+		klog.Errorf("failed to get scheduler config: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if errors.Is(err, scheduler.ErrServiceDisabled) {
+		return c.JSON(http.StatusBadRequest, "When using an external scheduler, you cannot see and edit the scheduler configuration.")
+	}
+
+	cfg = cfg.DeepCopy()
+	cfg.Profiles = reqSchedulerCfg.Profiles
+	cfg.Extenders = reqSchedulerCfg.Extenders
+	res, err := validate(cfg)
+	if err != nil {
+		klog.Errorf("failed to validate scheduler config: %+v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func validate(cfg *configv1.KubeSchedulerConfiguration) (*ValidationResponse, error) {
+	return toValidationResponse(simulatorschedconfig.ValidateSchedulerConfig(cfg))
+}
+
+// validateForApply validates the config applied to the scheduler with the name.
+// The plugins registered to the scheduler running in a container aren't known by the simulator,
+// so the enabled plugins which aren't registered to the simulator are only warned about for it.
+func (h *SchedulerConfigHandler) validateForApply(name string, cfg *configv1.KubeSchedulerConfiguration) (*ValidationResponse, error) {
+	if !h.runsInContainer(name) {
+		return validate(cfg)
+	}
+	warnings, err := simulatorschedconfig.ValidateSchedulerConfigForContainer(cfg)
+	res, err := toValidationResponse(err)
+	if err != nil {
+		return nil, err
+	}
+	res.Warnings = warnings
+	return res, nil
+}
+
+// runsInContainer returns true if the scheduler with the name runs in a container.
+func (h *SchedulerConfigHandler) runsInContainer(name string) bool {
+	for _, s := range h.service.ListSchedulers() {
+		if s.Name == name {
+			return s.ContainerName != ""
+		}
+	}
+	return false
+}
+
+func toValidationResponse(err error) (*ValidationResponse, error) {
+	if err == nil {
+		return &ValidationResponse{Valid: true, Errors: []simulatorschedconfig.FieldError{}}, nil
+	}
+	var verr *simulatorschedconfig.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, err
+	}
+	return &ValidationResponse{Valid: false, Errors: verr.Errors}, nil
+}
+
+// warn tells the warnings of the validation in the Warning headers of the response, in the same way as kube-apiserver does.
+func warn(c echo.Context, warnings []simulatorschedconfig.FieldError) {
+	for _, w := range warnings {
+		msg := w.Message
+		if w.Field != "" {
+			msg = w.Field + ": " + msg
+		}
+		klog.Warningf("scheduler config: %s", msg)
+		c.Response().Header().Add("Warning", "299 - "+strconv.Quote(msg))
+	}
+}
+
+// validationFailure responds to the apply request whose config failed the validation.
+func validationFailure(c echo.Context, res *ValidationResponse, err error) error {
+	if err != nil {
+		klog.Errorf("failed to validate scheduler config: %+v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
+	return c.JSON(http.StatusBadRequest, res)
+}
+
+// ListSchedulerConfigVersions returns the versions of the configurations applied to the scheduler specified by the path parameter.
+func (h *SchedulerConfigHandler) ListSchedulerConfigVersions(c echo.Context) error {
+	versions, err := h.service.ListSchedulerConfigVersions(c.Param("name"))
+	if err != nil {
+		return historyFailure(err)
+	}
+	return c.JSON(http.StatusOK, versions)
+}
+
+// GetSchedulerConfigVersion returns the configuration with the version specified by the path parameter.
+func (h *SchedulerConfigHandler) GetSchedulerConfigVersion(c echo.Context) error {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "version must be an integer")
+	}
+	v, err := h.service.GetSchedulerConfigVersion(c.Param("name"), version)
+	if err != nil {
+		return historyFailure(err)
+	}
+	return c.JSON(http.StatusOK, v)
+}
+
+// DiffSchedulerConfigVersions returns the fields changed between the versions specified by the from and to query parameters.
+func (h *SchedulerConfigHandler) DiffSchedulerConfigVersions(c echo.Context) error {
+	from, err := strconv.Atoi(c.QueryParam("from"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "from must be an integer")
+	}
+	to, err := strconv.Atoi(c.QueryParam("to"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "to must be an integer")
+	}
+	changes, err := h.service.DiffSchedulerConfigVersions(c.Param("name"), from, to)
+	if err != nil {
+		return historyFailure(err)
+	}
+	return c.JSON(http.StatusOK, changes)
+}
+
+// rollbackRequest is the request body of RollbackScheduler.
+type rollbackRequest struct {
+	Version int `json:"version"`
+}
 
+// RollbackScheduler restarts the scheduler specified by the path parameter with the configuration of the posted version.
+func (h *SchedulerConfigHandler) RollbackScheduler(c echo.Context) error {
+	req := new(rollbackRequest)
+	if err := c.Bind(req); err != nil {
+		klog.Errorf("failed to bind rollback request: %+v", err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+	if err := h.service.RollbackScheduler(c.Param("name"), req.Version); err != nil {
+		if errors.Is(err, scheduler.ErrSchedulerNameConflict) || errors.Is(err, scheduler.ErrRolledBack) {
+			klog.Errorf("failed to rollback scheduler: %+v", err)
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return historyFailure(err)
+	}
 	return c.NoContent(http.StatusAccepted)
 }
+
+// GetSchedulingQueue returns the Pods in the scheduling queue of the scheduler specified by the path parameter.
+func (h *SchedulerConfigHandler) GetSchedulingQueue(c echo.Context) error {
+	snapshot, err := h.service.GetSchedulingQueue(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		klog.Errorf("failed to get scheduling queue: %+v", err)
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, queue.ErrSchedulerNotRunning) {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// ListExtenderCalls returns the calls to the extenders of the scheduler specified by the path parameter.
+// They're filtered by the namespace and pod query parameters.
+func (h *SchedulerConfigHandler) ListExtenderCalls(c echo.Context) error {
+	calls, err := h.service.ListExtenderCalls(c.Request().Context(), c.Param("name"), c.QueryParam("namespace"), c.QueryParam("pod"))
+	if err != nil {
+		klog.Errorf("failed to list extender calls: %+v", err)
+		if errors.Is(err, scheduler.ErrSchedulerNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, scheduler.ErrExtenderCallsNotRecorded) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, calls)
+}
+
+// wasmPluginRequest is the request body of ReloadWasmPlugin to load the wasm plugin from guestURL.
+type wasmPluginRequest struct {
+	GuestURL string `json:"guestURL"`
+}
+
+// maxWasmModuleSize is the maximum size of the wasm module uploaded to ReloadWasmPlugin.
+const maxWasmModuleSize = 64 << 20
+
+// ReloadWasmPlugin hot-swaps the wasm plugin specified by the path parameters in the scheduler.
+// The wasm module is uploaded as the body with Content-Type application/wasm, or given by guestURL in the JSON body.
+// It responds with the new version of the scheduler configuration.
+func (h *SchedulerConfigHandler) ReloadWasmPlugin(c echo.Context) error {
+	name, pluginName := c.Param("name"), c.Param("plugin")
+
+	var guestURL string
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType == "application/wasm" || mediaType == echo.MIMEOctetStream {
+		module, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWasmModuleSize+1))
+		if err != nil {
+			klog.Errorf("failed to read wasm module: %+v", err)
+			return echo.NewHTTPError(http.StatusBadRequest)
+		}
+		if len(module) > maxWasmModuleSize {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "the wasm module must be at most 64MiB")
+		}
+		guestURL, err = h.service.StoreWasmModule(name, pluginName, module)
+		if err != nil {
+			return wasmPluginFailure(err)
+		}
+	} else {
+		req := new(wasmPluginRequest)
+		if err := c.Bind(req); err != nil {
+			klog.Errorf("failed to bind wasm plugin request: %+v", err)
+			return echo.NewHTTPError(http.StatusBadRequest)
+		}
+		if req.GuestURL == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "guestURL is required unless the wasm module is uploaded")
+		}
+		guestURL = req.GuestURL
+	}
+
+	v, err := h.service.ReloadWasmPlugin(c.Request().Context(), name, pluginName, guestURL)
+	if err != nil {
+		return wasmPluginFailure(err)
+	}
+	return c.JSON(http.StatusOK, v)
+}
+
+func wasmPluginFailure(err error) error {
+	klog.Errorf("failed to reload wasm plugin: %+v", err)
+	if errors.Is(err, scheduler.ErrSchedulerNotFound) || errors.Is(err, scheduler.ErrWasmPluginNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, scheduler.ErrWasmPluginLoad) || errors.Is(err, scheduler.ErrWasmUploadNotSupported) || errors.Is(err, scheduler.ErrSchedulerNameConflict) || errors.Is(err, scheduler.ErrRolledBack) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError)
+}
+
+func historyFailure(err error) error {
+	klog.Errorf("failed to handle scheduler config history: %+v", err)
+	if errors.Is(err, scheduler.ErrSchedulerNotFound) || errors.Is(err, scheduler.ErrConfigVersionNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError)
+}
@@ -35,23 +35,61 @@ func NewSimulatorServer(cfg *config.Config, dic *di.Container) *SimulatorServer
 	schedulercfgHandler := handler.NewSchedulerConfigHandler(dic.SchedulerService())
 	snapshotHandler := handler.NewSnapshotHandler(dic.ExportService())
 	resetHandler := handler.NewResetHandler(dic.ResetService())
+	checkpointHandler := handler.NewCheckpointHandler(dic.CheckpointService())
+	dryRunHandler := handler.NewDryRunHandler(dic.DryRunService())
+	capacityHandler := handler.NewCapacityHandler(dic.CapacityService())
+	comparisonHandler := handler.NewComparisonHandler(dic.ComparisonService())
+	resultHandler := handler.NewResultHandler(dic.ResultService())
+	rescoreHandler := handler.NewRescoreHandler(dic.RescoreService())
+	optimizerHandler := handler.NewOptimizerHandler(dic.OptimizerService())
+	gangHandler := handler.NewGangHandler(dic.GangService())
+	breakpointHandler := handler.NewBreakpointHandler(dic.BreakpointService())
 	resourcewatcherHandler := handler.NewResourceWatcherHandler(dic.ResourceWatcherService())
 	extenderHandler := handler.NewExtenderHandler(dic.ExtenderService())
+	namedExtenderHandler := handler.NewNamedExtenderHandler(dic.SchedulerService().NamedExtenderService)
 
 	// register apis
 	v1 := e.Group("/api/v1")
 
 	v1.GET("/schedulerconfiguration", schedulercfgHandler.GetSchedulerConfig)
 	v1.POST("/schedulerconfiguration", schedulercfgHandler.ApplySchedulerConfig)
+	v1.POST("/schedulerconfiguration/validate", schedulercfgHandler.ValidateSchedulerConfig)
+
+	v1.GET("/schedulers", schedulercfgHandler.ListSchedulers)
+	v1.GET("/schedulers/:name/schedulerconfiguration", schedulercfgHandler.GetNamedSchedulerConfig)
+	v1.POST("/schedulers/:name/schedulerconfiguration", schedulercfgHandler.ApplyNamedSchedulerConfig)
+	v1.GET("/schedulers/:name/schedulerconfiguration/versions", schedulercfgHandler.ListSchedulerConfigVersions)
+	v1.GET("/schedulers/:name/schedulerconfiguration/versions/:version", schedulercfgHandler.GetSchedulerConfigVersion)
+	v1.GET("/schedulers/:name/schedulerconfiguration/diff", schedulercfgHandler.DiffSchedulerConfigVersions)
+	v1.POST("/schedulers/:name/schedulerconfiguration/rollback", schedulercfgHandler.RollbackScheduler)
+	v1.POST("/schedulers/:name/wasmplugins/:plugin", schedulercfgHandler.ReloadWasmPlugin)
+	v1.GET("/schedulers/:name/queue", schedulercfgHandler.GetSchedulingQueue)
+	v1.GET("/schedulers/:name/extender/calls", schedulercfgHandler.ListExtenderCalls)
+	RouteBreakpoints(v1.Group("/schedulers/:name"), breakpointHandler)
 
 	v1.PUT("/reset", resetHandler.Reset)
 
+	v1.GET("/checkpoints", checkpointHandler.List)
+	v1.POST("/checkpoints", checkpointHandler.Save)
+	v1.POST("/checkpoints/:name/restore", checkpointHandler.Restore)
+
+	v1.POST("/dryrun", dryRunHandler.DryRun)
+	v1.POST("/capacity", capacityHandler.Estimate)
+	v1.POST("/comparison", comparisonHandler.Compare)
+
+	v1.GET("/results", resultHandler.List)
+	v1.POST("/rescore", rescoreHandler.Rescore)
+	v1.POST("/optimize", optimizerHandler.Optimize)
+	v1.GET("/gangs", gangHandler.List)
+	v1.GET("/gangs/:namespace/:name", gangHandler.Get)
+
 	v1.GET("/export", snapshotHandler.Snap)
 	v1.POST("/import", snapshotHandler.Load)
 
 	v1.GET("/listwatchresources", resourcewatcherHandler.ListWatchResources)
 
 	RouteExtender(v1, extenderHandler)
+	RouteExtender(v1.Group("/schedulers/:name"), namedExtenderHandler)
 
 	// initialize SimulatorServer.
 	s := &SimulatorServer{e: e}
@@ -91,3 +129,22 @@ func RouteExtender(v1 *echo.Group, handler *handler.ExtenderHandler) {
 	v1.POST("/extender/preempt/:id", handler.Preempt)
 	v1.POST("/extender/bind/:id", handler.Bind)
 }
+
+// RouteExtenderCalls routes request for the calls to the extenders recorded in the same process.
+func RouteExtenderCalls(v1 *echo.Group, handler *handler.ExtenderCallHandler) {
+	v1.GET("/extender/calls", handler.ListCalls)
+}
+
+// RouteBreakpoints routes request for the breakpoints of a scheduler.
+func RouteBreakpoints(g *echo.Group, handler *handler.BreakpointHandler) {
+	g.GET("/breakpoints", handler.ListBreakpoints)
+	g.POST("/breakpoints", handler.AddBreakpoint)
+	g.DELETE("/breakpoints/:id", handler.DeleteBreakpoint)
+	g.GET("/breakpoints/hits", handler.ListHits)
+	g.POST("/breakpoints/hits/:id/resume", handler.Resume)
+}
+
+// RouteSchedulingQueue routes request for the scheduling queue of the scheduler in the same process.
+func RouteSchedulingQueue(v1 *echo.Group, handler *handler.SchedulingQueueHandler) {
+	v1.GET("/queue", handler.GetSchedulingQueue)
+}